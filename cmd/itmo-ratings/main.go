@@ -0,0 +1,13 @@
+package main
+
+import (
+	"itmo-ratings/internal/cli"
+	"os"
+
+	// the runtime image has no zoneinfo, recipients' timezones are resolved from the embedded database
+	_ "time/tzdata"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[0], os.Args[1:]))
+}
@@ -3,8 +3,11 @@ module itmo-ratings
 go 1.24.5
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1
 	github.com/samber/lo v1.51.0
+	golang.org/x/net v0.48.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require golang.org/x/text v0.22.0 // indirect
+require golang.org/x/text v0.32.0 // indirect
@@ -0,0 +1,177 @@
+// Package cli implements the itmo-ratings command line: one subcommand per use case built on the rating services.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"itmo-ratings/internal/config"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/delivery"
+	"itmo-ratings/internal/domain/rating/preferences"
+	"itmo-ratings/internal/domain/rating/rules"
+	"itmo-ratings/internal/domain/rating/scrapper"
+	sender "itmo-ratings/internal/domain/rating/student_rating_service"
+	"itmo-ratings/internal/infrustructure/httpreplay"
+	"itmo-ratings/internal/infrustructure/outbox"
+)
+
+// Exit codes of all subcommands.
+const (
+	Success     = 0
+	Fail        = 1
+	PartialFail = 2
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(a *app, ctx context.Context, args []string) int
+}
+
+var commands = []command{
+	{"serve", "run the HTTP API, bot commands and periodic refresh", (*app).serve},
+	{"notify", "send student summaries to their Telegram recipients", (*app).notify},
+	{"summary", "print the summary of a student", (*app).summary},
+	{"programs", "list the programs of a degree", (*app).programs},
+	{"entries", "print the admission list of a program", (*app).entries},
+	{"export", "write a program list or a student summary as CSV or XLSX", (*app).export},
+	{"dump", "save all programs as an NDJSON/Parquet snapshot", (*app).dump},
+	{"diff", "compare two snapshots", (*app).diff},
+	{"outbox", "inspect, deliver and replay the notifications of notify", (*app).outbox},
+	{"simulate", "project the budget admission of a degree", (*app).simulate},
+	{"tui", "browse programs and tracked students in the terminal", (*app).tui},
+}
+
+type app struct {
+	name   string
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// Run executes the subcommand named by args[0] and returns the exit code.
+func Run(name string, args []string) int {
+	a := &app{name: name, stdout: os.Stdout, stderr: os.Stderr}
+
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		a.usage()
+		if len(args) == 0 {
+			return Fail
+		}
+		return Success
+	}
+
+	i := slices.IndexFunc(commands, func(c command) bool { return c.name == args[0] })
+	if i < 0 {
+		fmt.Fprintf(a.stderr, "unknown command %q\n\n", args[0])
+		a.usage()
+		return Fail
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return commands[i].run(a, ctx, args[1:])
+}
+
+func (a *app) usage() {
+	fmt.Fprintf(a.stderr, "usage: %s <command> [flags] [args]\n\ncommands:\n", a.name)
+	for _, c := range commands {
+		fmt.Fprintf(a.stderr, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(a.stderr, "\nRun '%s <command> -h' for the flags of a command.\n", a.name)
+}
+
+// flagSet creates the flag set of a subcommand with the shared configuration flags registered.
+func (a *app) flagSet(cmd, usage string) (*flag.FlagSet, *config.Flags) {
+	fs := flag.NewFlagSet(a.name+" "+cmd, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "usage: %s %s\n\n", a.name, usage)
+		fs.PrintDefaults()
+	}
+	return fs, config.RegisterFlags(fs)
+}
+
+// printConfig handles --print-config, it reports whether the command should stop.
+func (a *app) printConfig(flags *config.Flags, cfg *config.Config) (bool, int) {
+	if !flags.PrintConfig() {
+		return false, Success
+	}
+	if err := cfg.Print(a.stdout); err != nil {
+		slog.Error("failed to print config", "err", err)
+		return true, Fail
+	}
+	return true, Success
+}
+
+func newParser(cfg *config.Config) (*scrapper.Service, error) {
+	httpClient, err := httpreplay.NewClient(http.DefaultClient, cfg.Scrapper.RecordDir, cfg.Scrapper.ReplayDir, cfg.Scrapper.MaxPageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init http client: %w", err)
+	}
+	return scrapper.New(httpClient, cfg.Scrapper.Options()...), nil
+}
+
+func newService(cfg *config.Config, degrees ...rating.Degree) (*sender.Service, error) {
+	parser, err := newParser(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return sender.New(parser, degrees...), nil
+}
+
+func openPreferences(cfg *config.Config) (*preferences.Store, error) {
+	store, err := preferences.Open(cfg.DataPath("preferences.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open preferences: %w", err)
+	}
+	return store, nil
+}
+
+func openHistory(cfg *config.Config) (*rules.History, error) {
+	history, err := rules.OpenHistory(cfg.DataPath("summaries.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open summary history: %w", err)
+	}
+	return history, nil
+}
+
+func openDelivery(cfg *config.Config) (*delivery.Store, error) {
+	store, err := delivery.Open(cfg.DataPath("delivery.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open delivery state: %w", err)
+	}
+	return store, nil
+}
+
+func openOutbox(cfg *config.Config) (*outbox.Outbox, error) {
+	box, err := outbox.Open(cfg.DataPath("outbox.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox: %w", err)
+	}
+	return box, nil
+}
+
+func (a *app) writeJSON(v any) error {
+	encoder := json.NewEncoder(a.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// parseChoice checks a flag value against the allowed ones.
+func parseChoice(flagName, value string, allowed ...string) error {
+	if !slices.Contains(allowed, value) {
+		return fmt.Errorf("--%s must be one of %v, got %q", flagName, allowed, value)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"itmo-ratings/internal/domain/rating/snapshot"
+	"log/slog"
+	"text/tabwriter"
+)
+
+const diffUsage = `diff [flags] <snapshot_a> <snapshot_b>
+
+Compares two snapshots saved by dump or the periodic snapshots of serve. A snapshot is referenced
+by its ID from manifest.json or by the path of its NDJSON file.`
+
+func (a *app) diff(_ context.Context, args []string) int {
+	fs, flags := a.flagSet("diff", diffUsage)
+	dirFlag := fs.String("dir", "", "snapshot directory (default snapshots.dir or ./snapshots)")
+	format := fs.String("format", "text", "output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return Fail
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return Fail
+	}
+	if err := parseChoice("format", *format, "text", "json"); err != nil {
+		slog.Error("invalid diff flags", "err", err)
+		return Fail
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		return Fail
+	}
+	store, err := snapshot.NewStore(snapshotDir(cfg.Snapshots.Dir, *dirFlag))
+	if err != nil {
+		slog.Error("failed to init snapshot store", "err", err)
+		return Fail
+	}
+	from, err := store.Load(fs.Arg(0))
+	if err != nil {
+		slog.Error("failed to load snapshot", "ref", fs.Arg(0), "err", err)
+		return Fail
+	}
+	to, err := store.Load(fs.Arg(1))
+	if err != nil {
+		slog.Error("failed to load snapshot", "ref", fs.Arg(1), "err", err)
+		return Fail
+	}
+
+	diff := snapshot.Compare(from, to)
+	if *format == "json" {
+		err = a.writeJSON(diff)
+	} else {
+		err = writeDiffText(a.stdout, diff)
+	}
+	if err != nil {
+		slog.Error("failed to write diff", "err", err)
+		return Fail
+	}
+	return Success
+}
+
+func writeDiffText(w io.Writer, diff snapshot.Diff) error {
+	fmt.Fprintf(w, "Снимки %s → %s\n", diff.From, diff.To)
+	if len(diff.Lists) == 0 {
+		_, err := fmt.Fprintln(w, "Изменений нет")
+		return err
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "УРОВЕНЬ\tID\tПРОГРАММА\tСПИСОК\tЗАЯВЛЕНИЙ\tНОВЫЕ\tУШЛИ\tСОГЛАСИЯ\tСДВИНУЛИСЬ")
+	for _, d := range diff.Lists {
+		list := string(d.Basis)
+		if d.TargetQuota {
+			list = "target"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%d → %d\t+%d\t-%d\t+%d\t%d\n",
+			d.Degree, d.ProgramID, d.ProgramTitle, list, d.Before, d.After,
+			len(d.Added), len(d.Removed), len(d.Agreements), d.Moved)
+	}
+	return tw.Flush()
+}
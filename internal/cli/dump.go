@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"itmo-ratings/internal/domain/rating/snapshot"
+	"log/slog"
+)
+
+const dumpUsage = `dump [flags]
+
+Loads all programs of the configured degrees and saves them as a snapshot: one file per format
+and a manifest.json describing the snapshots and their schema.`
+
+func (a *app) dump(ctx context.Context, args []string) int {
+	fs, flags := a.flagSet("dump", dumpUsage)
+	output := fs.String("output", "", "snapshot directory (default snapshots.dir or ./snapshots)")
+	formatsFlag := fs.String("format", "", "comma separated formats: ndjson, parquet (default snapshots.formats)")
+	if err := fs.Parse(args); err != nil {
+		return Fail
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		return Fail
+	}
+	dir := snapshotDir(cfg.Snapshots.Dir, *output)
+	formats := cfg.Snapshots.Formats
+	if *formatsFlag != "" {
+		if formats, err = snapshot.ParseFormats(*formatsFlag); err != nil {
+			slog.Error("invalid dump flags", "err", err)
+			return Fail
+		}
+	}
+
+	store, err := snapshot.NewStore(dir)
+	if err != nil {
+		slog.Error("failed to init snapshot store", "err", err)
+		return Fail
+	}
+	service, err := newService(cfg, cfg.Degrees...)
+	if err != nil {
+		slog.Error("failed to init rating service", "err", err)
+		return Fail
+	}
+
+	// a snapshot missing a whole degree would look like every applicant withdrew
+	if err := service.EnrichAll(ctx); err != nil {
+		slog.Error("failed to load ratings", "err", err)
+		return Fail
+	}
+	snap, err := snapshot.Collect(ctx, service)
+	if err != nil {
+		slog.Error("failed to collect snapshot", "err", err)
+		return Fail
+	}
+	info, err := store.Save(snap, formats...)
+	if err != nil {
+		slog.Error("failed to save snapshot", "err", err)
+		return Fail
+	}
+
+	slog.Info("snapshot saved", "dir", dir, "id", info.ID, "programs", info.Programs, "rows", info.Rows)
+	return Success
+}
+
+// snapshotDir picks the flag value over the configured directory, ./snapshots when neither is set.
+func snapshotDir(configured, flagValue string) string {
+	switch {
+	case flagValue != "":
+		return flagValue
+	case configured != "":
+		return configured
+	default:
+		return "snapshots"
+	}
+}
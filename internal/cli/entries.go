@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/export"
+	"log/slog"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+)
+
+const entriesUsage = `entries [flags] <program_id>
+
+Prints the admission list of a program, the budget one together with the target quota.`
+
+func (a *app) entries(ctx context.Context, args []string) int {
+	fs, flags := a.flagSet("entries", entriesUsage)
+	degreeFlag := fs.String("degree", string(rating.DegreeMaster), "degree of the admission lists")
+	basisFlag := fs.String("basis", string(rating.BasisBudget), "admission list: budget or contract")
+	format := fs.String("format", "text", "output format: text, json, csv or xlsx")
+	if err := fs.Parse(args); err != nil {
+		return Fail
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return Fail
+	}
+	programID, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		slog.Error("program id must be a number", "id", fs.Arg(0))
+		return Fail
+	}
+	degree, err := rating.ParseDegree(*degreeFlag)
+	if err != nil {
+		slog.Error("invalid entries flags", "err", err)
+		return Fail
+	}
+	basis, err := rating.ParseBasis(*basisFlag)
+	if err != nil {
+		slog.Error("invalid entries flags", "err", err)
+		return Fail
+	}
+	if err := parseChoice("format", *format, "text", "json", string(export.FormatCSV), string(export.FormatXLSX)); err != nil {
+		slog.Error("invalid entries flags", "err", err)
+		return Fail
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		return Fail
+	}
+	service, err := newService(cfg, degree)
+	if err != nil {
+		slog.Error("failed to init rating service", "err", err)
+		return Fail
+	}
+	program, err := service.GetProgram(ctx, degree, programID)
+	if err != nil {
+		slog.Error("failed to get program", "programID", programID, "err", err)
+		return Fail
+	}
+
+	switch *format {
+	case "json":
+		entries := program.List(basis)
+		if basis == rating.BasisBudget {
+			entries = slices.Concat(entries, program.TargetEntries)
+		}
+		err = a.writeJSON(entries)
+	case "text":
+		err = writeEntriesText(a.stdout, program, basis)
+	default:
+		err = a.writeTable("", export.Format(*format), export.Program(program, basis))
+	}
+	if err != nil {
+		slog.Error("failed to write entries", "err", err)
+		return Fail
+	}
+	return Success
+}
+
+func writeEntriesText(w io.Writer, program *rating.ProgramData, basis rating.Basis) error {
+	fmt.Fprintf(w, "%s (%d), %s, мест: %d\n\n", program.Data.DirectionTitle, program.Data.CompetitiveGroupID, basis, basis.Seats(program.Data))
+
+	if err := writeEntriesTable(w, program.List(basis)); err != nil {
+		return err
+	}
+	if basis == rating.BasisBudget && len(program.TargetEntries) > 0 {
+		fmt.Fprintf(w, "\nЦелевая квота, мест: %d\n\n", program.Data.TargetReception)
+		return writeEntriesTable(w, program.TargetEntries)
+	}
+	return nil
+}
+
+func writeEntriesTable(w io.Writer, entries []rating.Entry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "ПОЗИЦИЯ\tПРИОРИТЕТ\tID\tСУММА\tВИ\tИД\tСОГЛАСИЕ\t")
+	for _, e := range entries {
+		agreement := ""
+		if e.IsSendAgreement {
+			agreement = "да"
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%g\t%g\t%g\t%s\t\n", e.Position, e.Priority, e.SSPVOID, e.TotalScores, e.ExamScores, e.IAScores, agreement)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/export"
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+const exportUsage = `export [flags] program <program_id>
+       %[1]s export [flags] student <student_id>
+
+Writes the full admission list of a program or the summary of a student as CSV or XLSX.`
+
+func (a *app) export(ctx context.Context, args []string) int {
+	fs, flags := a.flagSet("export", fmt.Sprintf(exportUsage, a.name))
+	formatFlag := fs.String("format", string(export.FormatCSV), "output format: csv or xlsx")
+	output := fs.String("output", "", "file to write, stdout when empty")
+	degreeFlag := fs.String("degree", string(rating.DegreeMaster), "degree of the admission lists")
+	basisFlag := fs.String("basis", string(rating.BasisBudget), "admission list of the program: budget or contract")
+	if err := fs.Parse(args); err != nil {
+		return Fail
+	}
+
+	format, err := export.ParseFormat(*formatFlag)
+	if err != nil {
+		slog.Error("invalid export flags", "err", err)
+		return Fail
+	}
+	degree, err := rating.ParseDegree(*degreeFlag)
+	if err != nil {
+		slog.Error("invalid export flags", "err", err)
+		return Fail
+	}
+	basis, err := rating.ParseBasis(*basisFlag)
+	if err != nil {
+		slog.Error("invalid export flags", "err", err)
+		return Fail
+	}
+	if fs.NArg() != 2 || (fs.Arg(0) != "program" && fs.Arg(0) != "student") {
+		fs.Usage()
+		return Fail
+	}
+	id, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		slog.Error("id must be a number", "id", fs.Arg(1))
+		return Fail
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		return Fail
+	}
+	service, err := newService(cfg, degree)
+	if err != nil {
+		slog.Error("failed to init rating service", "err", err)
+		return Fail
+	}
+
+	var table export.Table
+	switch fs.Arg(0) {
+	case "program":
+		program, err := service.GetProgram(ctx, degree, id)
+		if err != nil {
+			slog.Error("failed to get program", "programID", id, "err", err)
+			return Fail
+		}
+		table = export.Program(program, basis)
+	case "student":
+		summary, err := service.GetStudentSummaryRaw(ctx, degree, fs.Arg(1))
+		if err != nil {
+			slog.Error("failed to get student summary", "studentID", id, "err", err)
+			return Fail
+		}
+		table = export.Summary(summary)
+	}
+
+	if err := a.writeTable(*output, format, table); err != nil {
+		slog.Error("failed to export", "err", err)
+		return Fail
+	}
+	return Success
+}
+
+// writeTable writes to the file at path, to stdout when path is empty.
+func (a *app) writeTable(path string, format export.Format, table export.Table) error {
+	if path == "" {
+		w := bufio.NewWriter(a.stdout)
+		if err := export.Write(w, format, table); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	w := bufio.NewWriter(f)
+	err = export.Write(w, format, table)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"context"
+	"itmo-ratings/internal/config"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/infrustructure/bot"
+	"log/slog"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+type messageSender interface {
+	SendMessage(ctx context.Context, userID int64, content string) error
+}
+
+// notify sends the summary of every configured student. Summaries go through the outbox: they are written
+// there first and delivered with retries, the ones still failing are left to the next run. It exits with
+// PartialFail when only some of the notifications were delivered.
+func (a *app) notify(ctx context.Context, args []string) int {
+	fs, flags := a.flagSet("notify", "notify [flags]")
+	retryWait := fs.Duration("retry-wait", time.Minute, "how long to wait for retries of failed notifications, later ones are left to the next run")
+	if err := fs.Parse(args); err != nil {
+		return Fail
+	}
+	cfg, err := flags.Load()
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		return Fail
+	}
+	if stop, code := a.printConfig(flags, cfg); stop {
+		return code
+	}
+	// replayed runs are offline, so without a token summaries are printed instead of sent
+	if cfg.Telegram.Token == "" && cfg.Scrapper.ReplayDir == "" {
+		slog.Error("telegram token is not configured")
+		return Fail
+	}
+
+	subscriptions := cfg.Subscriptions()
+	if len(subscriptions) == 0 {
+		slog.Error("no students configured")
+		return Fail
+	}
+	for _, sub := range subscriptions {
+		if len(sub.Recipients) == 0 {
+			slog.Error("student has no recipients", "studentID", sub.StudentID)
+			return Fail
+		}
+	}
+
+	prefs, err := openPreferences(cfg)
+	if err != nil {
+		slog.Error("failed to load preferences", "err", err)
+		return Fail
+	}
+	prefs.Apply(subscriptions)
+	history, err := openHistory(cfg)
+	if err != nil {
+		slog.Error("failed to load summary history", "err", err)
+		return Fail
+	}
+	schedule, err := openDelivery(cfg)
+	if err != nil {
+		slog.Error("failed to load delivery state", "err", err)
+		return Fail
+	}
+	box, err := openOutbox(cfg)
+	if err != nil {
+		slog.Error("failed to load outbox", "err", err)
+		return Fail
+	}
+
+	degrees := lo.Uniq(lo.Map(subscriptions, func(sub rating.Subscription, _ int) rating.Degree {
+		return sub.Degree
+	}))
+	runner, err := newService(cfg, degrees...)
+	if err != nil {
+		slog.Error("failed to init rating service", "err", err)
+		return Fail
+	}
+
+	deliveries, err := runner.Notify(ctx, box, history, schedule, subscriptions)
+	if err != nil {
+		slog.Error("failed to update status", "err", err)
+		return Fail
+	}
+	// rules of the next run would compare against stale summaries and fire again
+	if err := history.Save(); err != nil {
+		slog.Error("failed to save summary history", "err", err)
+		return Fail
+	}
+	// digests and queued messages would be lost or sent twice
+	if err := schedule.Save(); err != nil {
+		slog.Error("failed to save delivery state", "err", err)
+		return Fail
+	}
+
+	failed, skipped, queued := 0, 0, 0
+	for _, d := range deliveries {
+		switch {
+		case d.Err != nil:
+			failed++
+			slog.Error("failed to notify", "studentID", d.StudentID, "chatID", d.ChatID, "err", d.Err)
+		case d.Skipped:
+			skipped++
+		case d.Queued:
+			queued++
+		}
+	}
+
+	result, err := box.Deliver(ctx, a.telegram(cfg), *retryWait)
+	if err != nil {
+		slog.Error("failed to deliver outbox", "err", err)
+		return Fail
+	}
+	slog.Info("summaries delivered", "sent", result.Sent, "pending", result.Pending, "failed", failed+result.Failed,
+		"skipped", skipped, "queued", queued)
+
+	switch {
+	case failed+result.Failed+result.Pending == 0:
+		return Success
+	case result.Sent == 0:
+		return Fail
+	default:
+		return PartialFail
+	}
+}
+
+// telegram sends messages with the bot, or prints them when no token is configured.
+func (a *app) telegram(cfg *config.Config) messageSender {
+	if cfg.Telegram.Token == "" {
+		return bot.NewConsole(a.stdout)
+	}
+	var options []bot.Option
+	if cfg.Telegram.Debug {
+		options = append(options, bot.WithDebug())
+	}
+	return bot.New(cfg.Telegram.Token, options...)
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"itmo-ratings/internal/infrustructure/outbox"
+	"itmo-ratings/pkg/tgmarkup"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const outboxUsage = `outbox [flags] list|deliver|replay [id...]
+
+Inspects the notifications notify keeps in data_dir/outbox.json until they are delivered.
+  list     print the notifications, --state filters them
+  deliver  send the pending notifications now
+  replay   make the notifications with the given IDs (or prefixes) pending again and send them,
+           --failed replays every failed one`
+
+const outboxPreviewLen = 50
+
+func (a *app) outbox(ctx context.Context, args []string) int {
+	fs, flags := a.flagSet("outbox", outboxUsage)
+	state := fs.String("state", "", "list only notifications in the state: pending, sent or failed")
+	format := fs.String("format", "text", "list output format: text or json")
+	failed := fs.Bool("failed", false, "replay every failed notification")
+	retryWait := fs.Duration("retry-wait", time.Minute, "how long deliver and replay wait for retries")
+	if err := fs.Parse(args); err != nil {
+		return Fail
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return Fail
+	}
+	if err := parseChoice("format", *format, "text", "json"); err != nil {
+		slog.Error("invalid outbox flags", "err", err)
+		return Fail
+	}
+	var states []outbox.State
+	if *state != "" {
+		if err := parseChoice("state", *state, lo.Map(outbox.States(), func(s outbox.State, _ int) string { return string(s) })...); err != nil {
+			slog.Error("invalid outbox flags", "err", err)
+			return Fail
+		}
+		states = append(states, outbox.State(*state))
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		return Fail
+	}
+	if stop, code := a.printConfig(flags, cfg); stop {
+		return code
+	}
+	if cfg.DataDir == "" {
+		slog.Error("data_dir is not configured, notify keeps the outbox in memory only")
+		return Fail
+	}
+	box, err := openOutbox(cfg)
+	if err != nil {
+		slog.Error("failed to load outbox", "err", err)
+		return Fail
+	}
+
+	switch fs.Arg(0) {
+	case "list":
+		list := box.List(states...)
+		if *format == "json" {
+			if list == nil {
+				list = []outbox.Notification{}
+			}
+			err = a.writeJSON(list)
+		} else {
+			err = writeOutboxText(a.stdout, list)
+		}
+		if err != nil {
+			slog.Error("failed to write outbox", "err", err)
+			return Fail
+		}
+		return Success
+	case "deliver":
+	case "replay":
+		ids := fs.Args()[1:]
+		if *failed {
+			ids = append(ids, lo.Map(box.List(outbox.StateFailed), func(n outbox.Notification, _ int) string { return n.ID })...)
+		}
+		if len(ids) == 0 {
+			slog.Error("no notifications to replay, pass their IDs or --failed")
+			return Fail
+		}
+		replayed, err := box.Replay(ids...)
+		if err != nil {
+			slog.Error("failed to replay notifications", "err", err)
+			return Fail
+		}
+		slog.Info("notifications replayed", "count", len(replayed))
+	default:
+		fs.Usage()
+		return Fail
+	}
+
+	result, err := box.Deliver(ctx, a.telegram(cfg), *retryWait)
+	if err != nil {
+		slog.Error("failed to deliver outbox", "err", err)
+		return Fail
+	}
+	slog.Info("outbox delivered", "sent", result.Sent, "pending", result.Pending, "failed", result.Failed)
+	switch {
+	case result.Failed+result.Pending == 0:
+		return Success
+	case result.Sent == 0:
+		return Fail
+	default:
+		return PartialFail
+	}
+}
+
+func writeOutboxText(w io.Writer, list []outbox.Notification) error {
+	if len(list) == 0 {
+		_, err := fmt.Fprintln(w, "Уведомлений нет")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tСОСТОЯНИЕ\tЧАТ\tПОПЫТОК\tСОЗДАНО\tДАЛЬШЕ\tОШИБКА\tТЕКСТ")
+	for _, n := range list {
+		var next string
+		switch {
+		case n.State == outbox.StateSent:
+			next = "отправлено " + n.SentAt.Format(time.DateTime)
+		case n.State == outbox.StatePending && !n.NextAttemptAt.IsZero():
+			next = n.NextAttemptAt.Format(time.DateTime)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\n", n.ID, n.State, n.ChatID, n.Attempts,
+			n.CreatedAt.Format(time.DateTime), dash(next), dash(shorten(n.LastError, outboxPreviewLen)), preview(n.Text))
+	}
+	return tw.Flush()
+}
+
+// preview is the start of the message text on a single line.
+func preview(text string) string {
+	return shorten(strings.Join(strings.Fields(tgmarkup.Plain(text)), " "), outboxPreviewLen)
+}
+
+func shorten(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
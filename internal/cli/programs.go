@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"itmo-ratings/internal/domain/rating"
+	"log/slog"
+	"slices"
+	"text/tabwriter"
+)
+
+const programsUsage = `programs [flags]
+
+Lists the programs of a degree with their places.`
+
+func (a *app) programs(ctx context.Context, args []string) int {
+	fs, flags := a.flagSet("programs", programsUsage)
+	degreeFlag := fs.String("degree", string(rating.DegreeMaster), "degree of the admission lists")
+	format := fs.String("format", "text", "output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return Fail
+	}
+	degree, err := rating.ParseDegree(*degreeFlag)
+	if err != nil {
+		slog.Error("invalid programs flags", "err", err)
+		return Fail
+	}
+	if err := parseChoice("format", *format, "text", "json"); err != nil {
+		slog.Error("invalid programs flags", "err", err)
+		return Fail
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		return Fail
+	}
+	parser, err := newParser(cfg)
+	if err != nil {
+		slog.Error("failed to init scrapper", "err", err)
+		return Fail
+	}
+
+	programs, err := parser.GetAllPrograms(ctx, degree)
+	if err != nil {
+		slog.Error("failed to get programs", "degree", degree, "err", err)
+		return Fail
+	}
+	slices.SortFunc(programs, func(x, y rating.ProgramDirection) int {
+		return x.CompetitiveGroupID - y.CompetitiveGroupID
+	})
+
+	if *format == "json" {
+		err = a.writeJSON(programs)
+	} else {
+		tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tПРОГРАММА\tБЮДЖЕТ\tЦЕЛЕВАЯ\tКОНТРАКТ")
+		for _, p := range programs {
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\n", p.CompetitiveGroupID, p.DirectionTitle, p.BudgetMin, p.TargetReception, p.Contract)
+		}
+		err = tw.Flush()
+	}
+	if err != nil {
+		slog.Error("failed to write programs", "err", err)
+		return Fail
+	}
+	return Success
+}
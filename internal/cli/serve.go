@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"itmo-ratings/internal/config"
+	"itmo-ratings/internal/domain/rating/export"
+	"itmo-ratings/internal/domain/rating/snapshot"
+	sender "itmo-ratings/internal/domain/rating/student_rating_service"
+	"itmo-ratings/internal/domain/rating/timeline"
+	"itmo-ratings/internal/infrustructure/bot"
+	"itmo-ratings/internal/rpc/bot_commands"
+	"itmo-ratings/internal/rpc/dashboard"
+	"itmo-ratings/internal/rpc/leaderboard"
+	"itmo-ratings/internal/rpc/program_chart"
+	"itmo-ratings/internal/rpc/program_entries"
+	"itmo-ratings/internal/rpc/program_neighbours"
+	"itmo-ratings/internal/rpc/program_stats"
+	"itmo-ratings/internal/rpc/rating_summary"
+	"itmo-ratings/internal/rpc/readiness"
+	"itmo-ratings/pkg/chart"
+	"itmo-ratings/pkg/info_handler"
+	"itmo-ratings/pkg/middleware"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// readHeaderTimeout bounds the time a client may take to send request headers.
+const readHeaderTimeout = 10 * time.Second
+
+// serve runs the HTTP API until ctx is cancelled. Up to two positional arguments are the HTTP host and port.
+func (a *app) serve(ctx context.Context, args []string) int {
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	cfg, printConfig, err := config.Load(a.name+" serve", args)
+	if err != nil {
+		slog.Error("failed to load config", "err", err.Error())
+		return Fail
+	}
+	if printConfig {
+		if err := cfg.Print(a.stdout); err != nil {
+			slog.Error("failed to print config", "err", err.Error())
+			return Fail
+		}
+		return Success
+	}
+
+	ratingService, err := newService(cfg, cfg.Degrees...)
+	if err != nil {
+		slog.Error("failed to init rating service", "err", err.Error())
+		return Fail
+	}
+
+	var snapshots *snapshot.Periodic
+	var history *timeline.Builder
+	if cfg.Snapshots.Dir != "" {
+		store, err := snapshot.NewStore(cfg.Snapshots.Dir)
+		if err != nil {
+			slog.Error("failed to init snapshot store", "err", err.Error())
+			return Fail
+		}
+		snapshots = snapshot.NewPeriodic(store, ratingService, cfg.Snapshots.Interval, cfg.Snapshots.Formats...)
+		history = timeline.New(store)
+	}
+
+	prefs, err := openPreferences(cfg)
+	if err != nil {
+		slog.Error("failed to load preferences", "err", err.Error())
+		return Fail
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		refresh := func() {
+			if err := ratingService.EnrichAll(ctx); err != nil {
+				slog.Error("failed to update cache", "err", err.Error())
+			}
+			if snapshots != nil && ctx.Err() == nil {
+				snapshots.Refreshed(ctx)
+			}
+		}
+		// load the cache right away so /readyz doesn't wait for the first tick
+		refresh()
+		t := time.NewTicker(cfg.RefreshInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				refresh()
+			}
+		}
+	}()
+	if cfg.Telegram.Polling {
+		var options []bot.Option
+		if cfg.Telegram.Debug {
+			options = append(options, bot.WithDebug())
+		}
+		telegram := bot.New(cfg.Telegram.Token, options...)
+		commandOptions := []bot_commands.Option{
+			bot_commands.WithSubscriptions(cfg.Subscriptions()),
+			bot_commands.WithPreferences(prefs),
+		}
+		if history != nil {
+			commandOptions = append(commandOptions, bot_commands.WithTimeline(history))
+		}
+		commands := bot_commands.New(ratingService, telegram, commandOptions...)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slog.Info("listening for bot commands")
+			telegram.Listen(ctx, commands.Handle)
+		}()
+	}
+
+	info := info_handler.New()
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/_info", info.ServeHTTP)
+	mux.HandleFunc("/readyz", readiness.New(ratingService).ServeHTTP)
+	mux.Handle("/debug/vars", expvar.Handler())
+	summaryHandler := rating_summary.New(ratingService)
+	mux.HandleFunc("/api/v1/rating/summary/{id}", summaryHandler.ServeHTTP)
+	mux.HandleFunc("/api/v1/rating/{degree}/summary/{id}", summaryHandler.ServeHTTP)
+	mux.HandleFunc("/api/v1/programs/{id}/stats", program_stats.New(ratingService).ServeHTTP)
+	mux.HandleFunc("/api/v1/programs/leaderboard", leaderboard.New(ratingService).ServeHTTP)
+	mux.HandleFunc("/api/v1/programs/{id}/neighbours", program_neighbours.New(ratingService, sender.DefaultNeighbours).ServeHTTP)
+	mux.HandleFunc("/api/v1/programs/{id}/entries.csv", program_entries.New(ratingService, export.FormatCSV).ServeHTTP)
+	mux.HandleFunc("/api/v1/programs/{id}/entries.xlsx", program_entries.New(ratingService, export.FormatXLSX).ServeHTTP)
+	if history != nil {
+		mux.HandleFunc("/api/v1/programs/{id}/chart.svg", program_chart.New(history, chart.FormatSVG).ServeHTTP)
+		mux.HandleFunc("/api/v1/programs/{id}/chart.png", program_chart.New(history, chart.FormatPNG).ServeHTTP)
+	}
+	pages := dashboard.New(ratingService)
+	mux.HandleFunc("/{$}", pages.Search)
+	mux.HandleFunc("/students/{id}", pages.Student)
+	mux.HandleFunc("/programs/{id}", pages.Program)
+	addr := cfg.HTTP.Addr()
+
+	logger := middleware.NewLogger(mux)
+	rateLimiter := middleware.NewRateLimiter(logger, cfg.HTTP.RateLimit.RPS, cfg.HTTP.RateLimit.Burst)
+	defer rateLimiter.Stop()
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           rateLimiter,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("starting http server", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	code := Success
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down http server", "addr", addr)
+	case err := <-serverErr:
+		if err != nil {
+			slog.Error("failed to start http server", "err", err.Error(), "addr", addr)
+			code = Fail
+		}
+		stop()
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to gracefully shutdown http server", "err", err.Error())
+		// closing connections cancels contexts of requests that are still running
+		_ = server.Close()
+	}
+
+	// in-flight Enrich observes ctx cancellation and returns early
+	wg.Wait()
+	if snapshots != nil {
+		// Shutdown may have used up its timeout, the last snapshot gets one of its own
+		flushCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+		defer cancel()
+		snapshots.Flush(flushCtx)
+	}
+	slog.Info("http server stopped")
+	return code
+}
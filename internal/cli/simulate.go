@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/admission"
+	"itmo-ratings/internal/domain/rating/snapshot"
+	"log/slog"
+	"maps"
+	"slices"
+	"text/tabwriter"
+)
+
+const simulateUsage = `simulate [flags]
+
+Projects the budget general competition of a degree, see the admission package for the model.
+Runs on the current ratings, or on a saved snapshot with --snapshot.`
+
+type simulatedProgram struct {
+	ProgramID    int    `json:"program_id"`
+	ProgramTitle string `json:"program_title"`
+	admission.Cutoff
+}
+
+type simulation struct {
+	Degree   rating.Degree      `json:"degree"`
+	Snapshot string             `json:"snapshot,omitempty"`
+	Programs []simulatedProgram `json:"programs"`
+	// Student is set with --student, AssignedProgram is nil when they are not projected to pass anywhere.
+	Student         string `json:"student,omitempty"`
+	AssignedProgram *int   `json:"assigned_program,omitempty"`
+}
+
+func (a *app) simulate(ctx context.Context, args []string) int {
+	fs, flags := a.flagSet("simulate", simulateUsage)
+	degreeFlag := fs.String("degree", string(rating.DegreeMaster), "degree of the admission lists")
+	studentID := fs.String("student", "", "also print the program the student is projected to enter")
+	snapshotRef := fs.String("snapshot", "", "snapshot ID or NDJSON path to simulate instead of the current ratings")
+	dirFlag := fs.String("dir", "", "snapshot directory (default snapshots.dir or ./snapshots)")
+	format := fs.String("format", "text", "output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return Fail
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return Fail
+	}
+	degree, err := rating.ParseDegree(*degreeFlag)
+	if err != nil {
+		slog.Error("invalid simulate flags", "err", err)
+		return Fail
+	}
+	if err := parseChoice("format", *format, "text", "json"); err != nil {
+		slog.Error("invalid simulate flags", "err", err)
+		return Fail
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		return Fail
+	}
+
+	var programs map[int]rating.ProgramData
+	if *snapshotRef != "" {
+		store, err := snapshot.NewStore(snapshotDir(cfg.Snapshots.Dir, *dirFlag))
+		if err != nil {
+			slog.Error("failed to init snapshot store", "err", err)
+			return Fail
+		}
+		snap, err := store.Load(*snapshotRef)
+		if err != nil {
+			slog.Error("failed to load snapshot", "ref", *snapshotRef, "err", err)
+			return Fail
+		}
+		programs = snapshot.Programs(snap.Rows)[degree]
+		if len(programs) == 0 {
+			slog.Error("snapshot has no programs of the degree", "ref", *snapshotRef, "degree", degree)
+			return Fail
+		}
+	} else {
+		service, err := newService(cfg, degree)
+		if err != nil {
+			slog.Error("failed to init rating service", "err", err)
+			return Fail
+		}
+		if programs, err = service.GetPrograms(ctx, degree); err != nil {
+			slog.Error("failed to get programs", "degree", degree, "err", err)
+			return Fail
+		}
+	}
+
+	result := admission.Simulate(programs)
+	out := simulation{Degree: degree, Snapshot: *snapshotRef, Student: *studentID}
+	for _, id := range slices.Sorted(maps.Keys(programs)) {
+		out.Programs = append(out.Programs, simulatedProgram{
+			ProgramID:    id,
+			ProgramTitle: programs[id].Data.DirectionTitle,
+			Cutoff:       result.Cutoffs[id],
+		})
+	}
+	if assigned, ok := result.Assigned[*studentID]; ok {
+		out.AssignedProgram = &assigned
+	}
+
+	if *format == "json" {
+		err = a.writeJSON(out)
+	} else {
+		err = writeSimulationText(a.stdout, out)
+	}
+	if err != nil {
+		slog.Error("failed to write simulation", "err", err)
+		return Fail
+	}
+	return Success
+}
+
+func writeSimulationText(w io.Writer, s simulation) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tПРОГРАММА\tМЕСТ\tПРОХОДЯТ\tПРОХОДНОЙ\tПОЗИЦИЯ")
+	for _, p := range s.Programs {
+		cutoff := "все проходят"
+		if p.Full {
+			cutoff = fmt.Sprintf("%g", p.Score)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%s\t%d\n", p.ProgramID, p.ProgramTitle, p.Seats, p.Admitted, cutoff, p.Position)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if s.Student == "" {
+		return nil
+	}
+	if s.AssignedProgram == nil {
+		_, err := fmt.Fprintf(w, "\n%s не проходит на бюджет ни на одну программу\n", s.Student)
+		return err
+	}
+	i := slices.IndexFunc(s.Programs, func(p simulatedProgram) bool { return p.ProgramID == *s.AssignedProgram })
+	_, err := fmt.Fprintf(w, "\n%s проходит на бюджет: %s (%d)\n", s.Student, s.Programs[i].ProgramTitle, *s.AssignedProgram)
+	return err
+}
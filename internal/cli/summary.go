@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/report"
+	"log/slog"
+	"strconv"
+)
+
+const summaryUsage = `summary [flags] <student_id>
+
+Prints the summary of a student in every program, no Telegram token needed.`
+
+func (a *app) summary(ctx context.Context, args []string) int {
+	fs, flags := a.flagSet("summary", summaryUsage)
+	degreeFlag := fs.String("degree", string(rating.DegreeMaster), "degree of the admission lists")
+	format := fs.String("format", "text", "output format: text, telegram (the message in Telegram HTML markup) or json")
+	templateName := fs.String("template", report.DefaultTemplate, "layout of the telegram format: compact, detailed or table")
+	locale := fs.String("locale", report.DefaultLocale, "language of the telegram format: ru or en")
+	timezone := fs.String("timezone", "", "IANA timezone of update times in the telegram format, the zone of the lists when empty")
+	if err := fs.Parse(args); err != nil {
+		return Fail
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return Fail
+	}
+	studentID := fs.Arg(0)
+	if _, err := strconv.Atoi(studentID); err != nil {
+		slog.Error("student id must be a number", "id", studentID)
+		return Fail
+	}
+	degree, err := rating.ParseDegree(*degreeFlag)
+	if err != nil {
+		slog.Error("invalid summary flags", "err", err)
+		return Fail
+	}
+	if err := parseChoice("format", *format, "text", "telegram", "json"); err != nil {
+		slog.Error("invalid summary flags", "err", err)
+		return Fail
+	}
+	messageFormat, err := report.ParseFormat(*templateName, *locale, *timezone)
+	if err != nil {
+		slog.Error("invalid summary flags", "err", err)
+		return Fail
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		return Fail
+	}
+	service, err := newService(cfg, degree)
+	if err != nil {
+		slog.Error("failed to init rating service", "err", err)
+		return Fail
+	}
+
+	if *format == "telegram" {
+		summary, err := service.GetStudentSummary(ctx, degree, studentID, messageFormat)
+		if err != nil {
+			slog.Error("failed to get student summary", "studentID", studentID, "err", err)
+			return Fail
+		}
+		fmt.Fprint(a.stdout, summary)
+		return Success
+	}
+
+	summary, err := service.GetStudentSummaryRaw(ctx, degree, studentID)
+	if err != nil {
+		slog.Error("failed to get student summary", "studentID", studentID, "err", err)
+		return Fail
+	}
+	if *format == "json" {
+		err = a.writeJSON(summary)
+	} else {
+		err = writeSummaryText(a.stdout, summary)
+	}
+	if err != nil {
+		slog.Error("failed to write summary", "err", err)
+		return Fail
+	}
+	return Success
+}
+
+func writeSummaryText(w io.Writer, summary *rating.StudentSummary) error {
+	for i, e := range summary.Entries {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%d. %s (%d), %s\n", e.Priority, e.ProgramTitle, e.ProgramID, e.Basis)
+		fmt.Fprintf(w, "   позиция %d из %d мест, заявлений %d\n", e.Position, e.Seats, e.TotalApplications)
+		fmt.Fprintf(w, "   выше в списке с более низким приоритетом: %d\n", e.LowerPriorityAhead)
+		if e.Basis == rating.BasisBudget {
+			verdict := "не проходит"
+			if e.ProjectedPass {
+				verdict = "проходит"
+			}
+			fmt.Fprintf(w, "   прогноз: %s, проходной балл %.0f\n", verdict, e.ProjectedCutoff)
+		}
+		if _, err := fmt.Fprintf(w, "   обновлено %s\n", e.LastUpdatedFormatted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/tui"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+)
+
+const tuiUsage = `tui [flags]
+
+Browses the programs, their lists and the student summaries in the terminal. Students from the
+config and from --students are highlighted, the ratings are refreshed every refresh-interval.`
+
+func (a *app) tui(ctx context.Context, args []string) int {
+	fs, flags := a.flagSet("tui", tuiUsage)
+	students := fs.String("students", "", "comma separated student IDs to highlight besides the configured ones")
+	if err := fs.Parse(args); err != nil {
+		return Fail
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		slog.Error("failed to load config", "err", err)
+		return Fail
+	}
+
+	degrees := slices.Clone(cfg.Degrees)
+	var tracked []string
+	for _, sub := range cfg.Subscriptions() {
+		tracked = append(tracked, sub.StudentID)
+		if !slices.Contains(degrees, sub.Degree) {
+			degrees = append(degrees, sub.Degree)
+		}
+	}
+	for _, id := range strings.Split(*students, ",") {
+		if id = strings.TrimSpace(id); id != "" && !slices.Contains(tracked, id) {
+			tracked = append(tracked, id)
+		}
+	}
+	if len(degrees) == 0 {
+		degrees = []rating.Degree{rating.DegreeMaster}
+	}
+
+	service, err := newService(cfg, degrees...)
+	if err != nil {
+		slog.Error("failed to init rating service", "err", err)
+		return Fail
+	}
+
+	err = tui.Run(ctx, service, os.Stdin, a.stdout,
+		tui.WithStudents(tracked...),
+		tui.WithRefreshInterval(cfg.RefreshInterval),
+	)
+	if err != nil {
+		slog.Error("failed to run terminal interface", "err", err)
+		return Fail
+	}
+	return Success
+}
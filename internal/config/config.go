@@ -0,0 +1,525 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/report"
+	"itmo-ratings/internal/domain/rating/scrapper"
+	"itmo-ratings/internal/domain/rating/snapshot"
+
+	"github.com/BurntSushi/toml"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+const maskedSecret = "******"
+
+type Config struct {
+	HTTP HTTP `yaml:"http" toml:"http"`
+	// Degrees are the admission lists refreshed by the service.
+	Degrees         []rating.Degree `yaml:"degrees" toml:"degrees"`
+	RefreshInterval time.Duration   `yaml:"refresh_interval" toml:"refresh_interval"`
+	Scrapper        Scrapper        `yaml:"scrapper" toml:"scrapper"`
+	Telegram        Telegram        `yaml:"telegram" toml:"telegram"`
+	Snapshots       Snapshots       `yaml:"snapshots" toml:"snapshots"`
+	Students        []Student       `yaml:"students" toml:"students"`
+	// DataDir keeps the state changed from the bot, like muted programs, and the state notify keeps between runs.
+	// It's held in memory when empty.
+	DataDir string `yaml:"data_dir" toml:"data_dir"`
+}
+
+type HTTP struct {
+	Host            string        `yaml:"host" toml:"host"`
+	Port            int           `yaml:"port" toml:"port"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	RateLimit       RateLimit     `yaml:"rate_limit" toml:"rate_limit"`
+}
+
+type RateLimit struct {
+	RPS   int `yaml:"rps" toml:"rps"`
+	Burst int `yaml:"burst" toml:"burst"`
+}
+
+type Scrapper struct {
+	SiteURL    string        `yaml:"site_url" toml:"site_url"`
+	APIURL     string        `yaml:"api_url" toml:"api_url"`
+	Timeout    time.Duration `yaml:"timeout" toml:"timeout"`
+	MaxRetries int           `yaml:"max_retries" toml:"max_retries"`
+	RetryDelay time.Duration `yaml:"retry_delay" toml:"retry_delay"`
+	// MaxPageSize caps a single downloaded page or API response, in bytes.
+	MaxPageSize int64 `yaml:"max_page_size" toml:"max_page_size"`
+	// RecordDir saves every downloaded page and API response to the directory.
+	RecordDir string `yaml:"record_dir" toml:"record_dir"`
+	// ReplayDir serves pages and API responses from a directory filled by RecordDir instead of the network.
+	ReplayDir string `yaml:"replay_dir" toml:"replay_dir"`
+}
+
+type Telegram struct {
+	Token string `yaml:"token" toml:"token"`
+	Debug bool   `yaml:"debug" toml:"debug"`
+	// Polling makes the service answer bot commands.
+	Polling bool `yaml:"polling" toml:"polling"`
+}
+
+// Snapshots configures the dumps the service saves of its rating cache.
+type Snapshots struct {
+	// Dir enables the dumps, they are not saved when empty.
+	Dir string `yaml:"dir" toml:"dir"`
+	// Interval is the minimal time between two dumps, the last cache state is also saved on shutdown.
+	Interval time.Duration     `yaml:"interval" toml:"interval"`
+	Formats  []snapshot.Format `yaml:"formats" toml:"formats"`
+}
+
+type Student struct {
+	ID string `yaml:"id" toml:"id"`
+	// Degree of the admission lists the student is looked up in, master when empty.
+	Degree     rating.Degree `yaml:"degree" toml:"degree"`
+	Recipients []Recipient   `yaml:"recipients" toml:"recipients"`
+}
+
+// Recipient is a Telegram chat receiving the student's summary: a user, a group or a channel.
+type Recipient struct {
+	ChatID int64 `yaml:"chat_id" toml:"chat_id"`
+	// Template is the summary layout: compact, detailed (default) or table.
+	Template string `yaml:"template,omitempty" toml:"template,omitempty"`
+	// Locale is the summary language: ru (default) or en.
+	Locale string `yaml:"locale,omitempty" toml:"locale,omitempty"`
+	// Timezone is an IANA name like Europe/Moscow for update times, the zone of the lists when empty.
+	Timezone string `yaml:"timezone,omitempty" toml:"timezone,omitempty"`
+	// DigestAt is the local time like 20:00 of a daily digest of the changes, summaries are sent right away when empty.
+	DigestAt string `yaml:"digest_at,omitempty" toml:"digest_at,omitempty"`
+	// QuietHours like 23:00-08:00 hold messages back until they end, in the local time too.
+	QuietHours string `yaml:"quiet_hours,omitempty" toml:"quiet_hours,omitempty"`
+}
+
+// Default returns the configuration used when nothing is overridden.
+func Default() Config {
+	return Config{
+		HTTP: HTTP{
+			Host:            "0.0.0.0",
+			Port:            8080,
+			ShutdownTimeout: 15 * time.Second,
+			RateLimit: RateLimit{
+				RPS:   10,
+				Burst: 20,
+			},
+		},
+		Degrees:         []rating.Degree{rating.DegreeMaster},
+		RefreshInterval: 5 * time.Minute,
+		Snapshots: Snapshots{
+			Interval: time.Hour,
+			Formats:  snapshot.Formats(),
+		},
+		Scrapper: Scrapper{
+			SiteURL:     scrapper.DefaultSiteURL,
+			APIURL:      scrapper.DefaultAPIURL,
+			Timeout:     scrapper.DefaultTimeout,
+			MaxRetries:  scrapper.DefaultMaxRetries,
+			RetryDelay:  scrapper.DefaultRetryDelay,
+			MaxPageSize: scrapper.DefaultMaxPageSize,
+		},
+	}
+}
+
+// Load builds the effective configuration for the binary called name.
+// Sources are applied in order: defaults, config file, environment, flags.
+// The second return value reports whether --print-config was requested.
+//
+// For compatibility with older deployments up to two positional arguments
+// are accepted as the HTTP host and port.
+func Load(name string, args []string) (*Config, bool, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, false, err
+	}
+
+	cfg, err := flags.load(func(cfg *Config) error {
+		if fs.NArg() > 0 {
+			cfg.HTTP.Host = fs.Arg(0)
+		}
+		if fs.NArg() > 1 {
+			p, err := strconv.Atoi(fs.Arg(1))
+			if err != nil {
+				return fmt.Errorf("invalid port argument %q: %w", fs.Arg(1), err)
+			}
+			cfg.HTTP.Port = p
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return cfg, flags.PrintConfig(), nil
+}
+
+// Flags are the configuration flags registered on a flag set, for commands that add flags of their own.
+type Flags struct {
+	fs          *flag.FlagSet
+	path        *string
+	printConfig *bool
+	host        *string
+	port        *int
+	degrees     *string
+	refresh     *time.Duration
+	record      *string
+	replay      *string
+}
+
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	return &Flags{
+		fs:          fs,
+		path:        fs.String("config", os.Getenv("CONFIG_PATH"), "path to YAML or TOML (.toml) config file (env CONFIG_PATH)"),
+		printConfig: fs.Bool("print-config", false, "print the effective configuration and exit"),
+		host:        fs.String("host", "", "HTTP listen host (env HTTP_HOST)"),
+		port:        fs.Int("port", 0, "HTTP listen port (env HTTP_PORT)"),
+		degrees:     fs.String("degrees", "", "comma separated degrees to track: bachelor, master, postgraduate (env DEGREES)"),
+		refresh:     fs.Duration("refresh-interval", 0, "rating cache refresh interval (env REFRESH_INTERVAL)"),
+		record:      fs.String("record", "", "save ITMO responses to the directory"),
+		replay:      fs.String("replay", "", "serve ITMO responses from a directory recorded with --record"),
+	}
+}
+
+// PrintConfig reports whether --print-config was requested.
+func (f *Flags) PrintConfig() bool {
+	return *f.printConfig
+}
+
+// Load builds the effective configuration once the flag set is parsed.
+func (f *Flags) Load() (*Config, error) {
+	return f.load(nil)
+}
+
+// load applies the sources in order, override runs between the environment and the flags.
+func (f *Flags) load(override func(*Config) error) (*Config, error) {
+	cfg := Default()
+
+	if *f.path != "" {
+		if err := cfg.loadFile(*f.path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	if override != nil {
+		if err := override(&cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "host":
+			cfg.HTTP.Host = *f.host
+		case "port":
+			cfg.HTTP.Port = *f.port
+		case "degrees":
+			cfg.Degrees = splitDegrees(*f.degrees)
+		case "refresh-interval":
+			cfg.RefreshInterval = *f.refresh
+		case "record":
+			cfg.Scrapper.RecordDir = *f.record
+		case "replay":
+			cfg.Scrapper.ReplayDir = *f.replay
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		meta, err := toml.Decode(string(content), c)
+		if err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("failed to parse config file %s: unknown fields %v", path, undecoded)
+		}
+		return nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	if v, ok := os.LookupEnv("HTTP_HOST"); ok {
+		c.HTTP.Host = v
+	}
+	if v, ok := os.LookupEnv("HTTP_PORT"); ok {
+		p, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid HTTP_PORT %q: %w", v, err)
+		}
+		c.HTTP.Port = p
+	}
+	if v, ok := os.LookupEnv("DEGREES"); ok {
+		c.Degrees = splitDegrees(v)
+	}
+	if v, ok := os.LookupEnv("REFRESH_INTERVAL"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid REFRESH_INTERVAL %q: %w", v, err)
+		}
+		c.RefreshInterval = d
+	}
+	if v, ok := os.LookupEnv("TELEGRAM_API_TOKEN"); ok {
+		c.Telegram.Token = v
+	}
+	if v, ok := os.LookupEnv("TELEGRAM_DEBUG"); ok {
+		debug, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid TELEGRAM_DEBUG %q: %w", v, err)
+		}
+		c.Telegram.Debug = debug
+	}
+	if v, ok := os.LookupEnv("TELEGRAM_POLLING"); ok {
+		polling, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid TELEGRAM_POLLING %q: %w", v, err)
+		}
+		c.Telegram.Polling = polling
+	}
+
+	if v, ok := os.LookupEnv("SNAPSHOTS_DIR"); ok {
+		c.Snapshots.Dir = v
+	}
+	if v, ok := os.LookupEnv("DATA_DIR"); ok {
+		c.DataDir = v
+	}
+
+	// STUDENT_ID/TELEGRAM_USER_ID describe a single student and are kept for existing deployments
+	if studentID := os.Getenv("STUDENT_ID"); studentID != "" {
+		student := Student{ID: studentID}
+		if v := os.Getenv("TELEGRAM_USER_ID"); v != "" {
+			userID, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid TELEGRAM_USER_ID %q: %w", v, err)
+			}
+			student.Recipients = append(student.Recipients, Recipient{ChatID: userID})
+		}
+		c.Students = append(c.Students, student)
+	}
+
+	return nil
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.HTTP.Port < 1 || c.HTTP.Port > 65535 {
+		errs = append(errs, fmt.Errorf("http.port must be in range 1..65535, got %d", c.HTTP.Port))
+	}
+	if c.HTTP.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("http.shutdown_timeout must be positive"))
+	}
+	if c.HTTP.RateLimit.RPS <= 0 {
+		errs = append(errs, fmt.Errorf("http.rate_limit.rps must be positive"))
+	}
+	if c.HTTP.RateLimit.Burst <= 0 {
+		errs = append(errs, fmt.Errorf("http.rate_limit.burst must be positive"))
+	}
+	if c.Telegram.Polling && c.Telegram.Token == "" {
+		errs = append(errs, fmt.Errorf("telegram.polling requires telegram.token"))
+	}
+	if len(c.Degrees) == 0 {
+		errs = append(errs, fmt.Errorf("degrees must not be empty"))
+	}
+	for i, degree := range c.Degrees {
+		if _, err := rating.ParseDegree(string(degree)); err != nil {
+			errs = append(errs, fmt.Errorf("degrees[%d]: %w", i, err))
+		}
+	}
+	if c.RefreshInterval <= 0 {
+		errs = append(errs, fmt.Errorf("refresh_interval must be positive"))
+	}
+	if c.Snapshots.Dir != "" && c.Snapshots.Interval <= 0 {
+		errs = append(errs, fmt.Errorf("snapshots.interval must be positive"))
+	}
+	for i, format := range c.Snapshots.Formats {
+		if _, err := snapshot.ParseFormat(string(format)); err != nil {
+			errs = append(errs, fmt.Errorf("snapshots.formats[%d]: %w", i, err))
+		}
+	}
+	if err := validateURL(c.Scrapper.SiteURL); err != nil {
+		errs = append(errs, fmt.Errorf("scrapper.site_url: %w", err))
+	}
+	if err := validateURL(c.Scrapper.APIURL); err != nil {
+		errs = append(errs, fmt.Errorf("scrapper.api_url: %w", err))
+	}
+	if c.Scrapper.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("scrapper.timeout must be positive"))
+	}
+	if c.Scrapper.MaxRetries < 1 {
+		errs = append(errs, fmt.Errorf("scrapper.max_retries must be at least 1"))
+	}
+	if c.Scrapper.RetryDelay < 0 {
+		errs = append(errs, fmt.Errorf("scrapper.retry_delay must not be negative"))
+	}
+	if c.Scrapper.MaxPageSize <= 0 {
+		errs = append(errs, fmt.Errorf("scrapper.max_page_size must be positive"))
+	}
+	if c.Scrapper.RecordDir != "" && c.Scrapper.ReplayDir != "" {
+		errs = append(errs, fmt.Errorf("scrapper.record_dir and scrapper.replay_dir are mutually exclusive"))
+	}
+	for i, student := range c.Students {
+		if _, err := strconv.Atoi(student.ID); err != nil {
+			errs = append(errs, fmt.Errorf("students[%d].id must be numeric, got %q", i, student.ID))
+		}
+		if student.Degree != "" {
+			if _, err := rating.ParseDegree(string(student.Degree)); err != nil {
+				errs = append(errs, fmt.Errorf("students[%d].degree: %w", i, err))
+			}
+		}
+		for j, recipient := range student.Recipients {
+			if recipient.ChatID == 0 {
+				errs = append(errs, fmt.Errorf("students[%d].recipients[%d].chat_id must be set", i, j))
+			}
+			if _, err := recipient.Format(); err != nil {
+				errs = append(errs, fmt.Errorf("students[%d].recipients[%d]: %w", i, j, err))
+			}
+			if _, err := recipient.Schedule(); err != nil {
+				errs = append(errs, fmt.Errorf("students[%d].recipients[%d]: %w", i, j, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Print writes the effective configuration as YAML with secrets masked.
+func (c *Config) Print(w io.Writer) error {
+	masked := *c
+	if masked.Telegram.Token != "" {
+		masked.Telegram.Token = maskedSecret
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(masked); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	return encoder.Close()
+}
+
+// Subscriptions maps configured students to their notification recipients.
+func (c *Config) Subscriptions() []rating.Subscription {
+	return lo.Map(c.Students, func(student Student, _ int) rating.Subscription {
+		degree := student.Degree
+		if degree == "" {
+			degree = rating.DegreeMaster
+		}
+		return rating.Subscription{
+			Degree:    degree,
+			StudentID: student.ID,
+			Recipients: lo.Map(student.Recipients, func(r Recipient, _ int) rating.Recipient {
+				// validated on load
+				format, _ := r.Format()
+				schedule, _ := r.Schedule()
+				return rating.Recipient{ChatID: r.ChatID, Format: format, Schedule: schedule}
+			}),
+		}
+	})
+}
+
+// Format converts the summary settings of the recipient.
+func (r Recipient) Format() (rating.SummaryFormat, error) {
+	return report.ParseFormat(r.Template, r.Locale, r.Timezone)
+}
+
+// Schedule converts the delivery settings of the recipient.
+func (r Recipient) Schedule() (rating.Schedule, error) {
+	var schedule rating.Schedule
+	if r.DigestAt != "" {
+		at, err := rating.ParseClock(r.DigestAt)
+		if err != nil {
+			return rating.Schedule{}, fmt.Errorf("digest_at: %w", err)
+		}
+		schedule.Digest = &at
+	}
+	if r.QuietHours != "" {
+		quiet, err := rating.ParseClockRange(r.QuietHours)
+		if err != nil {
+			return rating.Schedule{}, fmt.Errorf("quiet_hours: %w", err)
+		}
+		schedule.Quiet = &quiet
+	}
+	return schedule, nil
+}
+
+// DataPath is the path of a state file in DataDir, empty when DataDir isn't set.
+func (c *Config) DataPath(name string) string {
+	if c.DataDir == "" {
+		return ""
+	}
+	return filepath.Join(c.DataDir, name)
+}
+
+// Addr returns the HTTP listen address.
+func (h HTTP) Addr() string {
+	return fmt.Sprintf("%s:%d", h.Host, h.Port)
+}
+
+func splitDegrees(s string) []rating.Degree {
+	var degrees []rating.Degree
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			degrees = append(degrees, rating.Degree(part))
+		}
+	}
+	return degrees
+}
+
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme in %q", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host in %q", raw)
+	}
+	return nil
+}
+
+// Options converts scrapper settings into scrapper.Service options.
+func (s Scrapper) Options() []scrapper.Option {
+	return []scrapper.Option{
+		scrapper.WithBaseURLs(s.SiteURL, s.APIURL),
+		scrapper.WithTimeout(s.Timeout),
+		scrapper.WithRetries(s.MaxRetries, s.RetryDelay),
+		scrapper.WithMaxPageSize(s.MaxPageSize),
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func loadExample(t *testing.T, name string) *Config {
+	t.Helper()
+	cfg := Default()
+	if err := cfg.loadFile(filepath.Join("..", "..", name)); err != nil {
+		t.Fatalf("loadFile(%s): %v", name, err)
+	}
+	return &cfg
+}
+
+func TestLoadFileTOML(t *testing.T) {
+	yamlCfg := loadExample(t, "config.example.yaml")
+	tomlCfg := loadExample(t, "config.example.toml")
+	if !reflect.DeepEqual(yamlCfg, tomlCfg) {
+		t.Errorf("TOML example differs from YAML:\n%+v\n%+v", tomlCfg, yamlCfg)
+	}
+	if len(tomlCfg.Students) != 2 || len(tomlCfg.Students[0].Recipients) != 2 || tomlCfg.Students[0].Recipients[1].ChatID != -1001234567890 {
+		t.Errorf("got students %+v", tomlCfg.Students)
+	}
+	if err := tomlCfg.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestLoadFileTOMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{name: "unknown field", doc: "[http]\nprot = 8080\n", want: "http.prot"},
+		{name: "unknown table", doc: "[telegramm]\ntoken = \"x\"\n", want: "telegramm"},
+		{name: "leading zero", doc: "[http]\nport = 010\n"},
+		{name: "duplicate key", doc: "refresh_interval = \"1m\"\nrefresh_interval = \"2m\"\n"},
+		{name: "bad duration", doc: "refresh_interval = \"soon\"\n"},
+		{name: "wrong type", doc: "[http]\nport = \"8080\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(tt.doc), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cfg := Default()
+			err := cfg.loadFile(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error with %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package admission
+
+import "itmo-ratings/internal/domain/rating"
+
+// SeatModel splits the budget places of a program between quotas and the general competition.
+//
+// ITMO publishes budget_min as the general competition places only, the target, special and
+// separate quotas come on top of it, so nothing is subtracted from General. Quota places nobody
+// applied for move to the general competition in the final waves. The target quota fill level is
+// estimated from its list. Special and separate quota lists are not published on the rating page,
+// so those quotas are assumed to be filled and never add places.
+type SeatModel struct {
+	General int `json:"general"`
+	Target  int `json:"target"`
+	// TargetFilled is the number of target quota applications up to the quota. Every applicant is
+	// assumed to take their seat, although some may enter a program of a higher priority, so the
+	// leftover is a lower bound.
+	TargetFilled int `json:"targetFilled"`
+	Special      int `json:"special"`
+	Separate     int `json:"separate"`
+	// Effective is the number of general competition places once quota leftovers are added.
+	Effective int `json:"effective"`
+}
+
+// Seats builds the seat model of the budget admission of a program.
+func Seats(program *rating.ProgramData) SeatModel {
+	if program == nil || program.Data == nil {
+		return SeatModel{}
+	}
+
+	m := SeatModel{
+		General:  program.Data.BudgetMin,
+		Target:   program.Data.TargetReception,
+		Special:  program.Data.SpecialQuota,
+		Separate: program.Data.Invalid,
+	}
+	m.TargetFilled = min(m.Target, len(program.TargetEntries))
+	m.Effective = m.General + m.Target - m.TargetFilled
+
+	return m
+}
+
+// ListSeats returns the number of places available on the list of basis.
+func ListSeats(program *rating.ProgramData, basis rating.Basis) int {
+	if basis == rating.BasisContract {
+		return basis.Seats(program.Data)
+	}
+	return Seats(program).Effective
+}
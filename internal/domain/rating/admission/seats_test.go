@@ -0,0 +1,70 @@
+package admission
+
+import (
+	"testing"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+func TestSeats(t *testing.T) {
+	tests := []struct {
+		name    string
+		program *rating.ProgramData
+		want    SeatModel
+	}{
+		{name: "no program"},
+		{name: "no direction", program: &rating.ProgramData{}},
+		{
+			name:    "general only",
+			program: &rating.ProgramData{Data: &rating.ProgramDirection{BudgetMin: 20}},
+			want:    SeatModel{General: 20, Effective: 20},
+		},
+		{
+			// special and separate quotas come on top of budget_min and are assumed filled
+			name: "quotas filled",
+			program: &rating.ProgramData{
+				Data:          &rating.ProgramDirection{BudgetMin: 20, TargetReception: 2, SpecialQuota: 3, Invalid: 1},
+				TargetEntries: make([]rating.Entry, 2),
+			},
+			want: SeatModel{General: 20, Target: 2, TargetFilled: 2, Special: 3, Separate: 1, Effective: 20},
+		},
+		{
+			name: "target quota unfilled",
+			program: &rating.ProgramData{
+				Data:          &rating.ProgramDirection{BudgetMin: 20, TargetReception: 5, SpecialQuota: 3},
+				TargetEntries: make([]rating.Entry, 2),
+			},
+			want: SeatModel{General: 20, Target: 5, TargetFilled: 2, Special: 3, Effective: 23},
+		},
+		{
+			name:    "nobody applied for the target quota",
+			program: &rating.ProgramData{Data: &rating.ProgramDirection{BudgetMin: 10, TargetReception: 4}},
+			want:    SeatModel{General: 10, Target: 4, Effective: 14},
+		},
+		{
+			name: "target quota overapplied",
+			program: &rating.ProgramData{
+				Data:          &rating.ProgramDirection{BudgetMin: 10, TargetReception: 2},
+				TargetEntries: make([]rating.Entry, 7),
+			},
+			want: SeatModel{General: 10, Target: 2, TargetFilled: 2, Effective: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Seats(tt.program); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListSeats(t *testing.T) {
+	program := &rating.ProgramData{Data: &rating.ProgramDirection{BudgetMin: 10, TargetReception: 3, Contract: 40}}
+	if got := ListSeats(program, rating.BasisBudget); got != 13 {
+		t.Errorf("got %d budget seats, want 13 with the unfilled target quota", got)
+	}
+	if got := ListSeats(program, rating.BasisContract); got != 40 {
+		t.Errorf("got %d contract seats, want 40", got)
+	}
+}
@@ -0,0 +1,122 @@
+package admission
+
+import (
+	"slices"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+// Cutoff is the projected result of the budget general competition of a program.
+type Cutoff struct {
+	Seats    int `json:"seats"`
+	Admitted int `json:"admitted"`
+	// Score and Position belong to the last admitted applicant, zero when nobody is admitted.
+	// While the program isn't full every applicant passes.
+	Score    float64 `json:"score"`
+	Position int     `json:"position"`
+	Full     bool    `json:"full"`
+}
+
+// Result is the outcome of Simulate.
+type Result struct {
+	// Assigned maps a student to the competitive group ID of the program they are projected to enter.
+	Assigned map[string]int
+	Cutoffs  map[int]Cutoff
+}
+
+// Passes reports whether the student is projected to enter the program.
+func (r Result) Passes(studentID string, programID int) bool {
+	assigned, ok := r.Assigned[studentID]
+	return ok && assigned == programID
+}
+
+type application struct {
+	programID int
+	entry     *rating.Entry
+}
+
+// Simulate distributes the budget general competition places of all programs of a degree.
+//
+// Applicants are considered in the order of their priorities and programs keep the best ranked
+// applicants up to their effective seats (applicant-proposing deferred acceptance). This matches
+// how priorities work in the final admission waves, assuming every applicant submits the agreement.
+func Simulate(programs map[int]rating.ProgramData) Result {
+	applications := make(map[string][]application)
+	seats := make(map[int]int, len(programs))
+	for id, program := range programs {
+		seats[id] = Seats(&program).Effective
+		for i := range program.Entries {
+			entry := &program.Entries[i]
+			if entry.SSPVOID == "" {
+				continue
+			}
+			applications[entry.SSPVOID] = append(applications[entry.SSPVOID], application{
+				programID: id,
+				entry:     entry,
+			})
+		}
+	}
+
+	free := make([]string, 0, len(applications))
+	for studentID, apps := range applications {
+		slices.SortFunc(apps, func(a, b application) int {
+			return a.entry.Priority - b.entry.Priority
+		})
+		free = append(free, studentID)
+	}
+	// map iteration is random, keep the result reproducible
+	slices.Sort(free)
+
+	next := make(map[string]int, len(applications))
+	held := make(map[int][]*rating.Entry, len(programs))
+
+	for len(free) > 0 {
+		studentID := free[len(free)-1]
+		free = free[:len(free)-1]
+
+		apps := applications[studentID]
+		if next[studentID] >= len(apps) {
+			continue
+		}
+		app := apps[next[studentID]]
+		next[studentID]++
+
+		admitted := held[app.programID]
+		idx, _ := slices.BinarySearchFunc(admitted, app.entry.Position, func(e *rating.Entry, position int) int {
+			return e.Position - position
+		})
+		admitted = slices.Insert(admitted, idx, app.entry)
+
+		if len(admitted) > seats[app.programID] {
+			rejected := admitted[len(admitted)-1]
+			admitted = admitted[:len(admitted)-1]
+			free = append(free, rejected.SSPVOID)
+		}
+		held[app.programID] = admitted
+	}
+
+	result := Result{
+		Assigned: make(map[string]int),
+		Cutoffs:  make(map[int]Cutoff, len(programs)),
+	}
+	for id := range programs {
+		admitted := held[id]
+		cutoff := Cutoff{
+			Seats:    seats[id],
+			Admitted: len(admitted),
+			Full:     len(admitted) >= seats[id],
+		}
+		if len(admitted) > 0 {
+			last := admitted[len(admitted)-1]
+			cutoff.Score = last.TotalScores
+			cutoff.Position = last.Position
+		}
+		result.Cutoffs[id] = cutoff
+
+		for _, entry := range admitted {
+			result.Assigned[entry.SSPVOID] = id
+		}
+	}
+
+	return result
+}
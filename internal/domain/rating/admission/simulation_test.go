@@ -0,0 +1,118 @@
+package admission
+
+import (
+	"maps"
+	"testing"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+// applicant is an entry of a budget list: the student, their priority for the program and score.
+type applicant struct {
+	id       string
+	priority int
+	score    float64
+}
+
+// program builds a budget list ranked by score, in the order given.
+func program(id, seats, target int, applicants ...applicant) rating.ProgramData {
+	p := rating.ProgramData{
+		Data:          &rating.ProgramDirection{CompetitiveGroupID: id, BudgetMin: seats, TargetReception: target},
+		TargetEntries: make([]rating.Entry, 0),
+	}
+	for i, a := range applicants {
+		p.Entries = append(p.Entries, rating.Entry{SSPVOID: a.id, Priority: a.priority, Position: i + 1, TotalScores: a.score})
+	}
+	return p
+}
+
+func TestSimulate(t *testing.T) {
+	tests := []struct {
+		name     string
+		programs []rating.ProgramData
+		assigned map[string]int
+		cutoffs  map[int]Cutoff
+	}{
+		{
+			name: "single program",
+			programs: []rating.ProgramData{
+				program(1, 2, 0, applicant{"a", 1, 90}, applicant{"b", 1, 80}, applicant{"c", 1, 70}),
+			},
+			assigned: map[string]int{"a": 1, "b": 1},
+			cutoffs:  map[int]Cutoff{1: {Seats: 2, Admitted: 2, Score: 80, Position: 2, Full: true}},
+		},
+		{
+			// a ranks first on both but prefers program 2, which frees program 1 for c
+			name: "priority frees a seat",
+			programs: []rating.ProgramData{
+				program(1, 1, 0, applicant{"a", 2, 95}, applicant{"c", 1, 60}),
+				program(2, 1, 0, applicant{"a", 1, 90}, applicant{"b", 2, 85}),
+			},
+			assigned: map[string]int{"a": 2, "c": 1},
+			cutoffs: map[int]Cutoff{
+				1: {Seats: 1, Admitted: 1, Score: 60, Position: 2, Full: true},
+				2: {Seats: 1, Admitted: 1, Score: 90, Position: 1, Full: true},
+			},
+		},
+		{
+			// b is pushed out of program 1 by a better ranked applicant of the same priority
+			// and goes down to their second priority
+			name: "rejected applicant moves on",
+			programs: []rating.ProgramData{
+				program(1, 1, 0, applicant{"a", 1, 95}, applicant{"b", 1, 90}),
+				program(2, 1, 0, applicant{"b", 2, 90}, applicant{"c", 1, 50}),
+			},
+			assigned: map[string]int{"a": 1, "b": 2},
+			cutoffs: map[int]Cutoff{
+				1: {Seats: 1, Admitted: 1, Score: 95, Position: 1, Full: true},
+				2: {Seats: 1, Admitted: 1, Score: 90, Position: 1, Full: true},
+			},
+		},
+		{
+			// two target seats nobody applied for go to the general competition
+			name: "unfilled target quota",
+			programs: []rating.ProgramData{
+				program(1, 1, 2, applicant{"a", 1, 90}, applicant{"b", 1, 80}, applicant{"c", 1, 70}, applicant{"d", 1, 60}),
+			},
+			assigned: map[string]int{"a": 1, "b": 1, "c": 1},
+			cutoffs:  map[int]Cutoff{1: {Seats: 3, Admitted: 3, Score: 70, Position: 3, Full: true}},
+		},
+		{
+			name: "not full",
+			programs: []rating.ProgramData{
+				program(1, 5, 0, applicant{"a", 1, 90}, applicant{"", 1, 85}),
+			},
+			assigned: map[string]int{"a": 1},
+			cutoffs:  map[int]Cutoff{1: {Seats: 5, Admitted: 1, Score: 90, Position: 1}},
+		},
+		{
+			name:     "nobody applied",
+			programs: []rating.ProgramData{program(1, 3, 0)},
+			assigned: map[string]int{},
+			cutoffs:  map[int]Cutoff{1: {Seats: 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			programs := make(map[int]rating.ProgramData)
+			for _, p := range tt.programs {
+				programs[p.Data.CompetitiveGroupID] = p
+			}
+			result := Simulate(programs)
+			if !maps.Equal(result.Assigned, tt.assigned) {
+				t.Errorf("got assigned %v, want %v", result.Assigned, tt.assigned)
+			}
+			if !maps.Equal(result.Cutoffs, tt.cutoffs) {
+				t.Errorf("got cutoffs %+v, want %+v", result.Cutoffs, tt.cutoffs)
+			}
+			for studentID, programID := range tt.assigned {
+				if !result.Passes(studentID, programID) {
+					t.Errorf("%s doesn't pass to %d", studentID, programID)
+				}
+			}
+			if result.Passes("a", 100) {
+				t.Error("passes to an unknown program")
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package rating
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Basis is the funding basis of an admission list, as used in ITMO URLs.
+type Basis string
+
+const (
+	BasisBudget   Basis = "budget"
+	BasisContract Basis = "contract"
+)
+
+// Seats returns the number of places of the program for the basis.
+func (b Basis) Seats(program *ProgramDirection) int {
+	if program == nil {
+		return 0
+	}
+	if b == BasisContract {
+		return program.Contract
+	}
+	return program.BudgetMin
+}
+
+// Bases lists all supported bases.
+func Bases() []Basis {
+	return []Basis{BasisBudget, BasisContract}
+}
+
+func ParseBasis(s string) (Basis, error) {
+	b := Basis(s)
+	if !slices.Contains(Bases(), b) {
+		return "", fmt.Errorf("unknown basis %q, expected one of %v", s, Bases())
+	}
+	return b, nil
+}
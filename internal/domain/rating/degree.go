@@ -0,0 +1,28 @@
+package rating
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Degree is the education level of an admission list, as used in ITMO URLs.
+type Degree string
+
+const (
+	DegreeBachelor     Degree = "bachelor"
+	DegreeMaster       Degree = "master"
+	DegreePostgraduate Degree = "postgraduate"
+)
+
+// Degrees lists all supported degrees.
+func Degrees() []Degree {
+	return []Degree{DegreeBachelor, DegreeMaster, DegreePostgraduate}
+}
+
+func ParseDegree(s string) (Degree, error) {
+	d := Degree(s)
+	if !slices.Contains(Degrees(), d) {
+		return "", fmt.Errorf("unknown degree %q, expected one of %v", s, Degrees())
+	}
+	return d, nil
+}
@@ -0,0 +1,85 @@
+// Package delivery keeps what notify holds back between runs: the changes collected for digests and
+// the messages queued during quiet hours.
+package delivery
+
+import (
+	"fmt"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+// Range is the best and the worst position seen in a list.
+type Range struct {
+	Best  int `json:"best"`
+	Worst int `json:"worst"`
+}
+
+func (r *Range) add(position int) {
+	r.Best = min(r.Best, position)
+	r.Worst = max(r.Worst, position)
+}
+
+// Period collects the summaries of a student between two digests.
+type Period struct {
+	Start time.Time `json:"start"`
+	// Baseline is the summary at the start of the period.
+	Baseline *rating.StudentSummary `json:"baseline"`
+	// Ranges are keyed by "<program>/<basis>".
+	Ranges map[string]Range `json:"ranges"`
+}
+
+func NewPeriod(start time.Time, baseline *rating.StudentSummary) *Period {
+	p := &Period{Start: start, Baseline: baseline, Ranges: make(map[string]Range)}
+	p.Add(baseline)
+	return p
+}
+
+func rangeKey(e rating.StudentSummaryEntry) string {
+	return fmt.Sprintf("%d/%s", e.ProgramID, e.Basis)
+}
+
+// Add widens the position ranges with a summary fetched during the period.
+func (p *Period) Add(summary *rating.StudentSummary) {
+	for _, e := range summary.Entries {
+		r, ok := p.Ranges[rangeKey(e)]
+		if !ok {
+			r = Range{Best: e.Position, Worst: e.Position}
+		}
+		r.add(e.Position)
+		p.Ranges[rangeKey(e)] = r
+	}
+}
+
+// Change is how a list of the student changed over a period.
+type Change struct {
+	Entry rating.StudentSummaryEntry
+	// Baseline is the entry at the start of the period, nil for an application made during it.
+	Baseline *rating.StudentSummaryEntry
+	Range    Range
+}
+
+// NewApplications is the growth of the list over the period.
+func (c Change) NewApplications() int {
+	if c.Baseline == nil {
+		return 0
+	}
+	return c.Entry.TotalApplications - c.Baseline.TotalApplications
+}
+
+// Changes compares the current summary with the start of the period, in the order of the current entries.
+func (p *Period) Changes(current *rating.StudentSummary) []Change {
+	baseline := make(map[string]*rating.StudentSummaryEntry)
+	for i, e := range p.Baseline.Entries {
+		baseline[rangeKey(e)] = &p.Baseline.Entries[i]
+	}
+	changes := make([]Change, 0, len(current.Entries))
+	for _, e := range current.Entries {
+		r, ok := p.Ranges[rangeKey(e)]
+		if !ok {
+			r = Range{Best: e.Position, Worst: e.Position}
+		}
+		changes = append(changes, Change{Entry: e, Baseline: baseline[rangeKey(e)], Range: r})
+	}
+	return changes
+}
@@ -0,0 +1,130 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/pkg/fsutil"
+)
+
+const fileVersion = 1
+
+// Message is a notification held back by the quiet hours of its chat.
+type Message struct {
+	ChatID    int64         `json:"chatId"`
+	Degree    rating.Degree `json:"degree"`
+	StudentID string        `json:"studentId"`
+	Text      string        `json:"text"`
+	QueuedAt  time.Time     `json:"queuedAt"`
+}
+
+func (m Message) about(chatID int64, degree rating.Degree, studentID string) bool {
+	return m.ChatID == chatID && m.Degree == degree && m.StudentID == studentID
+}
+
+type file struct {
+	Version int `json:"version"`
+	// Periods are keyed by "<chat>/<degree>/<student>".
+	Periods map[string]*Period `json:"periods"`
+	Queue   []Message          `json:"queue"`
+}
+
+// Store keeps the digest periods and the queue, Save writes them to the file.
+type Store struct {
+	path    string
+	mu      sync.Mutex
+	periods map[string]*Period
+	queue   []Message
+}
+
+// Open reads the store from path, empty when the file doesn't exist yet.
+// With an empty path everything is kept in memory only.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, periods: make(map[string]*Period)}
+	if path == "" {
+		return s, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delivery state: %w", err)
+	}
+	var f file
+	if err := json.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse delivery state %s: %w", path, err)
+	}
+	if f.Periods != nil {
+		s.periods = f.Periods
+	}
+	s.queue = f.Queue
+	return s, nil
+}
+
+func periodKey(chatID int64, degree rating.Degree, studentID string) string {
+	return fmt.Sprintf("%d/%s/%s", chatID, degree, studentID)
+}
+
+// Period is the current digest period of the student for the chat, nil before the first one.
+func (s *Store) Period(chatID int64, degree rating.Degree, studentID string) *Period {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.periods[periodKey(chatID, degree, studentID)]
+}
+
+func (s *Store) SetPeriod(chatID int64, degree rating.Degree, studentID string, period *Period) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.periods[periodKey(chatID, degree, studentID)] = period
+}
+
+// Enqueue holds the message back, it replaces a queued message about the same student as it's outdated.
+func (s *Store) Enqueue(message Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = slices.DeleteFunc(s.queue, func(m Message) bool {
+		return m.about(message.ChatID, message.Degree, message.StudentID)
+	})
+	s.queue = append(s.queue, message)
+}
+
+// Queued returns a copy of the queue in the order of queueing.
+func (s *Store) Queued() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.queue)
+}
+
+// Dequeue removes the queued message about the student, if any.
+func (s *Store) Dequeue(chatID int64, degree rating.Degree, studentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = slices.DeleteFunc(s.queue, func(m Message) bool {
+		return m.about(chatID, degree, studentID)
+	})
+}
+
+func (s *Store) Save() error {
+	if s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	content, err := json.Marshal(file{Version: fileVersion, Periods: s.periods, Queue: s.queue})
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode delivery state: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(s.path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write delivery state: %w", err)
+	}
+	return nil
+}
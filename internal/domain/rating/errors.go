@@ -0,0 +1,34 @@
+package rating
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDegreeNotTracked is returned for degrees the service was not configured to follow.
+var ErrDegreeNotTracked = errors.New("degree is not tracked")
+
+// ErrProgramNotFound is returned for programs missing from the cached lists.
+var ErrProgramNotFound = errors.New("program not found")
+
+// ErrStudentNotFound is returned for students missing from every cached list.
+var ErrStudentNotFound = errors.New("student not found")
+
+// ErrSchemaChanged is matched by SchemaChangedError via errors.Is.
+var ErrSchemaChanged = errors.New("rating page schema changed")
+
+// SchemaChangedError reports a program page whose __NEXT_DATA__ payload no longer matches the expected schema.
+type SchemaChangedError struct {
+	ProgramID int64
+	Basis     Basis
+	Problems  []string
+}
+
+func (e *SchemaChangedError) Error() string {
+	return fmt.Sprintf("%s for %s list of program %d: %s", ErrSchemaChanged, e.Basis, e.ProgramID, strings.Join(e.Problems, "; "))
+}
+
+func (e *SchemaChangedError) Is(target error) bool {
+	return target == ErrSchemaChanged
+}
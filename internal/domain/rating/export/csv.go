@@ -0,0 +1,35 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// bom makes Excel read the file as UTF-8 instead of the system code page.
+const bom = "\uFEFF"
+
+func writeCSV(w io.Writer, table Table) error {
+	if _, err := io.WriteString(w, bom); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(table.Columns); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	record := make([]string, len(table.Columns))
+	for _, row := range table.Rows {
+		for i, cell := range row {
+			record[i] = formatCell(cell)
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
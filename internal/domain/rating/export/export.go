@@ -0,0 +1,199 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+// Format is a spreadsheet file format.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+func Formats() []Format {
+	return []Format{FormatCSV, FormatXLSX}
+}
+
+func ParseFormat(s string) (Format, error) {
+	f := Format(s)
+	if !slices.Contains(Formats(), f) {
+		return "", fmt.Errorf("unknown format %q, expected one of %v", s, Formats())
+	}
+	return f, nil
+}
+
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Table is a single sheet. Cells are strings, ints, float64s or bools, nil leaves the cell empty.
+type Table struct {
+	Name    string
+	Columns []string
+	Rows    [][]any
+}
+
+// Write encodes the table in the format.
+func Write(w io.Writer, format Format, table Table) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, table)
+	case FormatXLSX:
+		return writeXLSX(w, table)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// entryColumns follow the field order of rating.Entry, new fields are only ever appended.
+var entryColumns = []string{
+	"basis",
+	"target_quota",
+	"contest",
+	"exam_type",
+	"diploma_average",
+	"position",
+	"priority",
+	"ia_scores",
+	"exam_scores",
+	"total_scores",
+	"is_send_agreement",
+	"snils",
+	"case_number",
+	"link",
+	"status",
+	"is_special_b_category",
+	"sspvo_id",
+	"main_top_priority",
+	"highest_passageway_priority",
+	"is_published_in_work_in_russia",
+	"offer_number",
+	"is_detailed_target_quota",
+	"target_achievements",
+	"has_approved_contract",
+}
+
+// Entries builds a table of admission list entries.
+func Entries(name string, entries []rating.Entry) Table {
+	t := Table{
+		Name:    name,
+		Columns: entryColumns,
+		Rows:    make([][]any, 0, len(entries)),
+	}
+	for _, e := range entries {
+		t.Rows = append(t.Rows, []any{
+			string(e.Basis),
+			e.TargetQuota,
+			e.Contest,
+			e.ExamType,
+			e.DiplomaAverage,
+			e.Position,
+			e.Priority,
+			e.IAScores,
+			e.ExamScores,
+			e.TotalScores,
+			e.IsSendAgreement,
+			e.SNILS,
+			e.CaseNumber,
+			e.Link,
+			e.Status,
+			optional(e.IsSpecialBCategory),
+			e.SSPVOID,
+			e.MainTopPriority,
+			e.HighestPassagewayPriority,
+			optional(e.IsPublishedInWorkInRussia),
+			optional(e.OfferNumber),
+			optional(e.IsDetailedTargetQuota),
+			optional(e.TargetAchievements),
+			optional(e.HasApprovedContract),
+		})
+	}
+	return t
+}
+
+// Program builds a table of the whole admission list of basis, the budget one includes the target quota.
+func Program(program *rating.ProgramData, basis rating.Basis) Table {
+	entries := program.List(basis)
+	if basis == rating.BasisBudget {
+		entries = slices.Concat(entries, program.TargetEntries)
+	}
+	return Entries(fmt.Sprintf("%d %s", program.Data.CompetitiveGroupID, basis), entries)
+}
+
+var summaryColumns = []string{
+	"student_id",
+	"priority",
+	"program_id",
+	"program",
+	"basis",
+	"position",
+	"seats",
+	"budget_min",
+	"total_applications",
+	"lower_priority_ahead",
+	"projected_pass",
+	"projected_cutoff",
+	"last_updated",
+}
+
+// Summary builds a table with a row per program of the student summary.
+func Summary(summary *rating.StudentSummary) Table {
+	t := Table{
+		Name:    summary.StudentID,
+		Columns: summaryColumns,
+		Rows:    make([][]any, 0, len(summary.Entries)),
+	}
+	for _, e := range summary.Entries {
+		t.Rows = append(t.Rows, []any{
+			summary.StudentID,
+			e.Priority,
+			e.ProgramID,
+			e.ProgramTitle,
+			string(e.Basis),
+			e.Position,
+			e.Seats,
+			e.BudgetMin,
+			e.TotalApplications,
+			e.LowerPriorityAhead,
+			e.ProjectedPass,
+			e.ProjectedCutoff,
+			e.LastUpdatedFormatted,
+		})
+	}
+	return t
+}
+
+func optional[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// formatCell renders a cell the way it is written to CSV.
+func formatCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
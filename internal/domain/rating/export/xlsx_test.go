@@ -0,0 +1,163 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "A"},
+		{1, "B"},
+		{25, "Z"},
+		{26, "AA"},
+		{27, "AB"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+		{16383, "XFD"}, // the last column of Excel
+	}
+	for _, tt := range tests {
+		if got := columnName(tt.index); got != tt.want {
+			t.Errorf("columnName(%d) = %q, want %q", tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestSheetName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "", want: "Sheet1"},
+		{name: "master-budget-1000", want: "master-budget-1000"},
+		{name: "a[b]c:d*e?f/g\\h", want: "a_b_c_d_e_f_g_h"},
+		{name: "Компьютерные системы и технологии", want: "Компьютерные системы и технолог"},
+		{name: strings.Repeat("x", maxSheetName), want: strings.Repeat("x", maxSheetName)},
+		{name: "R&D <2025>", want: "R&D <2025>"},
+	}
+	for _, tt := range tests {
+		if got := sheetName(tt.name); got != tt.want {
+			t.Errorf("sheetName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWriteRow(t *testing.T) {
+	tests := []struct {
+		name  string
+		n     int
+		cells []any
+		style int
+		want  string
+	}{
+		{name: "empty", n: 1, want: `<row r="1"></row>`},
+		{
+			name:  "header",
+			n:     1,
+			cells: []any{"position", "score"},
+			style: headerStyle,
+			want: `<row r="1">` +
+				`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">position</t></is></c>` +
+				`<c r="B1" s="1" t="inlineStr"><is><t xml:space="preserve">score</t></is></c>` +
+				`</row>`,
+		},
+		{
+			name:  "numbers and booleans",
+			n:     2,
+			cells: []any{1, 97.5, true, false},
+			want: `<row r="2">` +
+				`<c r="A2"><v>1</v></c><c r="B2"><v>97.5</v></c>` +
+				`<c r="C2" t="b"><v>1</v></c><c r="D2" t="b"><v>0</v></c>` +
+				`</row>`,
+		},
+		{
+			// an empty cell keeps the references of the following ones
+			name:  "nil skipped",
+			n:     10,
+			cells: []any{"4000001", nil, 3},
+			want: `<row r="10">` +
+				`<c r="A10" t="inlineStr"><is><t xml:space="preserve">4000001</t></is></c>` +
+				`<c r="C10"><v>3</v></c>` +
+				`</row>`,
+		},
+		{
+			name:  "escaped",
+			n:     3,
+			cells: []any{`R&D <"2025">`},
+			want:  `<row r="3"><c r="A3" t="inlineStr"><is><t xml:space="preserve">R&amp;D &lt;&#34;2025&#34;&gt;</t></is></c></row>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			w := bufio.NewWriter(&b)
+			writeRow(w, tt.n, tt.cells, tt.style)
+			w.Flush()
+			if got := b.String(); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	table := Table{
+		Name:    "master/budget: 1000",
+		Columns: []string{"position", "id", "score"},
+		Rows:    [][]any{{1, "4000008", 104.0}, {2, "R&D <x>", nil}},
+	}
+	var b bytes.Buffer
+	if err := writeXLSX(&b, table); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		content, ok := parts[name]
+		if !ok {
+			t.Errorf("no %s in the package", name)
+			continue
+		}
+		d := xml.NewDecoder(strings.NewReader(content))
+		for {
+			if _, err := d.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("%s is not well formed: %v", name, err)
+				break
+			}
+		}
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `<sheet name="master_budget_ 1000"`) {
+		t.Errorf("got workbook %s", parts["xl/workbook.xml"])
+	}
+	if rows := strings.Count(parts["xl/worksheets/sheet1.xml"], "<row "); rows != 3 {
+		t.Errorf("got %d rows, want the header and 2 rows", rows)
+	}
+}
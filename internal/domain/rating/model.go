@@ -1,9 +1,13 @@
 package rating
 
-import "time"
+import (
+	"time"
+)
 
 // Add this to your rating package
 type Entry struct {
+	Basis                     Basis   `json:"basis"`
+	TargetQuota               bool    `json:"target_quota"`
 	Contest                   string  `json:"contest"`
 	ExamType                  string  `json:"exam_type"`
 	DiplomaAverage            float64 `json:"diploma_average"`
@@ -41,9 +45,22 @@ type ProgramDirection struct {
 }
 
 type ProgramData struct {
-	Data        *ProgramDirection
-	Entries     []Entry
-	LastUpdated time.Time
+	Degree  Degree
+	Data    *ProgramDirection
+	Entries []Entry
+	// ContractEntries is the paid admission list, empty for programs without contract seats.
+	ContractEntries []Entry
+	// TargetEntries is the target quota list of the budget admission.
+	TargetEntries []Entry
+	LastUpdated   time.Time
+}
+
+// List returns the admission list of the program for the basis.
+func (p *ProgramData) List(basis Basis) []Entry {
+	if basis == BasisContract {
+		return p.ContractEntries
+	}
+	return p.Entries
 }
 
 type StudentEntry struct {
@@ -53,16 +70,98 @@ type StudentEntry struct {
 }
 
 type StudentSummaryEntry struct {
-	Priority             int    `json:"priority"`
-	Program              string `json:"program"` // formatted link like "[Title](url)"
-	Position             int    `json:"position"`
-	BudgetMin            int    `json:"budgetMin"`
-	TotalApplications    int    `json:"totalApplications"`
-	LowerPriorityAhead   int    `json:"lowerPriorityAhead"`
-	LastUpdatedFormatted string `json:"lastUpdated"` // RFC822 to match msgRow
+	Priority           int    `json:"priority"`
+	ProgramID          int    `json:"programId"`
+	ProgramTitle       string `json:"programTitle"`
+	ProgramURL         string `json:"programUrl"` // rating page of the list on the site the ratings are loaded from
+	Basis              Basis  `json:"basis"`
+	Position           int    `json:"position"`
+	BudgetMin          int    `json:"budgetMin"`
+	Seats              int    `json:"seats"` // places on the list of Basis, for budget including unfilled quota seats
+	TotalApplications  int    `json:"totalApplications"`
+	LowerPriorityAhead int    `json:"lowerPriorityAhead"`
+	// ProjectedPass and ProjectedCutoff come from the admission simulation of budget lists.
+	ProjectedPass        bool      `json:"projectedPass"`
+	ProjectedCutoff      float64   `json:"projectedCutoff"`
+	LastUpdated          time.Time `json:"lastUpdatedAt"`
+	LastUpdatedFormatted string    `json:"lastUpdated"` // RFC822
 }
 
 type StudentSummary struct {
 	StudentID string                `json:"studentId"`
 	Entries   []StudentSummaryEntry `json:"entries"`
 }
+
+// Neighbourhood is the part of an admission list around a student, see Neighbour for the projection.
+type Neighbourhood struct {
+	StudentID    string      `json:"studentId"`
+	ProgramID    int         `json:"programId"`
+	ProgramTitle string      `json:"programTitle"`
+	Basis        Basis       `json:"basis"`
+	Seats        int         `json:"seats"`
+	Applicants   []Neighbour `json:"applicants"` // in list order, the student included
+}
+
+type Neighbour struct {
+	Position    int     `json:"position"`
+	StudentID   string  `json:"studentId"`
+	TotalScores float64 `json:"totalScores"`
+	ExamScores  float64 `json:"examScores"`
+	Priority    int     `json:"priority"`
+	Agreement   bool    `json:"agreement"`
+	// Self marks the student the neighbourhood was requested for.
+	Self bool `json:"self"`
+	// ProjectedProgramID is the budget program the admission simulation assigns the applicant to, 0 for none.
+	ProjectedProgramID int `json:"projectedProgramId"`
+	// LeavesForHigherPriority is set when that program has a higher priority than this one,
+	// so the applicant isn't expected to take a place here.
+	LeavesForHigherPriority bool `json:"leavesForHigherPriority"`
+}
+
+// Subscription binds a student to the chats that receive their summary.
+type Subscription struct {
+	Degree     Degree
+	StudentID  string
+	Recipients []Recipient
+}
+
+type Recipient struct {
+	ChatID int64
+	Format SummaryFormat
+	// MutedPrograms are left out of the summaries sent to the recipient.
+	MutedPrograms []int
+	// Rules select the summaries worth sending, every summary is sent when there are none.
+	Rules    []Rule
+	Schedule Schedule
+}
+
+// Location is the zone of the recipient's schedule.
+func (r Recipient) Location() *time.Location {
+	if r.Format.Location != nil {
+		return r.Format.Location
+	}
+	return DefaultLocation
+}
+
+// SummaryFormat selects how a summary is rendered for a recipient, zero values mean the defaults of report.
+type SummaryFormat struct {
+	Template string
+	Locale   string
+	Location *time.Location
+}
+
+// SchemaIssue is a program skipped by the last update because its page schema changed.
+type SchemaIssue struct {
+	ProgramID int      `json:"programId"`
+	Basis     Basis    `json:"basis"`
+	Title     string   `json:"title"`
+	Problems  []string `json:"problems"`
+}
+
+// Health describes the state of the rating cache.
+type Health struct {
+	Degree       Degree        `json:"degree"`
+	LastUpdated  time.Time     `json:"lastUpdated"`
+	Programs     int           `json:"programs"`
+	SchemaIssues []SchemaIssue `json:"schemaIssues,omitempty"`
+}
@@ -0,0 +1,156 @@
+// Package preferences keeps the settings subscribers change from the bot, per chat, in a JSON file.
+package preferences
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/pkg/fsutil"
+)
+
+const fileVersion = 1
+
+// Program is a program list of a student.
+type Program struct {
+	Degree    rating.Degree `json:"degree"`
+	StudentID string        `json:"studentId"`
+	ProgramID int           `json:"programId"`
+}
+
+// Chat are the settings of a single chat.
+type Chat struct {
+	// Muted programs are left out of the summaries sent to the chat.
+	Muted []Program `json:"muted,omitempty"`
+	// Rules select the summaries sent to the chat by notify, see rating.Rule.
+	Rules []rating.Rule `json:"rules,omitempty"`
+	// Schedule replaces the one of the configuration for every student of the chat.
+	Schedule *rating.Schedule `json:"schedule,omitempty"`
+}
+
+func (c *Chat) IsMuted(p Program) bool {
+	return slices.Contains(c.Muted, p)
+}
+
+// ToggleMute mutes the program or unmutes a muted one, it reports whether the program is muted now.
+func (c *Chat) ToggleMute(p Program) bool {
+	if i := slices.Index(c.Muted, p); i >= 0 {
+		c.Muted = slices.Delete(c.Muted, i, i+1)
+		return false
+	}
+	c.Muted = append(c.Muted, p)
+	return true
+}
+
+// clone copies the settings so that changing them doesn't touch the store.
+func (c Chat) clone() Chat {
+	c.Muted = slices.Clone(c.Muted)
+	c.Rules = slices.Clone(c.Rules)
+	if c.Schedule != nil {
+		schedule := *c.Schedule
+		if schedule.Digest != nil {
+			at := *schedule.Digest
+			schedule.Digest = &at
+		}
+		if schedule.Quiet != nil {
+			quiet := *schedule.Quiet
+			schedule.Quiet = &quiet
+		}
+		c.Schedule = &schedule
+	}
+	return c
+}
+
+type file struct {
+	Version int            `json:"version"`
+	Chats   map[int64]Chat `json:"chats"`
+}
+
+// Store holds the settings of all chats. Every update is written to the file right away.
+type Store struct {
+	path  string
+	mu    sync.RWMutex
+	chats map[int64]Chat
+}
+
+// Open reads the store from path, empty when the file doesn't exist yet.
+// With an empty path settings are kept in memory only.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, chats: make(map[int64]Chat)}
+	if path == "" {
+		return s, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preferences: %w", err)
+	}
+	var f file
+	if err := json.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse preferences %s: %w", path, err)
+	}
+	if f.Chats != nil {
+		s.chats = f.Chats
+	}
+	return s, nil
+}
+
+// Chat returns a copy of the settings of a chat.
+func (s *Store) Chat(chatID int64) Chat {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.chats[chatID].clone()
+}
+
+// Update changes the settings of a chat and saves the store.
+func (s *Store) Update(chatID int64, update func(*Chat)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.chats[chatID].clone()
+	update(&c)
+	s.chats[chatID] = c
+	return s.save()
+}
+
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	content, err := json.MarshalIndent(file{Version: fileVersion, Chats: s.chats}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode preferences: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(s.path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write preferences: %w", err)
+	}
+	return nil
+}
+
+// Apply sets the muted programs, the rules and the schedule set from the bot on every recipient of the subscriptions.
+func (s *Store) Apply(subscriptions []rating.Subscription) {
+	for i, sub := range subscriptions {
+		for j, recipient := range sub.Recipients {
+			c := s.Chat(recipient.ChatID)
+			var muted []int
+			for _, p := range c.Muted {
+				if p.Degree == sub.Degree && p.StudentID == sub.StudentID {
+					muted = append(muted, p.ProgramID)
+				}
+			}
+			subscriptions[i].Recipients[j].MutedPrograms = muted
+			subscriptions[i].Recipients[j].Rules = c.Rules
+			if c.Schedule != nil {
+				subscriptions[i].Recipients[j].Schedule = *c.Schedule
+			}
+		}
+	}
+}
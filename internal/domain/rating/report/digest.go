@@ -0,0 +1,60 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/delivery"
+	"itmo-ratings/pkg/tgmarkup"
+)
+
+// RenderDigest sums up the changes of the lists since the start of a digest period, a line per list.
+func RenderDigest(studentID string, start time.Time, changes []delivery.Change, format rating.SummaryFormat) (string, error) {
+	if err := Validate(format); err != nil {
+		return "", err
+	}
+	locale := format.Locale
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	messages := locales[locale]
+	formatTime := func(t time.Time) string {
+		if format.Location != nil {
+			t = t.In(format.Location)
+		}
+		return t.Format(messages["timeLayout"])
+	}
+
+	lines := []string{tgmarkup.Bold(fmt.Sprintf(messages["digest"], studentID, formatTime(start)))}
+	var updated time.Time
+	for _, c := range changes {
+		e, base := c.Entry, c.Baseline
+		var parts []string
+		switch {
+		case base == nil:
+			parts = append(parts, fmt.Sprintf(messages["digestNew"], e.Position))
+		case base.Position == e.Position:
+			parts = append(parts, fmt.Sprintf(messages["digestSame"], e.Position))
+		default:
+			parts = append(parts, fmt.Sprintf(messages["rulePosition"], base.Position, e.Position))
+		}
+		if c.Range.Best != c.Range.Worst {
+			parts[0] += " " + fmt.Sprintf(messages["digestRange"], c.Range.Best, c.Range.Worst)
+		}
+		if n := c.NewApplications(); n != 0 {
+			parts = append(parts, fmt.Sprintf(messages["digestApplications"], n))
+		}
+		if base != nil && e.Basis == rating.BasisBudget && base.ProjectedCutoff != e.ProjectedCutoff &&
+			base.ProjectedCutoff != 0 && e.ProjectedCutoff != 0 {
+			parts = append(parts, fmt.Sprintf(messages["ruleCutoff"], base.ProjectedCutoff, e.ProjectedCutoff))
+		}
+		lines = append(lines, fmt.Sprintf("• %s (%s): %s", programLink(&e), messages[string(e.Basis)], strings.Join(parts, ", ")))
+		if e.LastUpdated.After(updated) {
+			updated = e.LastUpdated
+		}
+	}
+	lines = append(lines, "", fmt.Sprintf("%s: %s", messages["updated"], formatTime(updated)))
+	return strings.Join(lines, "\n"), nil
+}
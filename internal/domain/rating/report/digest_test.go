@@ -0,0 +1,91 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/delivery"
+)
+
+func TestRenderDigest(t *testing.T) {
+	start := updated.Add(-24 * time.Hour)
+	moved := budget
+	moved.Position, moved.TotalApplications, moved.ProjectedCutoff = 5, 43, 73
+	same := contract
+	same.TotalApplications = 7
+
+	tests := []struct {
+		name   string
+		change delivery.Change
+		want   string
+	}{
+		{
+			name:   "moved",
+			change: delivery.Change{Entry: moved, Baseline: &budget, Range: delivery.Range{Best: 2, Worst: 6}},
+			want:   budgetLink + " (бюджет): позиция 3 → 5 (от 2 до 6), новых заявлений: +3, прогноз проходного балла 71.4 → 73",
+		},
+		{
+			name:   "unchanged",
+			change: delivery.Change{Entry: same, Baseline: &contract, Range: delivery.Range{Best: 5, Worst: 5}},
+			want:   "Тестовая программа_2 (контракт): позиция 5 без изменений",
+		},
+		{
+			// the position went away and came back during the period
+			name:   "unchanged in the end",
+			change: delivery.Change{Entry: budget, Baseline: &budget, Range: delivery.Range{Best: 1, Worst: 3}},
+			want:   budgetLink + " (бюджет): позиция 3 без изменений (от 1 до 3)",
+		},
+		{
+			name:   "new application",
+			change: delivery.Change{Entry: budget, Range: delivery.Range{Best: 3, Worst: 3}},
+			want:   budgetLink + " (бюджет): новое заявление, позиция 3",
+		},
+		{
+			// a baseline without a forecast has no cutoff to compare with
+			name:   "no cutoff",
+			change: delivery.Change{Entry: moved, Baseline: &rating.StudentSummaryEntry{Position: 5, TotalApplications: 43}, Range: delivery.Range{Best: 5, Worst: 5}},
+			want:   budgetLink + " (бюджет): позиция 5 без изменений",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderDigest("4000008", start, []delivery.Change{tt.change}, rating.SummaryFormat{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := "<b>Изменения у студента 4000008 с 25.07.2025 14:43 UTC</b>\n• " + tt.want +
+				"\n\nПоследнее обновление: " + tt.change.Entry.LastUpdated.Format("02.01.2006 15:04 MST")
+			if got != want {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestRenderDigestFormat(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := []delivery.Change{
+		{Entry: budget, Baseline: &budget, Range: delivery.Range{Best: 3, Worst: 3}},
+		{Entry: contract, Range: delivery.Range{Best: 5, Worst: 5}},
+	}
+	got, err := RenderDigest("4000008", updated.Add(-24*time.Hour), changes, rating.SummaryFormat{Locale: LocaleEN, Location: moscow})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<b>Changes of student 4000008 since Jul 25, 2025 17:43 MSK</b>\n" +
+		"• " + budgetLink + " (budget): position 3 unchanged\n" +
+		"• Тестовая программа_2 (contract): new application, position 5\n" +
+		"\nLast updated: Jul 26, 2025 18:00 MSK"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := RenderDigest("4000008", updated, changes, rating.SummaryFormat{Locale: "de"}); err == nil || !strings.Contains(err.Error(), "de") {
+		t.Errorf("got %v, want an unknown locale error", err)
+	}
+}
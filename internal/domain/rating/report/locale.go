@@ -0,0 +1,84 @@
+package report
+
+const (
+	LocaleRU = "ru"
+	LocaleEN = "en"
+
+	DefaultLocale = LocaleRU
+)
+
+func Locales() []string {
+	return []string{LocaleRU, LocaleEN}
+}
+
+// locales hold the texts of the templates, keys of bases are rating.Basis values.
+var locales = map[string]map[string]string{
+	LocaleRU: {
+		"priority":           "Приоритет",
+		"program":            "Программа",
+		"basis":              "Основа",
+		"position":           "Позиция",
+		"applications":       "всего подано заявлений",
+		"lowerPriorityAhead": "Выше в списке с более низким приоритетом",
+		"forecast":           "Прогноз",
+		"passes":             "проходит",
+		"fails":              "не проходит",
+		"cutoff":             "проходной балл",
+		"updated":            "Последнее обновление",
+		"budget":             "бюджет",
+		"contract":           "контракт",
+		"colPriority":        "№",
+		"colProgram":         "ID",
+		"colBasis":           "Основа",
+		"colPosition":        "Позиция",
+		"colAhead":           "Выше",
+		"timeLayout":         "02.01.2006 15:04 MST",
+		"rulesFired":         "Сработали правила уведомлений:",
+		"ruleNew":            "новое заявление",
+		"rulePosition":       "позиция %d → %d",
+		"ruleChanged":        "изменения в списке",
+		"ruleBudgetIn":       "позиция %d — в пределах %d бюджетных мест",
+		"ruleBudgetOut":      "позиция %d — за пределами %d бюджетных мест",
+		"ruleAhead":          "выше с более низким приоритетом: %d → %d",
+		"ruleCutoff":         "прогноз проходного балла %g → %g",
+		"digest":             "Изменения у студента %s с %s",
+		"digestNew":          "новое заявление, позиция %d",
+		"digestSame":         "позиция %d без изменений",
+		"digestRange":        "(от %d до %d)",
+		"digestApplications": "новых заявлений: %+d",
+	},
+	LocaleEN: {
+		"priority":           "Priority",
+		"program":            "Program",
+		"basis":              "Basis",
+		"position":           "Position",
+		"applications":       "applications in total",
+		"lowerPriorityAhead": "Ahead with a lower priority",
+		"forecast":           "Forecast",
+		"passes":             "passes",
+		"fails":              "doesn't pass",
+		"cutoff":             "cutoff score",
+		"updated":            "Last updated",
+		"budget":             "budget",
+		"contract":           "contract",
+		"colPriority":        "#",
+		"colProgram":         "ID",
+		"colBasis":           "Basis",
+		"colPosition":        "Position",
+		"colAhead":           "Ahead",
+		"timeLayout":         "Jan 2, 2006 15:04 MST",
+		"rulesFired":         "Notification rules fired:",
+		"ruleNew":            "new application",
+		"rulePosition":       "position %d → %d",
+		"ruleChanged":        "the list changed",
+		"ruleBudgetIn":       "position %d is within %d budget seats",
+		"ruleBudgetOut":      "position %d is outside %d budget seats",
+		"ruleAhead":          "ahead with a lower priority: %d → %d",
+		"ruleCutoff":         "projected cutoff %g → %g",
+		"digest":             "Changes of student %s since %s",
+		"digestNew":          "new application, position %d",
+		"digestSame":         "position %d unchanged",
+		"digestRange":        "(from %d to %d)",
+		"digestApplications": "new applications: %+d",
+	},
+}
@@ -0,0 +1,135 @@
+// Package report renders student summaries as Telegram messages in the HTML markup of tgmarkup.
+package report
+
+import (
+	"embed"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/pkg/tgmarkup"
+)
+
+const (
+	TemplateCompact  = "compact"
+	TemplateDetailed = "detailed"
+	TemplateTable    = "table"
+
+	DefaultTemplate = TemplateDetailed
+)
+
+func Templates() []string {
+	return []string{TemplateCompact, TemplateDetailed, TemplateTable}
+}
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// Validate checks the template and locale of a format, empty ones select the defaults.
+func Validate(format rating.SummaryFormat) error {
+	if format.Template != "" && !slices.Contains(Templates(), format.Template) {
+		return fmt.Errorf("unknown template %q, expected one of %v", format.Template, Templates())
+	}
+	if format.Locale != "" && !slices.Contains(Locales(), format.Locale) {
+		return fmt.Errorf("unknown locale %q, expected one of %v", format.Locale, Locales())
+	}
+	return nil
+}
+
+// ParseFormat builds a format from its settings, timezone is an IANA name like Europe/Moscow.
+func ParseFormat(templateName, locale, timezone string) (rating.SummaryFormat, error) {
+	format := rating.SummaryFormat{Template: templateName, Locale: locale}
+	if err := Validate(format); err != nil {
+		return rating.SummaryFormat{}, err
+	}
+	if timezone != "" {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return rating.SummaryFormat{}, fmt.Errorf("unknown timezone %q", timezone)
+		}
+		format.Location = location
+	}
+	return format, nil
+}
+
+type view struct {
+	StudentID string
+	Entries   []entry
+	// Programs are the distinct programs of the entries, in priority order.
+	Programs []entry
+	// Updated is the latest update of the lists.
+	Updated string
+	T       map[string]string
+}
+
+type entry struct {
+	rating.StudentSummaryEntry
+	// Program is the title linked to the rating page.
+	Program    string
+	Budget     bool
+	BasisTitle string
+	Updated    string
+}
+
+// Render formats the summary with the template and locale of format. Times are shown in
+// format.Location, or in the zone the lists were published in when it's nil.
+func Render(summary *rating.StudentSummary, format rating.SummaryFormat) (string, error) {
+	if err := Validate(format); err != nil {
+		return "", err
+	}
+	name := format.Template
+	if name == "" {
+		name = DefaultTemplate
+	}
+	locale := format.Locale
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	messages := locales[locale]
+
+	formatTime := func(t time.Time) string {
+		if format.Location != nil {
+			t = t.In(format.Location)
+		}
+		return t.Format(messages["timeLayout"])
+	}
+
+	v := view{StudentID: summary.StudentID, T: messages}
+	var updated time.Time
+	for _, e := range summary.Entries {
+		item := entry{
+			StudentSummaryEntry: e,
+			Program:             programLink(&e),
+			Budget:              e.Basis == rating.BasisBudget,
+			BasisTitle:          messages[string(e.Basis)],
+			Updated:             formatTime(e.LastUpdated),
+		}
+		v.Entries = append(v.Entries, item)
+		if !slices.ContainsFunc(v.Programs, func(p entry) bool { return p.ProgramID == e.ProgramID }) {
+			v.Programs = append(v.Programs, item)
+		}
+		if e.LastUpdated.After(updated) {
+			updated = e.LastUpdated
+		}
+	}
+	v.Updated = formatTime(updated)
+
+	var b strings.Builder
+	if err := templates.ExecuteTemplate(&b, name+".tmpl", v); err != nil {
+		return "", fmt.Errorf("failed to render %s summary: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// programLink is the program title linked to its rating page, just the title without the page.
+func programLink(e *rating.StudentSummaryEntry) string {
+	if e.ProgramURL == "" {
+		return tgmarkup.Escape(e.ProgramTitle)
+	}
+	return tgmarkup.Link(e.ProgramTitle, e.ProgramURL)
+}
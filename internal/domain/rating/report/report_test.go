@@ -0,0 +1,168 @@
+package report
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+var updated = time.Date(2025, time.July, 26, 14, 43, 0, 0, time.UTC)
+
+// budget is a budget entry linked to its rating page, with a title that needs escaping.
+var budget = rating.StudentSummaryEntry{
+	Priority: 1, ProgramID: 1000, ProgramTitle: "R&D <ИТМО>", ProgramURL: "https://abit.itmo.ru/rating/master/budget/1000",
+	Basis: rating.BasisBudget, Position: 3, Seats: 10, TotalApplications: 40, LowerPriorityAhead: 1,
+	ProjectedPass: true, ProjectedCutoff: 71.4, LastUpdated: updated,
+}
+
+// contract is a contract entry without a rating page.
+var contract = rating.StudentSummaryEntry{
+	Priority: 2, ProgramID: 1001, ProgramTitle: "Тестовая программа_2",
+	Basis: rating.BasisContract, Position: 5, Seats: 20, TotalApplications: 7, LastUpdated: updated.Add(17 * time.Minute),
+}
+
+const budgetLink = `<a href="https://abit.itmo.ru/rating/master/budget/1000">R&amp;D &lt;ИТМО&gt;</a>`
+
+func TestRender(t *testing.T) {
+	summary := &rating.StudentSummary{StudentID: "4000008", Entries: []rating.StudentSummaryEntry{budget, contract}}
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		format rating.SummaryFormat
+		want   string
+	}{
+		{
+			name:   "compact",
+			format: rating.SummaryFormat{Template: TemplateCompact},
+			want: "\n1. " + budgetLink + " (бюджет): 3 / 10, проходит\n" +
+				"2. Тестовая программа_2 (контракт): 5 / 20\n" +
+				"\nПоследнее обновление: 26.07.2025 15:00 UTC\n",
+		},
+		{
+			name:   "compact in English",
+			format: rating.SummaryFormat{Template: TemplateCompact, Locale: LocaleEN},
+			want: "\n1. " + budgetLink + " (budget): 3 / 10, passes\n" +
+				"2. Тестовая программа_2 (contract): 5 / 20\n" +
+				"\nLast updated: Jul 26, 2025 15:00 UTC\n",
+		},
+		{
+			name:   "compact in Moscow time",
+			format: rating.SummaryFormat{Template: TemplateCompact, Location: moscow},
+			want: "\n1. " + budgetLink + " (бюджет): 3 / 10, проходит\n" +
+				"2. Тестовая программа_2 (контракт): 5 / 20\n" +
+				"\nПоследнее обновление: 26.07.2025 18:00 MSK\n",
+		},
+		{
+			// only budget lists have a forecast
+			name: "detailed by default",
+			want: "\nПриоритет: 1\nПрограмма: " + budgetLink + "\nОснова: бюджет\n" +
+				"Позиция: 3 / 10 (всего подано заявлений: 40)\nВыше в списке с более низким приоритетом: 1\n" +
+				"Прогноз: проходит, проходной балл 71\nПоследнее обновление: 26.07.2025 14:43 UTC\n" +
+				"\nПриоритет: 2\nПрограмма: Тестовая программа_2\nОснова: контракт\n" +
+				"Позиция: 5 / 20 (всего подано заявлений: 7)\nВыше в списке с более низким приоритетом: 0\n" +
+				"Последнее обновление: 26.07.2025 15:00 UTC\n",
+		},
+		{
+			name:   "table",
+			format: rating.SummaryFormat{Template: TemplateTable, Locale: LocaleEN},
+			want: "<pre>\n#   ID     Basis     Position  Ahead\n" +
+				"1   1000   budget    3/10      1\n" +
+				"2   1001   contract  5/20      0\n</pre>\n" +
+				"1000 — " + budgetLink + "\n1001 — Тестовая программа_2\n" +
+				"\nLast updated: Jul 26, 2025 15:00 UTC\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(summary, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTablePrograms(t *testing.T) {
+	// the budget and contract lists of a program share the title line
+	sameProgram := contract
+	sameProgram.ProgramID, sameProgram.ProgramTitle, sameProgram.ProgramURL = budget.ProgramID, budget.ProgramTitle, budget.ProgramURL
+	summary := &rating.StudentSummary{Entries: []rating.StudentSummaryEntry{budget, sameProgram}}
+	got, err := Render(summary, rating.SummaryFormat{Template: TemplateTable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(got, "1000 — "); n != 1 {
+		t.Errorf("got %d title lines of program 1000 in\n%s", n, got)
+	}
+}
+
+func TestRenderInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		format rating.SummaryFormat
+		want   string
+	}{
+		{name: "template", format: rating.SummaryFormat{Template: "fancy"}, want: `unknown template "fancy"`},
+		{name: "locale", format: rating.SummaryFormat{Locale: "de"}, want: `unknown locale "de"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Render(&rating.StudentSummary{}, tt.format)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error with %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name                       string
+		template, locale, timezone string
+		wantErr                    bool
+		location                   string
+	}{
+		{name: "defaults"},
+		{name: "all set", template: TemplateTable, locale: LocaleEN, timezone: "Asia/Yekaterinburg", location: "Asia/Yekaterinburg"},
+		{name: "unknown template", template: "fancy", wantErr: true},
+		{name: "unknown locale", locale: "de", wantErr: true},
+		{name: "unknown timezone", timezone: "Europe/Atlantis", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := ParseFormat(tt.template, tt.locale, tt.timezone)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if format.Template != tt.template || format.Locale != tt.locale {
+				t.Errorf("got %+v", format)
+			}
+			if (format.Location == nil && tt.location != "") || (format.Location != nil && format.Location.String() != tt.location) {
+				t.Errorf("got location %v, want %q", format.Location, tt.location)
+			}
+		})
+	}
+}
+
+func TestLocales(t *testing.T) {
+	keys := slices.Sorted(maps.Keys(locales[DefaultLocale]))
+	for _, locale := range Locales() {
+		if got := slices.Sorted(maps.Keys(locales[locale])); !slices.Equal(got, keys) {
+			t.Errorf("locale %s has keys %v, want %v", locale, got, keys)
+		}
+	}
+}
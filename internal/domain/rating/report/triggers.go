@@ -0,0 +1,46 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/rules"
+	"itmo-ratings/pkg/tgmarkup"
+)
+
+// RenderTriggers lists why a summary was sent, a line per fired rule, in the locale of format.
+func RenderTriggers(triggers []rules.Trigger, format rating.SummaryFormat) (string, error) {
+	if err := Validate(format); err != nil {
+		return "", err
+	}
+	locale := format.Locale
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	messages := locales[locale]
+
+	lines := []string{tgmarkup.Bold(messages["rulesFired"])}
+	for _, t := range triggers {
+		e, prev := t.Entry, t.Previous
+		var reason string
+		switch {
+		case prev == nil:
+			reason = messages["ruleNew"]
+		case t.Rule.Kind == rating.RuleBudget && rules.InSeats(&e):
+			reason = fmt.Sprintf(messages["ruleBudgetIn"], e.Position, e.Seats)
+		case t.Rule.Kind == rating.RuleBudget:
+			reason = fmt.Sprintf(messages["ruleBudgetOut"], e.Position, e.Seats)
+		case t.Rule.Kind == rating.RuleAhead:
+			reason = fmt.Sprintf(messages["ruleAhead"], prev.LowerPriorityAhead, e.LowerPriorityAhead)
+		case t.Rule.Kind == rating.RuleCutoff:
+			reason = fmt.Sprintf(messages["ruleCutoff"], prev.ProjectedCutoff, e.ProjectedCutoff)
+		case prev.Position != e.Position:
+			reason = fmt.Sprintf(messages["rulePosition"], prev.Position, e.Position)
+		default:
+			reason = messages["ruleChanged"]
+		}
+		lines = append(lines, fmt.Sprintf("• %s (%s): %s", programLink(&e), messages[string(e.Basis)], reason))
+	}
+	return strings.Join(lines, "\n"), nil
+}
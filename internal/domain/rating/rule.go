@@ -0,0 +1,66 @@
+package rating
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// RuleKind is the change of a summary entry a notification rule reacts to.
+type RuleKind string
+
+const (
+	// RuleChange fires on any change of the position, seats, count ahead or forecast, and on new applications.
+	RuleChange RuleKind = "change"
+	// RuleBudget fires when the position crosses the number of budget seats, either way.
+	RuleBudget RuleKind = "budget"
+	// RuleAhead fires when the count of lower priority applicants ahead drops below the threshold.
+	RuleAhead RuleKind = "ahead"
+	// RuleCutoff fires when the projected cutoff moves by the threshold or more points.
+	RuleCutoff RuleKind = "cutoff"
+)
+
+// RuleKinds lists all supported rule kinds.
+func RuleKinds() []RuleKind {
+	return []RuleKind{RuleChange, RuleBudget, RuleAhead, RuleCutoff}
+}
+
+func ParseRuleKind(s string) (RuleKind, error) {
+	k := RuleKind(s)
+	if !slices.Contains(RuleKinds(), k) {
+		return "", fmt.Errorf("unknown rule %q, expected one of %v", s, RuleKinds())
+	}
+	return k, nil
+}
+
+// Rule decides whether a summary is worth sending, it is checked against the previous summary.
+type Rule struct {
+	Kind RuleKind `json:"kind"`
+	// Threshold is the count of RuleAhead and the points of RuleCutoff.
+	Threshold float64 `json:"threshold,omitempty"`
+	// ProgramID limits the rule to a program, 0 for all of them.
+	ProgramID int `json:"programId,omitempty"`
+	// MaxPriority limits the rule to the programs of priority 1 to MaxPriority, 0 for all of them.
+	MaxPriority int `json:"maxPriority,omitempty"`
+}
+
+func (r Rule) Validate() error {
+	if _, err := ParseRuleKind(string(r.Kind)); err != nil {
+		return err
+	}
+	if (r.Kind == RuleAhead || r.Kind == RuleCutoff) && (!(r.Threshold > 0) || math.IsInf(r.Threshold, 1)) {
+		return fmt.Errorf("rule %s needs a positive threshold", r.Kind)
+	}
+	if r.ProgramID < 0 || r.MaxPriority < 0 {
+		return fmt.Errorf("program and priority of a rule can't be negative")
+	}
+	return nil
+}
+
+// Applies reports whether the entry is in the scope of the rule.
+func (r Rule) Applies(e StudentSummaryEntry) bool {
+	if r.ProgramID != 0 && e.ProgramID != r.ProgramID {
+		return false
+	}
+	return r.MaxPriority == 0 || e.Priority <= r.MaxPriority
+}
@@ -0,0 +1,95 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/pkg/fsutil"
+)
+
+const historyVersion = 2
+
+type historyFile struct {
+	Version int `json:"version"`
+	// Summaries are keyed by "<chat>/<degree>/<student>". Version 1 keyed them by "<degree>/<student>",
+	// such entries are still read for chats without one of their own.
+	Summaries map[string]*rating.StudentSummary `json:"summaries"`
+}
+
+// History keeps the last summary every chat got about a student, its rules compare the next one against it.
+type History struct {
+	path      string
+	mu        sync.Mutex
+	summaries map[string]*rating.StudentSummary
+}
+
+// OpenHistory reads the history from path, empty when the file doesn't exist yet.
+// With an empty path the history is kept in memory only.
+func OpenHistory(path string) (*History, error) {
+	h := &History{path: path, summaries: make(map[string]*rating.StudentSummary)}
+	if path == "" {
+		return h, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read summary history: %w", err)
+	}
+	var f historyFile
+	if err := json.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse summary history %s: %w", path, err)
+	}
+	if f.Summaries != nil {
+		h.summaries = f.Summaries
+	}
+	return h, nil
+}
+
+func historyKey(chatID int64, degree rating.Degree, studentID string) string {
+	return fmt.Sprintf("%d/%s/%s", chatID, degree, studentID)
+}
+
+func legacyHistoryKey(degree rating.Degree, studentID string) string {
+	return string(degree) + "/" + studentID
+}
+
+// Previous is the last summary of the student recorded for the chat, nil when there is none.
+func (h *History) Previous(chatID int64, degree rating.Degree, studentID string) *rating.StudentSummary {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if summary, ok := h.summaries[historyKey(chatID, degree, studentID)]; ok {
+		return summary
+	}
+	return h.summaries[legacyHistoryKey(degree, studentID)]
+}
+
+// Record replaces the summary of the student for the chat, Save writes the records to the file.
+func (h *History) Record(chatID int64, degree rating.Degree, studentID string, summary *rating.StudentSummary) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.summaries[historyKey(chatID, degree, studentID)] = summary
+}
+
+func (h *History) Save() error {
+	if h.path == "" {
+		return nil
+	}
+	h.mu.Lock()
+	content, err := json.Marshal(historyFile{Version: historyVersion, Summaries: h.summaries})
+	h.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode summary history: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(h.path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write summary history: %w", err)
+	}
+	return nil
+}
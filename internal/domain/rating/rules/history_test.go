@@ -0,0 +1,46 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summaries.json")
+	// a version 1 file keyed by student only
+	legacy := `{"version":1,"summaries":{"master/100":{"studentId":"100"}}}`
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	h, err := OpenHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h.Previous(1, rating.DegreeMaster, "100") == nil || h.Previous(2, rating.DegreeMaster, "100") == nil {
+		t.Error("chats without a summary of their own don't fall back to the version 1 one")
+	}
+	if h.Previous(1, rating.DegreeBachelor, "100") != nil {
+		t.Error("summary of another degree returned")
+	}
+
+	recorded := &rating.StudentSummary{StudentID: "100", Entries: []rating.StudentSummaryEntry{{ProgramID: 1000}}}
+	h.Record(1, rating.DegreeMaster, "100", recorded)
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+	h, err = OpenHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Previous(1, rating.DegreeMaster, "100"); got == nil || len(got.Entries) != 1 {
+		t.Errorf("got %+v for chat 1, want the recorded summary", got)
+	}
+	// chat 2 keeps its own baseline
+	if got := h.Previous(2, rating.DegreeMaster, "100"); got == nil || len(got.Entries) != 0 {
+		t.Errorf("got %+v for chat 2, want the version 1 summary", got)
+	}
+}
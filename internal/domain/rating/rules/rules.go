@@ -0,0 +1,83 @@
+// Package rules checks the notification rules of subscribers against the change of a student summary
+// since the previous notification, and keeps those previous summaries.
+package rules
+
+import (
+	"math"
+	"slices"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+// Trigger is a rule fired by an entry of the summary.
+type Trigger struct {
+	Rule  rating.Rule
+	Entry rating.StudentSummaryEntry
+	// Previous is the entry in the previous summary, nil for a new application.
+	Previous *rating.StudentSummaryEntry
+}
+
+type entryKey struct {
+	programID int
+	basis     rating.Basis
+}
+
+// Evaluate returns the rules fired by the change from the previous summary to the current one, in the
+// order of the current entries. A kind fires once per entry even when several rules of it apply.
+func Evaluate(rules []rating.Rule, previous, current *rating.StudentSummary) []Trigger {
+	before := make(map[entryKey]*rating.StudentSummaryEntry)
+	if previous != nil {
+		for i, e := range previous.Entries {
+			before[entryKey{e.ProgramID, e.Basis}] = &previous.Entries[i]
+		}
+	}
+
+	var triggers []Trigger
+	for _, e := range current.Entries {
+		prev := before[entryKey{e.ProgramID, e.Basis}]
+		var fired []rating.RuleKind
+		for _, rule := range rules {
+			if slices.Contains(fired, rule.Kind) || !rule.Applies(e) || !fires(rule, prev, &e) {
+				continue
+			}
+			fired = append(fired, rule.Kind)
+			triggers = append(triggers, Trigger{Rule: rule, Entry: e, Previous: prev})
+		}
+	}
+	return triggers
+}
+
+func fires(rule rating.Rule, prev, e *rating.StudentSummaryEntry) bool {
+	if rule.Kind == rating.RuleChange {
+		return prev == nil || changed(prev, e)
+	}
+	if prev == nil {
+		return false
+	}
+	switch rule.Kind {
+	case rating.RuleBudget:
+		return e.Basis == rating.BasisBudget && InSeats(prev) != InSeats(e)
+	case rating.RuleAhead:
+		return float64(prev.LowerPriorityAhead) >= rule.Threshold && float64(e.LowerPriorityAhead) < rule.Threshold
+	case rating.RuleCutoff:
+		// a zero cutoff means there was no forecast
+		return e.Basis == rating.BasisBudget && prev.ProjectedCutoff != 0 && e.ProjectedCutoff != 0 &&
+			math.Abs(e.ProjectedCutoff-prev.ProjectedCutoff) >= rule.Threshold
+	}
+	return false
+}
+
+// changed ignores the application count, it grows with almost every update of the list.
+func changed(prev, e *rating.StudentSummaryEntry) bool {
+	return prev.Priority != e.Priority ||
+		prev.Position != e.Position ||
+		prev.Seats != e.Seats ||
+		prev.LowerPriorityAhead != e.LowerPriorityAhead ||
+		prev.ProjectedPass != e.ProjectedPass ||
+		prev.ProjectedCutoff != e.ProjectedCutoff
+}
+
+// InSeats reports whether the position is within the seats of the list.
+func InSeats(e *rating.StudentSummaryEntry) bool {
+	return e.Position > 0 && e.Position <= e.Seats
+}
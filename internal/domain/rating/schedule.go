@@ -0,0 +1,126 @@
+package rating
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultLocation is the zone of schedules of recipients without a timezone, the one ITMO publishes lists in.
+var DefaultLocation = loadLocation("Europe/Moscow", 3*60*60)
+
+func loadLocation(name string, offset int) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.FixedZone("MSK", offset)
+	}
+	return location
+}
+
+// Clock is a time of day as minutes since midnight, written as "HH:MM".
+type Clock int
+
+func ParseClock(s string) (Clock, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", s)
+	}
+	return Clock(t.Hour()*60 + t.Minute()), nil
+}
+
+// ClockOf is the time of day of t in its location.
+func ClockOf(t time.Time) Clock {
+	return Clock(t.Hour()*60 + t.Minute())
+}
+
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", c/60, c%60)
+}
+
+func (c Clock) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Clock) UnmarshalText(text []byte) error {
+	parsed, err := ParseClock(string(text))
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// ClockRange is a part of the day written as "HH:MM-HH:MM", it wraps midnight when From is after To.
+type ClockRange struct {
+	From Clock
+	To   Clock
+}
+
+func ParseClockRange(s string) (ClockRange, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return ClockRange{}, fmt.Errorf("invalid hours %q, expected HH:MM-HH:MM", s)
+	}
+	var r ClockRange
+	var err error
+	if r.From, err = ParseClock(from); err != nil {
+		return ClockRange{}, err
+	}
+	if r.To, err = ParseClock(to); err != nil {
+		return ClockRange{}, err
+	}
+	if r.From == r.To {
+		return ClockRange{}, fmt.Errorf("empty hours %q", s)
+	}
+	return r, nil
+}
+
+// Contains reports whether the time of day is in the range, From included and To excluded.
+func (r ClockRange) Contains(c Clock) bool {
+	if r.From < r.To {
+		return c >= r.From && c < r.To
+	}
+	return c >= r.From || c < r.To
+}
+
+func (r ClockRange) String() string {
+	return r.From.String() + "-" + r.To.String()
+}
+
+func (r ClockRange) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *ClockRange) UnmarshalText(text []byte) error {
+	parsed, err := ParseClockRange(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Schedule decides when notifications reach a recipient, in the local time of the recipient.
+type Schedule struct {
+	// Digest collects the changes into a message a day at this time, summaries are sent right away when nil.
+	Digest *Clock `json:"digest,omitempty"`
+	// Quiet hours hold messages back until they end.
+	Quiet *ClockRange `json:"quiet,omitempty"`
+}
+
+// NextDigest is the first digest time after t, in the location of t.
+func (s Schedule) NextDigest(t time.Time) time.Time {
+	if s.Digest == nil {
+		return time.Time{}
+	}
+	next := time.Date(t.Year(), t.Month(), t.Day(), int(*s.Digest/60), int(*s.Digest%60), 0, 0, t.Location())
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// IsQuiet reports whether t, in the local time of the recipient, falls into the quiet hours.
+func (s Schedule) IsQuiet(t time.Time) bool {
+	return s.Quiet != nil && s.Quiet.Contains(ClockOf(t))
+}
@@ -0,0 +1,161 @@
+package rating
+
+import (
+	"testing"
+	"time"
+)
+
+func clock(t *testing.T, s string) Clock {
+	t.Helper()
+	c, err := ParseClock(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestParseClockRange(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    ClockRange
+		wantErr bool
+	}{
+		{s: "22:00-07:00", want: ClockRange{From: 22 * 60, To: 7 * 60}},
+		{s: "13:00-14:30", want: ClockRange{From: 13 * 60, To: 14*60 + 30}},
+		{s: "00:00-23:59", want: ClockRange{From: 0, To: 23*60 + 59}},
+		{s: "22:00", wantErr: true},
+		{s: "22:00-24:00", wantErr: true},
+		{s: "09:00-09:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseClockRange(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if err == nil && got.String() != tt.s {
+				t.Errorf("formatted back as %q", got.String())
+			}
+		})
+	}
+}
+
+func TestClockRangeContains(t *testing.T) {
+	tests := []struct {
+		r     string
+		clock string
+		want  bool
+	}{
+		{r: "13:00-14:00", clock: "12:59", want: false},
+		{r: "13:00-14:00", clock: "13:00", want: true},
+		{r: "13:00-14:00", clock: "13:59", want: true},
+		{r: "13:00-14:00", clock: "14:00", want: false},
+
+		// across midnight
+		{r: "22:00-07:00", clock: "21:59", want: false},
+		{r: "22:00-07:00", clock: "22:00", want: true},
+		{r: "22:00-07:00", clock: "23:59", want: true},
+		{r: "22:00-07:00", clock: "00:00", want: true},
+		{r: "22:00-07:00", clock: "06:59", want: true},
+		{r: "22:00-07:00", clock: "07:00", want: false},
+		{r: "22:00-07:00", clock: "12:00", want: false},
+
+		// ending or starting at midnight
+		{r: "07:00-00:00", clock: "23:59", want: true},
+		{r: "07:00-00:00", clock: "00:00", want: false},
+		{r: "07:00-00:00", clock: "06:59", want: false},
+		{r: "00:00-07:00", clock: "00:00", want: true},
+		{r: "00:00-07:00", clock: "23:59", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.r+" "+tt.clock, func(t *testing.T) {
+			r, err := ParseClockRange(tt.r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.Contains(clock(t, tt.clock)); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsQuiet(t *testing.T) {
+	quiet, err := ParseClockRange("22:00-07:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := Schedule{Quiet: &quiet}
+	// 20:30 UTC is 23:30 in Moscow
+	at := time.Date(2025, time.July, 26, 20, 30, 0, 0, time.UTC)
+	if s.IsQuiet(at) {
+		t.Error("20:30 UTC is quiet")
+	}
+	if !s.IsQuiet(at.In(DefaultLocation)) {
+		t.Error("23:30 in Moscow isn't quiet")
+	}
+	if (Schedule{}).IsQuiet(at.In(DefaultLocation)) {
+		t.Error("a schedule without quiet hours is quiet")
+	}
+}
+
+func TestNextDigest(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2025, month, day, hour, min, 0, 0, berlin)
+	}
+	utc := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2025, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		digest string
+		t      time.Time
+		want   time.Time
+	}{
+		{name: "later today", digest: "21:00", t: date(time.July, 26, 10, 0), want: date(time.July, 26, 21, 0)},
+		{name: "tomorrow", digest: "09:00", t: date(time.July, 26, 10, 0), want: date(time.July, 27, 9, 0)},
+		{name: "right at the digest", digest: "09:00", t: date(time.July, 26, 9, 0), want: date(time.July, 27, 9, 0)},
+		{name: "new year", digest: "09:00", t: date(time.December, 31, 10, 0), want: time.Date(2026, time.January, 1, 9, 0, 0, 0, berlin)},
+
+		// the clocks go forward on March 30 at 02:00, the night is an hour shorter
+		{name: "over spring forward", digest: "09:00", t: date(time.March, 29, 22, 0), want: utc(time.March, 30, 7, 0)},
+		// 02:30 doesn't exist that day, the digest comes at 03:30 summer time
+		{name: "in the spring gap", digest: "02:30", t: date(time.March, 30, 1, 0), want: utc(time.March, 30, 1, 30)},
+		{name: "to the spring gap", digest: "02:30", t: date(time.March, 29, 3, 0), want: utc(time.March, 30, 1, 30)},
+
+		// the clocks go back on October 26 at 03:00, the night is an hour longer
+		{name: "over fall back", digest: "09:00", t: date(time.October, 25, 22, 0), want: utc(time.October, 26, 8, 0)},
+		// 02:30 happens twice, the digest comes once, at the second one
+		{name: "before the repeated hour", digest: "02:30", t: utc(time.October, 25, 23, 0).In(berlin), want: utc(time.October, 26, 1, 30)},
+		{name: "in the first repeated hour", digest: "02:30", t: utc(time.October, 26, 0, 30).In(berlin), want: utc(time.October, 26, 1, 30)},
+		{name: "after the second 02:30", digest: "02:30", t: utc(time.October, 26, 1, 45).In(berlin), want: utc(time.October, 27, 1, 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			digest := clock(t, tt.digest)
+			got := Schedule{Digest: &digest}.NextDigest(tt.t)
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want.In(berlin))
+			}
+			if got.Location() != tt.t.Location() {
+				t.Errorf("got location %v, want %v", got.Location(), tt.t.Location())
+			}
+			// the digest after a digest is the next day's
+			if next := (Schedule{Digest: &digest}).NextDigest(got); next.Sub(got) < 23*time.Hour || next.Sub(got) > 25*time.Hour {
+				t.Errorf("the digest after %v comes at %v", got, next)
+			}
+		})
+	}
+
+	if next := (Schedule{}).NextDigest(date(time.July, 26, 10, 0)); !next.IsZero() {
+		t.Errorf("got %v without a digest, want the zero time", next)
+	}
+}
@@ -0,0 +1,83 @@
+package scrapper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const nextDataID = "__NEXT_DATA__"
+
+var (
+	ErrNextDataNotFound = errors.New("__NEXT_DATA__ script tag not found")
+	ErrPageTooLarge     = errors.New("page exceeds size limit")
+)
+
+// extractNextData streams the page through an HTML tokenizer and returns the content of
+// <script id="__NEXT_DATA__">. Attribute order, quoting, whitespace and extra attributes
+// don't matter. At most limit bytes are read from r.
+func extractNextData(r io.Reader, limit int64) ([]byte, error) {
+	lr := &io.LimitedReader{R: r, N: limit + 1}
+	z := html.NewTokenizer(lr)
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if lr.N <= 0 {
+				return nil, fmt.Errorf("%w: more than %d bytes read", ErrPageTooLarge, limit)
+			}
+			if errors.Is(z.Err(), io.EOF) {
+				return nil, ErrNextDataNotFound
+			}
+			return nil, fmt.Errorf("failed to tokenize page: %w", z.Err())
+		case html.StartTagToken:
+			name, hasAttr := z.TagName()
+			if atom.Lookup(name) != atom.Script || !hasAttr || !hasNextDataID(z) {
+				continue
+			}
+
+			// script is a raw text element, so its whole content arrives as a single text token
+			switch z.Next() {
+			case html.TextToken:
+			case html.ErrorToken:
+				return nil, unterminated(z, lr, limit)
+			default:
+				return nil, fmt.Errorf("__NEXT_DATA__ script is empty")
+			}
+
+			payload := bytes.TrimSpace(bytes.Clone(z.Text()))
+			if len(payload) == 0 {
+				return nil, fmt.Errorf("__NEXT_DATA__ script is empty")
+			}
+			// the text also ends at EOF, only the end tag tells the payload isn't cut by the limit
+			if z.Next() != html.EndTagToken {
+				return nil, unterminated(z, lr, limit)
+			}
+			return payload, nil
+		}
+	}
+}
+
+func unterminated(z *html.Tokenizer, lr *io.LimitedReader, limit int64) error {
+	if lr.N <= 0 {
+		return fmt.Errorf("%w: more than %d bytes read", ErrPageTooLarge, limit)
+	}
+	return fmt.Errorf("__NEXT_DATA__ script is not terminated: %w", z.Err())
+}
+
+func hasNextDataID(z *html.Tokenizer) bool {
+	for {
+		key, val, more := z.TagAttr()
+		if string(key) == "id" && strings.TrimSpace(string(val)) == nextDataID {
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package scrapper
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+// pages are synthetic: hand-made pages imitating the markup of the ITMO rating pages (Next.js
+// chunks, inline scripts and the __NEXT_DATA__ payload the scrapper decodes), not saved copies
+// of the site, so markup the site has and they don't isn't covered. The expected fields come
+// from the payload.
+var pages = []struct {
+	file      string
+	programID int64
+	basis     rating.Basis
+	title     string
+	entries   int
+}{
+	{file: "budget.html", programID: 1000, basis: rating.BasisBudget, title: "09.04.01 «Компьютерные системы и технологии»", entries: 8},
+	{file: "contract.html", programID: 1001, basis: rating.BasisContract, title: "09.04.02 «Тестовая программа_2»", entries: 2},
+}
+
+func readPage(tb testing.TB, name string) []byte {
+	tb.Helper()
+	page, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return page
+}
+
+func TestExtractNextData(t *testing.T) {
+	for _, tt := range pages {
+		t.Run(tt.file, func(t *testing.T) {
+			page := readPage(t, tt.file)
+			payload, err := extractNextData(bytes.NewReader(page), DefaultMaxPageSize)
+			if err != nil {
+				t.Fatalf("extractNextData: %v", err)
+			}
+			data, err := decodeNextData(tt.programID, tt.basis, payload)
+			if err != nil {
+				t.Fatalf("decodeNextData: %v", err)
+			}
+			list := data.Props.PageProps.ProgramList
+			if list.Direction.DirectionTitle != tt.title {
+				t.Errorf("got title %q, want %q", list.Direction.DirectionTitle, tt.title)
+			}
+			if int64(list.Direction.CompetitiveGroupID) != tt.programID {
+				t.Errorf("got program %d, want %d", list.Direction.CompetitiveGroupID, tt.programID)
+			}
+			if len(list.GeneralCompetition) != tt.entries {
+				t.Errorf("got %d entries, want %d", len(list.GeneralCompetition), tt.entries)
+			}
+		})
+	}
+}
+
+func TestExtractNextDataErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want error
+	}{
+		{name: "no script", page: `<html><body><div id="__next"></div></body></html>`, want: ErrNextDataNotFound},
+		{name: "other id", page: `<script id="__NEXT_DATA_OLD__">{}</script>`, want: ErrNextDataNotFound},
+		{name: "commented out", page: `<!-- <script id="__NEXT_DATA__">{}</script> -->`, want: ErrNextDataNotFound},
+		{name: "id on another tag", page: `<div id="__NEXT_DATA__">{}</div>`, want: ErrNextDataNotFound},
+		{name: "empty", page: `<script id="__NEXT_DATA__">  </script>`},
+		{name: "self closed", page: `<script id="__NEXT_DATA__"/></body>`},
+		{name: "not terminated", page: `<script id="__NEXT_DATA__">{"props":{`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := extractNextData(strings.NewReader(tt.page), DefaultMaxPageSize)
+			if err == nil {
+				t.Fatalf("got payload %q, want an error", payload)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractNextDataLimit(t *testing.T) {
+	page := readPage(t, "budget.html")
+	script := int64(bytes.Index(page, []byte(nextDataID)))
+	end := int64(bytes.LastIndex(page, []byte("</script>")))
+
+	tests := []struct {
+		name  string
+		limit int64
+		want  error
+	}{
+		{name: "whole page", limit: int64(len(page))},
+		{name: "before script", limit: script - 20, want: ErrPageTooLarge},
+		{name: "inside script", limit: (script + end) / 2, want: ErrPageTooLarge},
+		{name: "before script end", limit: end + 3, want: ErrPageTooLarge},
+		// the rest of the page isn't needed once the script is read
+		{name: "after script end", limit: end + int64(len("</script>"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := extractNextData(bytes.NewReader(page), tt.limit)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("extractNextData: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got payload of %d bytes and error %v, want %v", len(payload), err, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzExtractNextData(f *testing.F) {
+	for _, tt := range pages {
+		page := readPage(f, tt.file)
+		f.Add(page, int64(len(page)))
+		f.Add(page, int64(len(page)/2))
+		f.Add(page[:len(page)*3/4], int64(len(page)))
+	}
+	f.Add([]byte(`<script id="__NEXT_DATA__">{}`), int64(64))
+	f.Add([]byte(`<script id=__NEXT_DATA__ type=application/json>{}</script>`), int64(1))
+
+	f.Fuzz(func(t *testing.T, page []byte, limit int64) {
+		if limit < 0 || limit > 1<<20 {
+			t.Skip()
+		}
+		payload, err := extractNextData(bytes.NewReader(page), limit)
+		if int64(len(page)) <= limit && errors.Is(err, ErrPageTooLarge) {
+			t.Fatalf("page of %d bytes rejected with limit %d", len(page), limit)
+		}
+		if err != nil {
+			return
+		}
+		if len(bytes.TrimSpace(payload)) == 0 {
+			t.Fatal("empty payload without an error")
+		}
+		// the payload is raw text of the page, so it must be within the bytes allowed to be read
+		if !bytes.Contains(tokenizerText(page[:min(int64(len(page)), limit)]), payload) {
+			t.Fatalf("payload %q is not within the first %d bytes of the page", payload, limit)
+		}
+	})
+}
+
+// tokenizerText is text as the HTML tokenizer returns it: with NUL replaced and line ends normalized.
+func tokenizerText(b []byte) []byte {
+	b = bytes.ReplaceAll(b, []byte("\x00"), []byte("\uFFFD"))
+	b = bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(b, []byte("\r"), []byte("\n"))
+}
@@ -0,0 +1,207 @@
+package scrapper
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+var (
+	schemaErrors  = expvar.NewMap("scrapper_schema_errors")
+	unknownFields = expvar.NewMap("scrapper_unknown_fields")
+
+	// reportedFields keeps unknown fields already logged so every refresh doesn't repeat them
+	reportedFields sync.Map
+)
+
+var (
+	requiredProgramListFields = map[rating.Basis][]string{
+		rating.BasisBudget:   {"general_competition", "by_target_quota", "direction", "update_time"},
+		rating.BasisContract: {"general_competition", "direction", "update_time"},
+	}
+	// lists the scrapper decodes, they never count as unknown
+	knownLists              = []string{"general_competition", "by_target_quota"}
+	requiredDirectionFields = []string{"direction_title", "budget_min", "competitive_group_id"}
+	requiredEntryFields     = []string{"position", "priority", "sspvo_id", "total_scores"}
+
+	// fields ITMO sends that are intentionally not decoded
+	ignoredEntryFields = []string{"target_organization_number"}
+)
+
+// rawNextData mirrors RatingsNextJSData down to programList keeping every key it finds.
+type rawNextData struct {
+	Props *struct {
+		PageProps *struct {
+			ProgramList map[string]json.RawMessage `json:"programList"`
+		} `json:"pageProps"`
+	} `json:"props"`
+}
+
+// decodeNextData unmarshals the __NEXT_DATA__ payload and checks that it still has the shape
+// the scrapper expects. Schema mismatches are returned as *rating.SchemaChangedError.
+func decodeNextData(programID int64, basis rating.Basis, payload []byte) (*RatingsNextJSData, error) {
+	var raw rawNextData
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	}
+
+	var problems []string
+	var nextData RatingsNextJSData
+	if err := json.Unmarshal(payload, &nextData); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+		}
+		problems = append(problems, fmt.Sprintf("field %s has unexpected type %s", typeErr.Field, typeErr.Value))
+	}
+
+	if raw.Props == nil || raw.Props.PageProps == nil || raw.Props.PageProps.ProgramList == nil {
+		problems = append(problems, "props.pageProps.programList is missing")
+	} else {
+		problems = append(problems, checkProgramList(programID, basis, raw.Props.PageProps.ProgramList)...)
+		problems = append(problems, checkEntries("general_competition", nextData.Props.PageProps.ProgramList.GeneralCompetition)...)
+		problems = append(problems, checkEntries("by_target_quota", nextData.Props.PageProps.ProgramList.ByTargetQuota)...)
+	}
+
+	if len(problems) > 0 {
+		schemaErrors.Add(fmt.Sprintf("%d/%s", programID, basis), 1)
+		return nil, &rating.SchemaChangedError{ProgramID: programID, Basis: basis, Problems: problems}
+	}
+
+	return &nextData, nil
+}
+
+func checkProgramList(programID int64, basis rating.Basis, programList map[string]json.RawMessage) []string {
+	var problems []string
+
+	problems = append(problems, missingFields("programList", programList, requiredProgramListFields[basis])...)
+	reportUnknown(programID, "programList", programList, jsonFields(reflect.TypeOf(RatingsNextJSData{}.Props.PageProps.ProgramList)))
+
+	if direction, ok := programList["direction"]; ok {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(direction, &fields); err != nil || fields == nil {
+			problems = append(problems, "programList.direction is not an object")
+		} else {
+			problems = append(problems, missingFields("programList.direction", fields, requiredDirectionFields)...)
+			reportUnknown(programID, "programList.direction", fields, jsonFields(reflect.TypeOf(ProgramDirection{})))
+		}
+	}
+
+	for _, list := range knownLists {
+		content, ok := programList[list]
+		if !ok {
+			continue
+		}
+		var entries []map[string]json.RawMessage
+		if err := json.Unmarshal(content, &entries); err != nil {
+			problems = append(problems, fmt.Sprintf("programList.%s is not a list of objects", list))
+			continue
+		}
+		known := append(jsonFields(reflect.TypeOf(RatingEntry{})), ignoredEntryFields...)
+		missing := make(map[string]int)
+		for _, entry := range entries {
+			for _, name := range requiredEntryFields {
+				if _, ok := entry[name]; !ok {
+					missing[name]++
+				}
+			}
+			reportUnknown(programID, "programList."+list+"[]", entry, known)
+		}
+		for _, name := range requiredEntryFields {
+			if n := missing[name]; n > 0 {
+				problems = append(problems, fmt.Sprintf("programList.%s[].%s is missing in %d of %d entries", list, name, n, len(entries)))
+			}
+		}
+	}
+
+	// An empty general competition list next to a populated unknown list most likely means
+	// the applicants were moved under a new key.
+	var general []json.RawMessage
+	_ = json.Unmarshal(programList["general_competition"], &general)
+	if len(general) == 0 {
+		for key, content := range programList {
+			if slices.Contains(knownLists, key) {
+				continue
+			}
+			var items []json.RawMessage
+			if json.Unmarshal(content, &items) == nil && len(items) > 0 {
+				problems = append(problems, fmt.Sprintf("general_competition is empty while programList.%s has %d items", key, len(items)))
+			}
+		}
+	}
+
+	return problems
+}
+
+func checkEntries(list string, entries []RatingEntry) []string {
+	var problems []string
+
+	prev := 0
+	for i, entry := range entries {
+		if entry.Position < 1 {
+			problems = append(problems, fmt.Sprintf("%s[%d]: position %d is not positive", list, i, entry.Position))
+		} else if entry.Position <= prev {
+			problems = append(problems, fmt.Sprintf("%s[%d]: position %d does not increase (previous %d)", list, i, entry.Position, prev))
+		}
+		prev = entry.Position
+
+		if entry.Priority < 1 {
+			problems = append(problems, fmt.Sprintf("%s[%d]: priority %d is not positive", list, i, entry.Priority))
+		}
+		if entry.TotalScores < 0 {
+			problems = append(problems, fmt.Sprintf("%s[%d]: total score %v is negative", list, i, entry.TotalScores))
+		}
+
+		// one broken list produces the same problem for every row, a few are enough to diagnose
+		if len(problems) >= 5 {
+			problems = append(problems, fmt.Sprintf("%s: further checks skipped", list))
+			break
+		}
+	}
+
+	return problems
+}
+
+func missingFields(path string, fields map[string]json.RawMessage, required []string) []string {
+	var problems []string
+	for _, name := range required {
+		if _, ok := fields[name]; !ok {
+			problems = append(problems, fmt.Sprintf("%s.%s is missing", path, name))
+		}
+	}
+	return problems
+}
+
+func reportUnknown(programID int64, path string, fields map[string]json.RawMessage, known []string) {
+	for name := range fields {
+		if slices.Contains(known, name) {
+			continue
+		}
+		field := path + "." + name
+		if _, loaded := reportedFields.LoadOrStore(field, struct{}{}); loaded {
+			continue
+		}
+		unknownFields.Add(field, 1)
+		slog.Warn("unknown field in rating page", "field", field, "programID", programID)
+	}
+}
+
+// jsonFields lists JSON names of the struct fields of t.
+func jsonFields(t reflect.Type) []string {
+	fields := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
@@ -3,11 +3,11 @@ package scrapper
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"itmo-ratings/internal/domain/rating"
 	"net/http"
-	"regexp"
 	"strings"
 	"time"
 
@@ -15,46 +15,112 @@ import (
 )
 
 const (
-	programsPageUrl = "https://abit.itmo.ru/ratings/master"
-	programPageUrl  = "https://abit.itmo.ru/rating/master/budget/%d"
-	programsAPIUrl  = "https://abitlk.itmo.ru/api/v1/rating/directions?degree=master"
-	timeout         = time.Minute * 5
-	maxRetries      = 3
-	retryDelay      = time.Second * 2
+	DefaultSiteURL    = "https://abit.itmo.ru"
+	DefaultAPIURL     = "https://abitlk.itmo.ru"
+	DefaultTimeout    = time.Minute * 5
+	DefaultMaxRetries = 3
+	DefaultRetryDelay = time.Second * 2
+	// DefaultMaxPageSize is well above the largest rating pages (a few MB for popular programs)
+	DefaultMaxPageSize = 32 << 20
+
+	programPagePath = "/rating/%s/%s/%d"
+	programsAPIPath = "/api/v1/rating/directions?degree=%s"
 )
 
 type Service struct {
-	client client
+	client     client
+	siteURL    string
+	apiURL     string
+	timeout    time.Duration
+	maxRetries int
+	retryDelay time.Duration
+	// maxPageSize caps the bytes read from a single response
+	maxPageSize int64
 }
 
-func New(httpClient client) *Service {
-	return &Service{client: httpClient}
+type Option func(*Service)
+
+// WithBaseURLs overrides the rating site (program pages) and the API base URLs.
+func WithBaseURLs(siteURL, apiURL string) Option {
+	return func(s *Service) {
+		s.siteURL = strings.TrimSuffix(siteURL, "/")
+		s.apiURL = strings.TrimSuffix(apiURL, "/")
+	}
+}
+
+// WithTimeout limits the duration of a single GetEntries or GetAllPrograms call.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		s.timeout = timeout
+	}
+}
+
+// WithRetries sets the number of page download attempts and the base delay between them.
+func WithRetries(maxRetries int, retryDelay time.Duration) Option {
+	return func(s *Service) {
+		s.maxRetries = maxRetries
+		s.retryDelay = retryDelay
+	}
+}
+
+// WithMaxPageSize caps the number of bytes read from a single response.
+func WithMaxPageSize(size int64) Option {
+	return func(s *Service) {
+		s.maxPageSize = size
+	}
+}
+
+func New(httpClient client, options ...Option) *Service {
+	s := &Service{
+		client:      httpClient,
+		siteURL:     DefaultSiteURL,
+		apiURL:      DefaultAPIURL,
+		timeout:     DefaultTimeout,
+		maxRetries:  DefaultMaxRetries,
+		retryDelay:  DefaultRetryDelay,
+		maxPageSize: DefaultMaxPageSize,
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	if s.maxRetries < 1 {
+		s.maxRetries = 1
+	}
+
+	return s
+}
+
+// ProgramURL is the rating page of a program list on the configured site.
+func (s *Service) ProgramURL(degree rating.Degree, basis rating.Basis, programID int) string {
+	return s.siteURL + fmt.Sprintf(programPagePath, degree, basis, programID)
 }
 
-func (s *Service) GetEntries(ctx context.Context, programID int64) ([]rating.Entry, time.Time, error) {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+func (s *Service) GetEntries(ctx context.Context, degree rating.Degree, basis rating.Basis, programID int64) ([]rating.Entry, time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
-	htmlContent, err := s.getPageWithRetries(ctx, fmt.Sprintf(programPageUrl, programID))
+	payload, err := s.getNextDataWithRetries(ctx, s.ProgramURL(degree, basis, int(programID)))
 	if err != nil {
-		return nil, time.Time{}, fmt.Errorf("failed to download html page: %d err: %v", programID, err)
+		return nil, time.Time{}, fmt.Errorf("failed to get __NEXT_DATA__ of program %d (%s): %w", programID, basis, err)
 	}
 
-	nextData, err := s.extractNextData(htmlContent)
+	nextData, err := decodeNextData(programID, basis, payload)
 	if err != nil {
-		return nil, time.Time{}, fmt.Errorf("failed to extract __NEXT_DATA__: %w", err)
+		return nil, time.Time{}, fmt.Errorf("failed to decode __NEXT_DATA__: %w", err)
 	}
 
-	entries := s.convertToRatingEntries(nextData)
+	entries := s.convertToRatingEntries(nextData, basis)
 
 	return entries, nextData.Props.PageProps.ProgramList.UpdateTime, nil
 }
 
-func (s *Service) GetAllPrograms(ctx context.Context) ([]rating.ProgramDirection, error) {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
+func (s *Service) GetAllPrograms(ctx context.Context, degree rating.Degree) ([]rating.ProgramDirection, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, programsAPIUrl, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.apiURL+fmt.Sprintf(programsAPIPath, degree), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -64,10 +130,10 @@ func (s *Service) GetAllPrograms(ctx context.Context) ([]rating.ProgramDirection
 	req.Header.Set("Accept-Language", "ru")
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("DNT", "1")
-	req.Header.Set("Origin", "https://abit.itmo.ru")
+	req.Header.Set("Origin", s.siteURL)
 	req.Header.Set("Pragma", "no-cache")
 	req.Header.Set("Priority", "u=1, i")
-	req.Header.Set("Referer", "https://abit.itmo.ru/")
+	req.Header.Set("Referer", s.siteURL+"/")
 	req.Header.Set("Sec-CH-UA", `"Not)A;Brand";v="8", "Chromium";v="138"`)
 	req.Header.Set("Sec-CH-UA-Mobile", "?0")
 	req.Header.Set("Sec-CH-UA-Platform", `"macOS"`)
@@ -86,10 +152,13 @@ func (s *Service) GetAllPrograms(ctx context.Context) ([]rating.ProgramDirection
 		return nil, fmt.Errorf("HTTP error: %d %s", resp.StatusCode, resp.Status)
 	}
 
-	content, err := io.ReadAll(resp.Body)
+	content, err := io.ReadAll(io.LimitReader(resp.Body, s.maxPageSize+1))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
+	if int64(len(content)) > s.maxPageSize {
+		return nil, fmt.Errorf("%w: more than %d bytes read", ErrPageTooLarge, s.maxPageSize)
+	}
 
 	var apiResponse ProgramsAPIResponse
 	if err := json.Unmarshal(content, &apiResponse); err != nil {
@@ -114,139 +183,116 @@ func (s *Service) GetAllPrograms(ctx context.Context) ([]rating.ProgramDirection
 	}), nil
 }
 
-func (s *Service) getPageWithRetries(ctx context.Context, url string) (string, error) {
+func (s *Service) getNextDataWithRetries(ctx context.Context, url string) ([]byte, error) {
 	var lastErr error
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
+	for attempt := 0; attempt < s.maxRetries; attempt++ {
 		select {
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return nil, ctx.Err()
 		default:
 		}
 
-		content, err := s.getPage(ctx, url)
+		payload, err := s.getNextData(ctx, url)
 		if err == nil {
-			return content, nil
+			return payload, nil
 		}
 
 		lastErr = err
 
 		// Don't retry on context cancellation/timeout
 		if ctx.Err() != nil {
-			return "", ctx.Err()
+			return nil, ctx.Err()
+		}
+
+		// A page over the limit won't shrink on retry
+		if errors.Is(err, ErrPageTooLarge) {
+			return nil, err
 		}
 
 		// Wait before retry (except for last attempt)
-		if attempt < maxRetries-1 {
+		if attempt < s.maxRetries-1 {
 			select {
-			case <-time.After(retryDelay * time.Duration(attempt+1)): // Exponential backoff
+			case <-time.After(s.retryDelay * time.Duration(attempt+1)): // Exponential backoff
 			case <-ctx.Done():
-				return "", ctx.Err()
+				return nil, ctx.Err()
 			}
 		}
 	}
 
-	return "", fmt.Errorf("failed after %d attempts, last error: %w", maxRetries, lastErr)
+	return nil, fmt.Errorf("failed after %d attempts, last error: %w", s.maxRetries, lastErr)
 }
 
-func (s *Service) getPage(ctx context.Context, url string) (string, error) {
+func (s *Service) getNextData(ctx context.Context, url string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to assemble request: %w", err)
+		return nil, fmt.Errorf("failed to assemble request: %w", err)
 	}
 
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to execute request: %w", err)
+		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		return "", fmt.Errorf("HTTP error: %d %s", resp.StatusCode, resp.Status)
-	}
-
-	content, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read body content: %w", err)
+		return nil, fmt.Errorf("HTTP error: %d %s", resp.StatusCode, resp.Status)
 	}
 
-	return string(content), nil
+	return extractNextData(resp.Body, s.maxPageSize)
 }
 
-func (s *Service) extractNextData(htmlContent string) (*RatingsNextJSData, error) {
-	re := regexp.MustCompile(`<script id="__NEXT_DATA__" type="application/json">(.*?)</script>`)
-	matches := re.FindStringSubmatch(htmlContent)
+func (s *Service) convertToRatingEntries(nextData *RatingsNextJSData, basis rating.Basis) []rating.Entry {
+	var entries []rating.Entry
 
-	if len(matches) < 2 {
-		return nil, fmt.Errorf("__NEXT_DATA__ script tag not found")
+	// Process general competition entries
+	for _, entry := range nextData.Props.PageProps.ProgramList.GeneralCompetition {
+		entries = append(entries, convertEntry(entry, basis, false))
 	}
 
-	jsonContent := strings.TrimSpace(matches[1])
-
-	var nextData RatingsNextJSData
-	if err := json.Unmarshal([]byte(jsonContent), &nextData); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	// Target quota applicants are kept to know how many quota seats are left for the general competition
+	for _, entry := range nextData.Props.PageProps.ProgramList.ByTargetQuota {
+		entries = append(entries, convertEntry(entry, basis, true))
 	}
 
-	return &nextData, nil
+	return entries
 }
 
-func (s *Service) convertToRatingEntries(nextData *RatingsNextJSData) []rating.Entry {
-	var entries []rating.Entry
-
-	// Process general competition entries
-	for _, entry := range nextData.Props.PageProps.ProgramList.GeneralCompetition {
-		ratingEntry := rating.Entry{
-			Position:       entry.Position,
-			Priority:       entry.Priority,
-			DiplomaAverage: entry.DiplomaAverage,
-			ExamScores:     entry.ExamScores,
-			TotalScores:    entry.TotalScores,
-			SNILS:          entry.SNILS,
-			CaseNumber:     entry.CaseNumber,
-			SSPVOID:        entry.SSPVO,
-			// Add other fields as needed for your rating.Entry struct
-		}
-
-		// Handle nullable fields
-		if entry.Contest != nil {
-			ratingEntry.Contest = *entry.Contest
-		}
-		if entry.ExamType != nil {
-			ratingEntry.ExamType = *entry.ExamType
-		}
-		if entry.Status != nil {
-			ratingEntry.Status = *entry.Status
-		}
+func convertEntry(entry RatingEntry, basis rating.Basis, targetQuota bool) rating.Entry {
+	ratingEntry := rating.Entry{
+		Basis:                     basis,
+		TargetQuota:               targetQuota,
+		Position:                  entry.Position,
+		Priority:                  entry.Priority,
+		DiplomaAverage:            entry.DiplomaAverage,
+		IAScores:                  entry.IAScores,
+		ExamScores:                entry.ExamScores,
+		TotalScores:               entry.TotalScores,
+		IsSendAgreement:           entry.IsSendAgreement,
+		SNILS:                     entry.SNILS,
+		CaseNumber:                entry.CaseNumber,
+		Link:                      entry.Link,
+		IsSpecialBCategory:        entry.IsSpecialBCategory,
+		SSPVOID:                   entry.SSPVO,
+		MainTopPriority:           entry.MainTopPriority,
+		HighestPassagewayPriority: entry.HighestPassagewayPriority,
+		IsPublishedInWorkInRussia: entry.IsPublishedInWorkInRussia,
+		OfferNumber:               entry.OfferNumber,
+		IsDetailedTargetQuota:     entry.IsDetailedTargetQuota,
+		TargetAchievements:        entry.TargetAchievements,
+		HasApprovedContract:       entry.HasApprovedContract,
+	}
 
-		entries = append(entries, ratingEntry)
-	}
-
-	// for _, entry := range nextData.Props.PageProps.ProgramList.ByTargetQuota {
-	// 	ratingEntry := rating.Entry{
-	// 		Position:       entry.Position,
-	// 		Priority:       entry.Priority,
-	// 		DiplomaAverage: entry.DiplomaAverage,
-	// 		ExamScores:     entry.ExamScores,
-	// 		TotalScores:    entry.TotalScores,
-	// 		SNILS:          entry.SNILS,
-	// 		CaseNumber:     entry.CaseNumber,
-	// 		// Add other fields as needed
-	// 	}
-
-	// 	// Handle nullable fields
-	// 	if entry.Contest != nil {
-	// 		ratingEntry.Contest = *entry.Contest
-	// 	}
-	// 	if entry.ExamType != nil {
-	// 		ratingEntry.ExamType = *entry.ExamType
-	// 	}
-	// 	if entry.Status != nil {
-	// 		ratingEntry.Status = *entry.Status
-	// 	}
-
-	// 	entries = append(entries, ratingEntry)
-	// }
+	// Handle nullable fields
+	if entry.Contest != nil {
+		ratingEntry.Contest = *entry.Contest
+	}
+	if entry.ExamType != nil {
+		ratingEntry.ExamType = *entry.ExamType
+	}
+	if entry.Status != nil {
+		ratingEntry.Status = *entry.Status
+	}
 
-	return entries
+	return ratingEntry
 }
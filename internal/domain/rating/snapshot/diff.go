@@ -0,0 +1,135 @@
+package snapshot
+
+import (
+	"cmp"
+	"slices"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+// ListDiff compares an admission list of a program between two snapshots.
+// Applicants are matched by their SSPVO ID, entries without one are only counted.
+type ListDiff struct {
+	Degree       rating.Degree `json:"degree"`
+	ProgramID    int           `json:"program_id"`
+	ProgramTitle string        `json:"program_title"`
+	Basis        rating.Basis  `json:"basis"`
+	TargetQuota  bool          `json:"target_quota"`
+	Before       int           `json:"before"`
+	After        int           `json:"after"`
+	Added        []string      `json:"added"`
+	Removed      []string      `json:"removed"`
+	// Agreements lists applicants who submitted the agreement since the first snapshot.
+	Agreements []string `json:"agreements"`
+	// Moved counts applicants present in both snapshots whose position changed.
+	Moved int `json:"moved"`
+}
+
+// Changed reports whether the list differs between the snapshots.
+func (d ListDiff) Changed() bool {
+	return d.Before != d.After || len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Agreements) > 0 || d.Moved > 0
+}
+
+type Diff struct {
+	From  string     `json:"from"`
+	To    string     `json:"to"`
+	Lists []ListDiff `json:"lists"`
+}
+
+type listKey struct {
+	degree      rating.Degree
+	programID   int
+	basis       rating.Basis
+	targetQuota bool
+}
+
+// Compare diffs every admission list present in either snapshot, unchanged lists are left out.
+func Compare(from, to *Snapshot) Diff {
+	before := groupRows(from.Rows)
+	after := groupRows(to.Rows)
+
+	keys := make([]listKey, 0, len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b listKey) int {
+		return cmp.Or(
+			cmp.Compare(a.degree, b.degree),
+			cmp.Compare(a.programID, b.programID),
+			cmp.Compare(a.basis, b.basis),
+			compareBool(a.targetQuota, b.targetQuota),
+		)
+	})
+
+	diff := Diff{From: from.ID, To: to.ID}
+	for _, key := range keys {
+		d := compareList(key, before[key], after[key])
+		if d.Changed() {
+			diff.Lists = append(diff.Lists, d)
+		}
+	}
+	return diff
+}
+
+func groupRows(rows []Row) map[listKey][]Row {
+	lists := make(map[listKey][]Row)
+	for _, row := range rows {
+		key := listKey{row.Degree, row.ProgramID, row.Basis, row.TargetQuota}
+		lists[key] = append(lists[key], row)
+	}
+	return lists
+}
+
+func compareList(key listKey, before, after []Row) ListDiff {
+	d := ListDiff{
+		Degree:      key.degree,
+		ProgramID:   key.programID,
+		Basis:       key.basis,
+		TargetQuota: key.targetQuota,
+		Before:      len(before),
+		After:       len(after),
+	}
+	if len(after) > 0 {
+		d.ProgramTitle = after[0].ProgramTitle
+	} else if len(before) > 0 {
+		d.ProgramTitle = before[0].ProgramTitle
+	}
+
+	old := make(map[string]*Row, len(before))
+	for i := range before {
+		if before[i].SSPVOID != "" {
+			old[before[i].SSPVOID] = &before[i]
+		}
+	}
+	for i := range after {
+		row := &after[i]
+		if row.SSPVOID == "" {
+			continue
+		}
+		prev, ok := old[row.SSPVOID]
+		delete(old, row.SSPVOID)
+		switch {
+		case !ok:
+			d.Added = append(d.Added, row.SSPVOID)
+			if row.IsSendAgreement {
+				d.Agreements = append(d.Agreements, row.SSPVOID)
+			}
+			continue
+		case row.IsSendAgreement && !prev.IsSendAgreement:
+			d.Agreements = append(d.Agreements, row.SSPVOID)
+		}
+		if prev.Position != row.Position {
+			d.Moved++
+		}
+	}
+	for id := range old {
+		d.Removed = append(d.Removed, id)
+	}
+	slices.Sort(d.Removed)
+	return d
+}
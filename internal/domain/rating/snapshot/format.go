@@ -0,0 +1,97 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"itmo-ratings/pkg/parquet"
+)
+
+// Format is a dump file format.
+type Format string
+
+const (
+	FormatNDJSON  Format = "ndjson"
+	FormatParquet Format = "parquet"
+)
+
+func Formats() []Format {
+	return []Format{FormatNDJSON, FormatParquet}
+}
+
+func ParseFormat(s string) (Format, error) {
+	f := Format(s)
+	if !slices.Contains(Formats(), f) {
+		return "", fmt.Errorf("unknown snapshot format %q, expected one of %v", s, Formats())
+	}
+	return f, nil
+}
+
+// ParseFormats parses a comma separated list of formats.
+func ParseFormats(s string) ([]Format, error) {
+	var out []Format
+	for _, part := range strings.Split(s, ",") {
+		f, err := ParseFormat(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// WriteNDJSON writes a JSON object per row.
+func WriteNDJSON(w io.Writer, rows []Row) error {
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+	for i := range rows {
+		if err := encoder.Encode(&rows[i]); err != nil {
+			return fmt.Errorf("failed to write NDJSON: %w", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write NDJSON: %w", err)
+	}
+	return nil
+}
+
+func ReadNDJSON(r io.Reader) ([]Row, error) {
+	var rows []Row
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		var row Row
+		if err := decoder.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to read NDJSON row %d: %w", len(rows)+1, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// WriteParquet writes the rows as a Parquet file, the column documentation goes to the footer metadata.
+func WriteParquet(w io.Writer, rows []Row) error {
+	columns := make([]parquet.Column, 0, len(schema))
+	for _, f := range schema {
+		columns = append(columns, parquet.Column{Name: f.name, Type: f.typ, Optional: f.nullable})
+	}
+	doc, err := json.Marshal(Columns())
+	if err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+
+	pw := parquet.NewWriter(w, columns, parquet.WithMetadata("itmo_ratings.schema", string(doc)))
+	values := make([]any, len(schema))
+	for i := range rows {
+		for j, f := range schema {
+			values[j] = f.value(&rows[i])
+		}
+		if err := pw.Write(values); err != nil {
+			return err
+		}
+	}
+	return pw.Close()
+}
@@ -0,0 +1,60 @@
+package snapshot
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Periodic saves snapshots of a refreshing rating service at most once per interval.
+// It is not safe for concurrent use, calls come from the refresh loop and the shutdown after it.
+type Periodic struct {
+	store    *Store
+	source   source
+	interval time.Duration
+	formats  []Format
+
+	lastSaved time.Time
+	pending   bool
+}
+
+func NewPeriodic(store *Store, source source, interval time.Duration, formats ...Format) *Periodic {
+	return &Periodic{
+		store:    store,
+		source:   source,
+		interval: interval,
+		formats:  formats,
+	}
+}
+
+// Refreshed is called after every cache refresh and saves a snapshot once the interval has passed.
+func (p *Periodic) Refreshed(ctx context.Context) {
+	p.pending = true
+	if time.Since(p.lastSaved) < p.interval {
+		return
+	}
+	p.save(ctx)
+}
+
+// Flush saves the refreshes made since the last snapshot, it is called on shutdown.
+func (p *Periodic) Flush(ctx context.Context) {
+	if p.pending {
+		p.save(ctx)
+	}
+}
+
+func (p *Periodic) save(ctx context.Context) {
+	snap, err := Collect(ctx, p.source)
+	if err != nil {
+		slog.Error("failed to collect snapshot", "err", err.Error())
+		return
+	}
+	info, err := p.store.Save(snap, p.formats...)
+	if err != nil {
+		slog.Error("failed to save snapshot", "id", snap.ID, "err", err.Error())
+		return
+	}
+	p.lastSaved = time.Now()
+	p.pending = false
+	slog.Info("snapshot saved", "id", info.ID, "programs", info.Programs, "rows", info.Rows)
+}
@@ -0,0 +1,183 @@
+package snapshot
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/pkg/parquet"
+)
+
+// Row is a single admission list entry joined with the metadata of its program.
+// The JSON names are the column names of every dump format.
+type Row struct {
+	Degree         rating.Degree `json:"degree"`
+	ProgramID      int           `json:"program_id"`
+	ProgramTitle   string        `json:"program_title"`
+	IsuID          *int          `json:"isu_id"`
+	BudgetSeats    int           `json:"budget_seats"`
+	ContractSeats  int           `json:"contract_seats"`
+	TargetSeats    int           `json:"target_seats"`
+	SpecialQuota   int           `json:"special_quota"`
+	SeparateQuota  int           `json:"separate_quota"`
+	ProgramUpdated time.Time     `json:"program_updated"`
+	rating.Entry
+}
+
+// Column documents a dump column.
+type Column struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Nullable    bool   `json:"nullable"`
+	Description string `json:"description"`
+}
+
+type field struct {
+	name        string
+	typ         parquet.Type
+	nullable    bool
+	description string
+	value       func(*Row) any
+}
+
+// schema is the single source of the column order, types and documentation, new columns are only appended.
+var schema = []field{
+	{"degree", parquet.String, false, "education level: bachelor, master, postgraduate", func(r *Row) any { return string(r.Degree) }},
+	{"program_id", parquet.Int64, false, "competitive group ID of the program", func(r *Row) any { return r.ProgramID }},
+	{"program_title", parquet.String, false, "program title", func(r *Row) any { return r.ProgramTitle }},
+	{"isu_id", parquet.Int64, true, "ISU ID of the program", func(r *Row) any { return optional(r.IsuID) }},
+	{"budget_seats", parquet.Int64, false, "budget places of the general competition", func(r *Row) any { return r.BudgetSeats }},
+	{"contract_seats", parquet.Int64, false, "contract places", func(r *Row) any { return r.ContractSeats }},
+	{"target_seats", parquet.Int64, false, "target quota places", func(r *Row) any { return r.TargetSeats }},
+	{"special_quota", parquet.Int64, false, "special quota places", func(r *Row) any { return r.SpecialQuota }},
+	{"separate_quota", parquet.Int64, false, "separate quota places", func(r *Row) any { return r.SeparateQuota }},
+	{"program_updated", parquet.Timestamp, false, "update time of the rating page", func(r *Row) any { return r.ProgramUpdated }},
+	{"basis", parquet.String, false, "admission list: budget or contract", func(r *Row) any { return string(r.Basis) }},
+	{"target_quota", parquet.Boolean, false, "entry of the target quota list", func(r *Row) any { return r.TargetQuota }},
+	{"contest", parquet.String, false, "competition type", func(r *Row) any { return r.Contest }},
+	{"exam_type", parquet.String, false, "entrance examination type", func(r *Row) any { return r.ExamType }},
+	{"diploma_average", parquet.Double, false, "average diploma grade", func(r *Row) any { return r.DiplomaAverage }},
+	{"position", parquet.Int64, false, "position on the list", func(r *Row) any { return r.Position }},
+	{"priority", parquet.Int64, false, "priority of the program for the applicant", func(r *Row) any { return r.Priority }},
+	{"ia_scores", parquet.Double, false, "individual achievement points", func(r *Row) any { return r.IAScores }},
+	{"exam_scores", parquet.Double, false, "entrance examination points", func(r *Row) any { return r.ExamScores }},
+	{"total_scores", parquet.Double, false, "total points", func(r *Row) any { return r.TotalScores }},
+	{"is_send_agreement", parquet.Boolean, false, "consent to enrolment submitted", func(r *Row) any { return r.IsSendAgreement }},
+	{"snils", parquet.String, false, "SNILS when published", func(r *Row) any { return r.SNILS }},
+	{"case_number", parquet.String, false, "application case number", func(r *Row) any { return r.CaseNumber }},
+	{"link", parquet.String, false, "link to the application", func(r *Row) any { return r.Link }},
+	{"status", parquet.String, false, "application status", func(r *Row) any { return r.Status }},
+	{"is_special_b_category", parquet.Boolean, true, "flag as published by ITMO", func(r *Row) any { return optional(r.IsSpecialBCategory) }},
+	{"sspvo_id", parquet.String, false, "applicant ID in SS PVO", func(r *Row) any { return r.SSPVOID }},
+	{"main_top_priority", parquet.Boolean, false, "flag as published by ITMO", func(r *Row) any { return r.MainTopPriority }},
+	{"highest_passageway_priority", parquet.Boolean, false, "flag as published by ITMO", func(r *Row) any { return r.HighestPassagewayPriority }},
+	{"is_published_in_work_in_russia", parquet.Boolean, true, "flag as published by ITMO", func(r *Row) any { return optional(r.IsPublishedInWorkInRussia) }},
+	{"offer_number", parquet.String, true, "target quota offer number", func(r *Row) any { return optional(r.OfferNumber) }},
+	{"is_detailed_target_quota", parquet.Boolean, true, "flag as published by ITMO", func(r *Row) any { return optional(r.IsDetailedTargetQuota) }},
+	{"target_achievements", parquet.Double, true, "target quota achievement points", func(r *Row) any { return optional(r.TargetAchievements) }},
+	{"has_approved_contract", parquet.Boolean, true, "flag as published by ITMO", func(r *Row) any { return optional(r.HasApprovedContract) }},
+}
+
+// Columns documents the dump schema.
+func Columns() []Column {
+	out := make([]Column, 0, len(schema))
+	for _, f := range schema {
+		out = append(out, Column{Name: f.name, Type: f.typ.String(), Nullable: f.nullable, Description: f.description})
+	}
+	return out
+}
+
+func optional[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// Rows flattens the programs of a degree, ordered by program, list and position.
+func Rows(degree rating.Degree, programs map[int]rating.ProgramData) []Row {
+	var out []Row
+	for id, program := range programs {
+		meta := Row{
+			Degree:         degree,
+			ProgramID:      id,
+			ProgramTitle:   program.Data.DirectionTitle,
+			IsuID:          program.Data.IsuID,
+			BudgetSeats:    program.Data.BudgetMin,
+			ContractSeats:  program.Data.Contract,
+			TargetSeats:    program.Data.TargetReception,
+			SpecialQuota:   program.Data.SpecialQuota,
+			SeparateQuota:  program.Data.Invalid,
+			ProgramUpdated: program.LastUpdated,
+		}
+		for _, list := range [][]rating.Entry{program.Entries, program.TargetEntries, program.ContractEntries} {
+			for _, entry := range list {
+				row := meta
+				row.Entry = entry
+				out = append(out, row)
+			}
+		}
+	}
+
+	slices.SortFunc(out, func(a, b Row) int {
+		return cmp.Or(
+			cmp.Compare(a.Degree, b.Degree),
+			cmp.Compare(a.ProgramID, b.ProgramID),
+			cmp.Compare(a.Basis, b.Basis),
+			compareBool(a.TargetQuota, b.TargetQuota),
+			cmp.Compare(a.Position, b.Position),
+		)
+	})
+	return out
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// Programs rebuilds the program lists from rows, the inverse of Rows.
+func Programs(rows []Row) map[rating.Degree]map[int]rating.ProgramData {
+	out := make(map[rating.Degree]map[int]rating.ProgramData)
+	for _, row := range rows {
+		programs, ok := out[row.Degree]
+		if !ok {
+			programs = make(map[int]rating.ProgramData)
+			out[row.Degree] = programs
+		}
+		program, ok := programs[row.ProgramID]
+		if !ok {
+			program = rating.ProgramData{
+				Degree: row.Degree,
+				Data: &rating.ProgramDirection{
+					DirectionTitle:     row.ProgramTitle,
+					BudgetMin:          row.BudgetSeats,
+					Contract:           row.ContractSeats,
+					TargetReception:    row.TargetSeats,
+					IsuID:              row.IsuID,
+					Invalid:            row.SeparateQuota,
+					SpecialQuota:       row.SpecialQuota,
+					CompetitiveGroupID: row.ProgramID,
+				},
+				LastUpdated: row.ProgramUpdated,
+			}
+		}
+		switch {
+		case row.Basis == rating.BasisContract:
+			program.ContractEntries = append(program.ContractEntries, row.Entry)
+		case row.TargetQuota:
+			program.TargetEntries = append(program.TargetEntries, row.Entry)
+		default:
+			program.Entries = append(program.Entries, row.Entry)
+		}
+		programs[row.ProgramID] = program
+	}
+	return out
+}
@@ -0,0 +1,241 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/pkg/fsutil"
+)
+
+const (
+	manifestName    = "manifest.json"
+	manifestVersion = 1
+	idLayout        = "20060102T150405Z"
+)
+
+var ErrNotFound = errors.New("snapshot not found")
+
+// Snapshot is the content of all tracked degree caches at a moment.
+type Snapshot struct {
+	ID        string
+	CreatedAt time.Time
+	Rows      []Row
+}
+
+// New flattens the programs of every degree into a snapshot identified by its creation time.
+func New(createdAt time.Time, programs map[rating.Degree]map[int]rating.ProgramData) *Snapshot {
+	createdAt = createdAt.UTC().Truncate(time.Second)
+	snap := &Snapshot{
+		ID:        createdAt.Format(idLayout),
+		CreatedAt: createdAt,
+	}
+	for _, degree := range slices.Sorted(maps.Keys(programs)) {
+		snap.Rows = append(snap.Rows, Rows(degree, programs[degree])...)
+	}
+	return snap
+}
+
+// Manifest lists the snapshots of a store directory and documents their schema.
+type Manifest struct {
+	Version   int      `json:"version"`
+	Schema    []Column `json:"schema"`
+	Snapshots []Info   `json:"snapshots"`
+}
+
+type Info struct {
+	ID        string          `json:"id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Degrees   []rating.Degree `json:"degrees"`
+	Programs  int             `json:"programs"`
+	Rows      int             `json:"rows"`
+	Files     []File          `json:"files"`
+}
+
+type File struct {
+	Format Format `json:"format"`
+	// Path is relative to the store directory.
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Store keeps snapshot dumps in a directory, one file per snapshot and format, and a manifest.json.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Manifest reads the manifest, empty when nothing was saved yet.
+func (s *Store) Manifest() (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(s.dir, manifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Manifest{Version: manifestVersion, Schema: Columns()}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Save writes the snapshot in every format and records it in the manifest.
+func (s *Store) Save(snap *Snapshot, formats ...Format) (*Info, error) {
+	if len(formats) == 0 {
+		formats = Formats()
+	}
+
+	info := Info{
+		ID:        snap.ID,
+		CreatedAt: snap.CreatedAt,
+		Rows:      len(snap.Rows),
+	}
+	programs := make(map[string]struct{})
+	for _, row := range snap.Rows {
+		if !slices.Contains(info.Degrees, row.Degree) {
+			info.Degrees = append(info.Degrees, row.Degree)
+		}
+		programs[fmt.Sprintf("%s/%d", row.Degree, row.ProgramID)] = struct{}{}
+	}
+	info.Programs = len(programs)
+
+	for _, format := range formats {
+		file, err := s.writeSnapshot(snap, format)
+		if err != nil {
+			return nil, err
+		}
+		info.Files = append(info.Files, file)
+	}
+
+	m, err := s.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	m.Version = manifestVersion
+	m.Schema = Columns()
+	m.Snapshots = slices.DeleteFunc(m.Snapshots, func(v Info) bool { return v.ID == info.ID })
+	m.Snapshots = append(m.Snapshots, info)
+	slices.SortFunc(m.Snapshots, func(a, b Info) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := fsutil.WriteAtomic(filepath.Join(s.dir, manifestName), 0o644, func(w io.Writer) error {
+		_, err := w.Write(content)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return &info, nil
+}
+
+func (s *Store) writeSnapshot(snap *Snapshot, format Format) (File, error) {
+	file := File{Format: format, Path: snap.ID + "." + string(format)}
+	hash := sha256.New()
+	counter := &countingWriter{}
+
+	err := fsutil.WriteAtomic(filepath.Join(s.dir, file.Path), 0o644, func(w io.Writer) error {
+		w = io.MultiWriter(w, hash, counter)
+		if format == FormatParquet {
+			return WriteParquet(w, snap.Rows)
+		}
+		return WriteNDJSON(w, snap.Rows)
+	})
+	if err != nil {
+		return File{}, fmt.Errorf("failed to write %s snapshot: %w", format, err)
+	}
+
+	file.Size = counter.n
+	file.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return file, nil
+}
+
+// Load reads a snapshot by its ID or by the path of its NDJSON file. Parquet files are write-only,
+// a snapshot saved only as Parquet can't be loaded.
+func (s *Store) Load(ref string) (*Snapshot, error) {
+	m, err := s.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	path := ref
+	snap := &Snapshot{ID: ref}
+	for _, info := range m.Snapshots {
+		if info.ID != ref {
+			continue
+		}
+		snap.CreatedAt = info.CreatedAt
+		path = ""
+		for _, file := range info.Files {
+			if file.Format == FormatNDJSON {
+				path = filepath.Join(s.dir, file.Path)
+			}
+		}
+		if path == "" {
+			return nil, fmt.Errorf("%w: %s is saved without NDJSON, only NDJSON dumps can be read back", ErrNotFound, ref)
+		}
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s (only NDJSON dumps can be read back)", ErrNotFound, ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer f.Close()
+
+	if snap.Rows, err = ReadNDJSON(f); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+type source interface {
+	Degrees() []rating.Degree
+	GetPrograms(ctx context.Context, degree rating.Degree) (map[int]rating.ProgramData, error)
+}
+
+// Collect takes a snapshot of every degree tracked by the rating service.
+func Collect(ctx context.Context, source source) (*Snapshot, error) {
+	programs := make(map[rating.Degree]map[int]rating.ProgramData)
+	for _, degree := range source.Degrees() {
+		degreePrograms, err := source.GetPrograms(ctx, degree)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s programs: %w", degree, err)
+		}
+		programs[degree] = degreePrograms
+	}
+	return New(time.Now(), programs), nil
+}
@@ -0,0 +1,134 @@
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/admission"
+)
+
+// Order is the leaderboard ranking key, every key ranks the most competitive programs first.
+type Order string
+
+const (
+	OrderCompetition   Order = "competition"
+	OrderCutoff        Order = "cutoff"
+	OrderFirstPriority Order = "first_priority"
+	OrderGrowth        Order = "growth"
+)
+
+func Orders() []Order {
+	return []Order{OrderCompetition, OrderCutoff, OrderFirstPriority, OrderGrowth}
+}
+
+func ParseOrder(s string) (Order, error) {
+	if s == "" {
+		return OrderCompetition, nil
+	}
+	order := Order(s)
+	if !slices.Contains(Orders(), order) {
+		return "", fmt.Errorf("unknown order %q", s)
+	}
+	return order, nil
+}
+
+// ProgramRank is a single leaderboard row, seat ratios are zero for programs without budget places.
+type ProgramRank struct {
+	Rank                 int     `json:"rank"`
+	ProgramID            int     `json:"programId"`
+	Title                string  `json:"title"`
+	Applications         int     `json:"applications"`
+	EffectiveSeats       int     `json:"effectiveSeats"`
+	ApplicantsPerSeat    float64 `json:"applicantsPerSeat"`
+	FirstPriority        int     `json:"firstPriority"`
+	FirstPriorityPerSeat float64 `json:"firstPriorityPerSeat"`
+	// ProjectedCutoff is the simulated passing score, zero when the program doesn't fill up.
+	ProjectedCutoff float64 `json:"projectedCutoff"`
+	// Growth is the change in applications since the previous cache refresh of the running service,
+	// nil for programs the refresh didn't have. It is not read from saved snapshots and starts over
+	// on restart.
+	Growth *int `json:"growth"`
+}
+
+type Leaderboard struct {
+	Degree rating.Degree `json:"degree"`
+	Order  Order         `json:"order"`
+	// PreviousUpdated is the time of the cache refresh Growth is counted from, nil until the service
+	// refreshed the ratings twice.
+	PreviousUpdated *time.Time    `json:"previousUpdated"`
+	Programs        []ProgramRank `json:"programs"`
+}
+
+// ComputeLeaderboard ranks the budget general competition of all programs of a degree.
+// previous holds the application counts of the previous cache refresh by competitive group ID.
+func ComputeLeaderboard(
+	degree rating.Degree,
+	programs map[int]rating.ProgramData,
+	simulation admission.Result,
+	previous map[int]int,
+	order Order,
+) Leaderboard {
+	out := Leaderboard{
+		Degree:   degree,
+		Order:    order,
+		Programs: make([]ProgramRank, 0, len(programs)),
+	}
+
+	for id, program := range programs {
+		seats := admission.Seats(&program).Effective
+		row := ProgramRank{
+			ProgramID:      id,
+			Title:          program.Data.DirectionTitle,
+			Applications:   len(program.Entries),
+			EffectiveSeats: seats,
+		}
+		for _, entry := range program.Entries {
+			if entry.Priority == 1 {
+				row.FirstPriority++
+			}
+		}
+		if seats > 0 {
+			row.ApplicantsPerSeat = float64(row.Applications) / float64(seats)
+			row.FirstPriorityPerSeat = float64(row.FirstPriority) / float64(seats)
+		}
+		if cutoff := simulation.Cutoffs[id]; cutoff.Full {
+			row.ProjectedCutoff = cutoff.Score
+		}
+		if count, ok := previous[id]; ok {
+			growth := row.Applications - count
+			row.Growth = &growth
+		}
+		out.Programs = append(out.Programs, row)
+	}
+
+	slices.SortFunc(out.Programs, func(a, b ProgramRank) int {
+		if c := cmp.Compare(orderKey(b, order), orderKey(a, order)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ProgramID, b.ProgramID)
+	})
+	for i := range out.Programs {
+		out.Programs[i].Rank = i + 1
+	}
+
+	return out
+}
+
+func orderKey(row ProgramRank, order Order) float64 {
+	switch order {
+	case OrderCutoff:
+		return row.ProjectedCutoff
+	case OrderFirstPriority:
+		return row.FirstPriorityPerSeat
+	case OrderGrowth:
+		if row.Growth == nil {
+			return 0
+		}
+		return float64(*row.Growth)
+	default:
+		return row.ApplicantsPerSeat
+	}
+}
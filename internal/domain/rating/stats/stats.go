@@ -0,0 +1,159 @@
+package stats
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/admission"
+)
+
+const (
+	// DefaultBinWidth is the histogram bin width in points.
+	DefaultBinWidth = 10
+	// MinBinWidth is the narrowest bin worth asking for, scores are whole or half points.
+	MinBinWidth = 0.5
+	// MaxBins caps the histogram, wider bins are used when the scores span more.
+	MaxBins = 1000
+)
+
+type Bin struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+type Percentiles struct {
+	P10 float64 `json:"p10"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P90 float64 `json:"p90"`
+}
+
+type Distribution struct {
+	Min         float64     `json:"min"`
+	Max         float64     `json:"max"`
+	Mean        float64     `json:"mean"`
+	Median      float64     `json:"median"`
+	Percentiles Percentiles `json:"percentiles"`
+	Histogram   []Bin       `json:"histogram"`
+}
+
+// ProgramStats is computed from the budget general competition list of a program.
+type ProgramStats struct {
+	ProgramID      int           `json:"programId"`
+	Title          string        `json:"title"`
+	Degree         rating.Degree `json:"degree"`
+	Applications   int           `json:"applications"`
+	BudgetSeats    int           `json:"budgetSeats"`
+	EffectiveSeats int           `json:"effectiveSeats"`
+	// CompetitionRatio is applications per effective budget seat, zero without seats.
+	CompetitionRatio float64        `json:"competitionRatio"`
+	TotalScores      Distribution   `json:"totalScores"`
+	ExamScores       Distribution   `json:"examScores"`
+	Agreements       int            `json:"agreements"`
+	AgreementShare   float64        `json:"agreementShare"`
+	Priorities       map[int]int    `json:"priorities"`
+	ExamTypes        map[string]int `json:"examTypes"`
+	Contests         map[string]int `json:"contests"`
+	LastUpdated      time.Time      `json:"lastUpdated"`
+}
+
+// Compute aggregates the program list. binWidth <= 0, NaN or infinite means DefaultBinWidth.
+func Compute(program *rating.ProgramData, binWidth float64) ProgramStats {
+	if !(binWidth > 0) || math.IsInf(binWidth, 0) {
+		binWidth = DefaultBinWidth
+	}
+
+	seats := admission.Seats(program)
+	out := ProgramStats{
+		ProgramID:      program.Data.CompetitiveGroupID,
+		Title:          program.Data.DirectionTitle,
+		Degree:         program.Degree,
+		Applications:   len(program.Entries),
+		BudgetSeats:    program.Data.BudgetMin,
+		EffectiveSeats: seats.Effective,
+		Priorities:     make(map[int]int),
+		ExamTypes:      make(map[string]int),
+		Contests:       make(map[string]int),
+		LastUpdated:    program.LastUpdated,
+	}
+	if seats.Effective > 0 {
+		out.CompetitionRatio = float64(out.Applications) / float64(seats.Effective)
+	}
+
+	totals := make([]float64, 0, len(program.Entries))
+	exams := make([]float64, 0, len(program.Entries))
+	for _, entry := range program.Entries {
+		totals = append(totals, entry.TotalScores)
+		exams = append(exams, entry.ExamScores)
+		if entry.IsSendAgreement {
+			out.Agreements++
+		}
+		out.Priorities[entry.Priority]++
+		out.ExamTypes[entry.ExamType]++
+		out.Contests[entry.Contest]++
+	}
+	if out.Applications > 0 {
+		out.AgreementShare = float64(out.Agreements) / float64(out.Applications)
+	}
+
+	out.TotalScores = distribution(totals, binWidth)
+	out.ExamScores = distribution(exams, binWidth)
+
+	return out
+}
+
+func distribution(values []float64, binWidth float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{Histogram: []Bin{}}
+	}
+
+	slices.Sort(values)
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+
+	d := Distribution{
+		Min:    values[0],
+		Max:    values[len(values)-1],
+		Mean:   sum / float64(len(values)),
+		Median: percentile(values, 0.5),
+		Percentiles: Percentiles{
+			P10: percentile(values, 0.1),
+			P25: percentile(values, 0.25),
+			P50: percentile(values, 0.5),
+			P75: percentile(values, 0.75),
+			P90: percentile(values, 0.9),
+		},
+	}
+
+	if span := d.Max - d.Min; span/binWidth > MaxBins-2 {
+		// aligning the first bin down adds one more
+		binWidth = span / (MaxBins - 2)
+	}
+	first := math.Floor(d.Min/binWidth) * binWidth
+	bins := int(math.Floor((d.Max-first)/binWidth)) + 1
+	d.Histogram = make([]Bin, bins)
+	for i := range d.Histogram {
+		d.Histogram[i].From = first + float64(i)*binWidth
+		d.Histogram[i].To = d.Histogram[i].From + binWidth
+	}
+	for _, v := range values {
+		d.Histogram[min(int((v-first)/binWidth), bins-1)].Count++
+	}
+
+	return d
+}
+
+// percentile interpolates linearly between the closest ranks of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
@@ -0,0 +1,55 @@
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+func TestComputeBinWidth(t *testing.T) {
+	program := &rating.ProgramData{
+		Data: &rating.ProgramDirection{BudgetMin: 10},
+		Entries: []rating.Entry{
+			{TotalScores: 41, ExamScores: 40},
+			{TotalScores: 75.5, ExamScores: 70},
+			{TotalScores: 100, ExamScores: 98},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		binWidth float64
+		bins     int
+	}{
+		{name: "default", binWidth: 0, bins: 7},
+		{name: "negative", binWidth: -5, bins: 7},
+		{name: "custom", binWidth: 20, bins: 4},
+		{name: "nan", binWidth: math.NaN(), bins: 7},
+		{name: "inf", binWidth: math.Inf(1), bins: 7},
+		{name: "tiny", binWidth: 1e-300, bins: MaxBins},
+		{name: "too narrow", binWidth: 1e-9, bins: MaxBins},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := Compute(program, tt.binWidth)
+			histogram := out.TotalScores.Histogram
+			if len(histogram) > MaxBins {
+				t.Fatalf("got %d bins, want at most %d", len(histogram), MaxBins)
+			}
+			if tt.bins != MaxBins && len(histogram) != tt.bins {
+				t.Errorf("got %d bins, want %d", len(histogram), tt.bins)
+			}
+			count := 0
+			for _, bin := range histogram {
+				count += bin.Count
+			}
+			if count != len(program.Entries) {
+				t.Errorf("histogram counts %d entries, want %d", count, len(program.Entries))
+			}
+			if first, last := histogram[0], histogram[len(histogram)-1]; first.From > 41 || last.To <= 100 {
+				t.Errorf("histogram [%v, %v) doesn't cover the scores", first.From, last.To)
+			}
+		})
+	}
+}
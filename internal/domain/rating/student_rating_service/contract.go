@@ -3,6 +3,7 @@ package sender
 import (
 	"context"
 	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/delivery"
 	"time"
 )
 
@@ -10,24 +11,42 @@ type (
 	sender interface {
 		SendMessage(ctx context.Context, userID int64, content string) error
 	}
+	// history keeps the summary each chat got last time, rules of the recipient are checked against it.
+	history interface {
+		Previous(chatID int64, degree rating.Degree, studentID string) *rating.StudentSummary
+		Record(chatID int64, degree rating.Degree, studentID string, summary *rating.StudentSummary)
+	}
+	// schedule keeps what Notify holds back between calls, see delivery.Store.
+	schedule interface {
+		Period(chatID int64, degree rating.Degree, studentID string) *delivery.Period
+		SetPeriod(chatID int64, degree rating.Degree, studentID string, period *delivery.Period)
+		Enqueue(message delivery.Message)
+		Queued() []delivery.Message
+		Dequeue(chatID int64, degree rating.Degree, studentID string)
+	}
 	parser interface {
 		// GetEntries получение списка студентов в рейтинговом списке на зачисление на данную программу.
 		//
 		// Parameters:
 		//   - ctx: контекст выполнения
+		//   - degree: уровень образования (бакалавриат, магистратура, аспирантура)
+		//   - basis: основа обучения (бюджет или контракт)
 		//   - programID: идентификатор программы (competitive_group_id)
 		//
 		// Returns:
 		//   - entries: список студентов, отсортированный по рейтингу
 		//   - lastUpdate: время последнего обновления рейтинга на сайте
 		//   - error: ошибка получения или парсинга данных
-		GetEntries(ctx context.Context, programID int64) ([]rating.Entry, time.Time, error)
+		GetEntries(ctx context.Context, degree rating.Degree, basis rating.Basis, programID int64) ([]rating.Entry, time.Time, error)
 
-		// GetAllPrograms получение всех доступных программ магистратуры ИТМО.
+		// GetAllPrograms получение всех доступных программ ИТМО заданного уровня образования.
 		//
 		// Returns:
 		//   - programs: список программ с информацией о количестве мест
 		//   - error: ошибка при запросе к API или парсинге ответа
-		GetAllPrograms(ctx context.Context) ([]rating.ProgramDirection, error)
+		GetAllPrograms(ctx context.Context, degree rating.Degree) ([]rating.ProgramDirection, error)
+
+		// ProgramURL ссылка на страницу рейтингового списка программы на сайте, с которого загружаются рейтинги.
+		ProgramURL(degree rating.Degree, basis rating.Basis, programID int) string
 	}
 )
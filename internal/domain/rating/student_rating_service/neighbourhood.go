@@ -0,0 +1,83 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/admission"
+)
+
+// DefaultNeighbours is the number of applicants shown on each side of a student.
+const DefaultNeighbours = 5
+
+// GetNeighbourhood returns up to n applicants above and below the student in a program list. With an empty
+// basis it's the budget list, or the contract one when the student applied only for a contract place.
+func (s *Service) GetNeighbourhood(
+	ctx context.Context,
+	degree rating.Degree,
+	studentID string,
+	programID int,
+	basis rating.Basis,
+	n int,
+) (*rating.Neighbourhood, error) {
+	snap, err := s.snapshot(ctx, degree)
+	if err != nil {
+		return nil, err
+	}
+	studentEntries, ok := snap.students[studentID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", rating.ErrStudentNotFound, studentID)
+	}
+	var self *rating.StudentEntry
+	for i, e := range studentEntries {
+		if e.Program.Data.CompetitiveGroupID != programID || (basis != "" && e.Entry.Basis != basis) {
+			continue
+		}
+		if self == nil || e.Entry.Basis == rating.BasisBudget {
+			self = &studentEntries[i]
+		}
+	}
+	if self == nil {
+		return nil, fmt.Errorf("%w: %d", rating.ErrProgramNotFound, programID)
+	}
+
+	list := self.Program.List(self.Entry.Basis)
+	i := slices.IndexFunc(list, func(e rating.Entry) bool { return e.SSPVOID == studentID })
+	out := &rating.Neighbourhood{
+		StudentID:    studentID,
+		ProgramID:    programID,
+		ProgramTitle: self.Program.Data.DirectionTitle,
+		Basis:        self.Entry.Basis,
+		Seats:        admission.ListSeats(self.Program, self.Entry.Basis),
+	}
+	for _, e := range list[max(i-n, 0):min(i+n+1, len(list))] {
+		neighbour := rating.Neighbour{
+			Position:    e.Position,
+			StudentID:   e.SSPVOID,
+			TotalScores: e.TotalScores,
+			ExamScores:  e.ExamScores,
+			Priority:    e.Priority,
+			Agreement:   e.IsSendAgreement,
+			Self:        e.SSPVOID == studentID,
+		}
+		if assigned, ok := snap.simulation.Assigned[e.SSPVOID]; ok {
+			neighbour.ProjectedProgramID = assigned
+			priority, ok := priorityOf(snap.students[e.SSPVOID], assigned)
+			neighbour.LeavesForHigherPriority = ok && assigned != programID && priority < e.Priority
+		}
+		out.Applicants = append(out.Applicants, neighbour)
+	}
+	return out, nil
+}
+
+// priorityOf is the priority of the student's budget application to a program.
+func priorityOf(entries []rating.StudentEntry, programID int) (int, bool) {
+	for _, e := range entries {
+		if e.Program.Data.CompetitiveGroupID == programID && e.Entry.Basis == rating.BasisBudget {
+			return e.Entry.Priority, true
+		}
+	}
+	return 0, false
+}
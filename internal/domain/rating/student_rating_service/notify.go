@@ -0,0 +1,215 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/delivery"
+	"itmo-ratings/internal/domain/rating/report"
+	"itmo-ratings/internal/domain/rating/rules"
+
+	"github.com/samber/lo"
+)
+
+// Delivery is the outcome of sending a summary to a single recipient.
+type Delivery struct {
+	StudentID string
+	ChatID    int64
+	// Skipped is set when nothing was sent: no rule of the recipient fired, every program is muted
+	// or the digest isn't due yet.
+	Skipped bool
+	// Queued is set when the message waits for the quiet hours of the recipient to end.
+	Queued bool
+	Err    error
+}
+
+// Notify refreshes the ratings of every degree used by subscriptions once and sends each subscribed
+// student's summary to all of their recipients. A failure for one degree, student or recipient does
+// not stop delivery to the others; the returned error is only set when no ratings could be fetched at all.
+// Recipients with rules only get the summary when a rule fires against the summary recorded in history
+// for their chat. A summary is recorded for the next call once the recipient got it, skipped it or has it
+// queued, so a failing chat doesn't hold back the others. Recipients with a digest get the changes once a day instead, and
+// messages falling into quiet hours wait in the schedule queue for a call after them.
+func (s *Service) Notify(ctx context.Context, sender sender, history history, schedule schedule, subscriptions []rating.Subscription) ([]Delivery, error) {
+	degrees := lo.Uniq(lo.Map(subscriptions, func(sub rating.Subscription, _ int) rating.Degree {
+		return sub.Degree
+	}))
+
+	enrichErrs := make(map[rating.Degree]error)
+	for _, degree := range degrees {
+		if err := s.Enrich(ctx, degree); err != nil {
+			enrichErrs[degree] = fmt.Errorf("failed to update %s ratings: %w", degree, err)
+		}
+	}
+	if len(degrees) > 0 && len(enrichErrs) == len(degrees) {
+		return nil, enrichErrs[degrees[0]]
+	}
+
+	now := s.now()
+	var deliveries []Delivery
+	for _, sub := range subscriptions {
+		err := enrichErrs[sub.Degree]
+		var summary *rating.StudentSummary
+		if err == nil {
+			summary, err = s.GetStudentSummaryRaw(ctx, sub.Degree, sub.StudentID)
+		}
+		for _, recipient := range sub.Recipients {
+			d := Delivery{StudentID: sub.StudentID, ChatID: recipient.ChatID, Err: err}
+			if err == nil {
+				previous := history.Previous(recipient.ChatID, sub.Degree, sub.StudentID)
+				d.Skipped, d.Queued, d.Err = deliver(ctx, sender, schedule, sub, recipient, previous, summary, now)
+				// a failed recipient keeps the old baseline, so its rules fire again on the next call
+				if d.Err == nil {
+					history.Record(recipient.ChatID, sub.Degree, sub.StudentID, summary)
+				}
+			}
+			deliveries = append(deliveries, d)
+		}
+	}
+
+	return append(deliveries, flush(ctx, sender, schedule, subscriptions, now)...), nil
+}
+
+// deliver sends the recipient what is due now, or queues it during quiet hours.
+func deliver(ctx context.Context, sender sender, schedule schedule, sub rating.Subscription, recipient rating.Recipient,
+	previous, summary *rating.StudentSummary, now time.Time) (skipped, queued bool, err error) {
+	var message string
+	var period *delivery.Period
+	if recipient.Schedule.Digest != nil {
+		message, period, err = digest(schedule, sub, recipient, summary, now)
+	} else {
+		message, err = immediate(recipient, previous, summary)
+	}
+	if err != nil || message == "" {
+		return err == nil, false, err
+	}
+
+	if recipient.Schedule.IsQuiet(now.In(recipient.Location())) {
+		schedule.Enqueue(delivery.Message{ChatID: recipient.ChatID, Degree: sub.Degree, StudentID: sub.StudentID, Text: message, QueuedAt: now})
+		queued = true
+	} else {
+		if err := sender.SendMessage(ctx, recipient.ChatID, message); err != nil {
+			return false, false, err
+		}
+		// a queued message about the student is outdated now
+		schedule.Dequeue(recipient.ChatID, sub.Degree, sub.StudentID)
+	}
+	// the digest period only ends once its digest is sent or queued
+	if period != nil {
+		schedule.SetPeriod(recipient.ChatID, sub.Degree, sub.StudentID, period)
+	}
+	return false, queued, nil
+}
+
+// immediate renders the summary in the format chosen by the recipient, the fired rules of the recipient
+// go first. It's empty when the recipient muted every program of the student or when none of their
+// rules fired; without a previous summary rules can't be checked and the summary is rendered.
+func immediate(recipient rating.Recipient, previous, summary *rating.StudentSummary) (string, error) {
+	summary = unmuted(recipient, summary)
+	if summary == nil {
+		return "", nil
+	}
+
+	var message string
+	if len(recipient.Rules) > 0 && previous != nil {
+		triggers := rules.Evaluate(recipient.Rules, previous, summary)
+		if len(triggers) == 0 {
+			return "", nil
+		}
+		reasons, err := report.RenderTriggers(triggers, recipient.Format)
+		if err != nil {
+			return "", err
+		}
+		message = reasons + "\n\n"
+	}
+	rendered, err := report.Render(summary, recipient.Format)
+	if err != nil {
+		return "", err
+	}
+	return message + strings.TrimLeft(rendered, "\n"), nil
+}
+
+// digest adds the summary to the digest period of the recipient. Once the digest time has passed since
+// the start of the period it renders the changes and returns the next period, the digest is empty when
+// every program is muted or none of the rules fired against the start of the period.
+func digest(schedule schedule, sub rating.Subscription, recipient rating.Recipient, summary *rating.StudentSummary,
+	now time.Time) (string, *delivery.Period, error) {
+	period := schedule.Period(recipient.ChatID, sub.Degree, sub.StudentID)
+	if period == nil {
+		schedule.SetPeriod(recipient.ChatID, sub.Degree, sub.StudentID, delivery.NewPeriod(now, summary))
+		return "", nil, nil
+	}
+	period.Add(summary)
+	location := recipient.Location()
+	if now.In(location).Before(recipient.Schedule.NextDigest(period.Start.In(location))) {
+		schedule.SetPeriod(recipient.ChatID, sub.Degree, sub.StudentID, period)
+		return "", nil, nil
+	}
+
+	next := delivery.NewPeriod(now, summary)
+	visible := unmuted(recipient, summary)
+	if visible == nil || len(recipient.Rules) > 0 && len(rules.Evaluate(recipient.Rules, period.Baseline, visible)) == 0 {
+		schedule.SetPeriod(recipient.ChatID, sub.Degree, sub.StudentID, next)
+		return "", nil, nil
+	}
+	message, err := report.RenderDigest(sub.StudentID, period.Start, period.Changes(visible), recipient.Format)
+	if err != nil {
+		return "", nil, err
+	}
+	return message, next, nil
+}
+
+// unmuted leaves out the programs muted by the recipient, it's nil when every program is muted.
+func unmuted(recipient rating.Recipient, summary *rating.StudentSummary) *rating.StudentSummary {
+	if len(recipient.MutedPrograms) == 0 {
+		return summary
+	}
+	filtered := *summary
+	filtered.Entries = lo.Reject(summary.Entries, func(e rating.StudentSummaryEntry, _ int) bool {
+		return slices.Contains(recipient.MutedPrograms, e.ProgramID)
+	})
+	if len(filtered.Entries) == 0 {
+		return nil
+	}
+	return &filtered
+}
+
+// flush sends the queued messages of recipients whose quiet hours are over. Messages for chats no longer
+// subscribed to the student are dropped, the ones that fail to send stay queued for the next call.
+func flush(ctx context.Context, sender sender, schedule schedule, subscriptions []rating.Subscription, now time.Time) []Delivery {
+	var deliveries []Delivery
+	for _, m := range schedule.Queued() {
+		recipient, ok := findRecipient(subscriptions, m)
+		if !ok {
+			schedule.Dequeue(m.ChatID, m.Degree, m.StudentID)
+			continue
+		}
+		if recipient.Schedule.IsQuiet(now.In(recipient.Location())) {
+			continue
+		}
+		d := Delivery{StudentID: m.StudentID, ChatID: m.ChatID, Err: sender.SendMessage(ctx, m.ChatID, m.Text)}
+		if d.Err == nil {
+			schedule.Dequeue(m.ChatID, m.Degree, m.StudentID)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries
+}
+
+func findRecipient(subscriptions []rating.Subscription, m delivery.Message) (rating.Recipient, bool) {
+	for _, sub := range subscriptions {
+		if sub.Degree != m.Degree || sub.StudentID != m.StudentID {
+			continue
+		}
+		for _, recipient := range sub.Recipients {
+			if recipient.ChatID == m.ChatID {
+				return recipient, true
+			}
+		}
+	}
+	return rating.Recipient{}, false
+}
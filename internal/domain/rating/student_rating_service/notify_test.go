@@ -0,0 +1,80 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/delivery"
+	"itmo-ratings/internal/domain/rating/rules"
+	"itmo-ratings/internal/itmotest"
+)
+
+// chatSender fails the chats in failing, the others get their messages.
+type chatSender struct {
+	failing map[int64]bool
+	sent    map[int64]int
+}
+
+func (s *chatSender) SendMessage(_ context.Context, chatID int64, _ string) error {
+	if s.failing[chatID] {
+		return errors.New("outbox is not writable")
+	}
+	s.sent[chatID]++
+	return nil
+}
+
+func TestNotifyRecordsHistoryPerRecipient(t *testing.T) {
+	service, _ := startService(t, itmotest.Generate(itmotest.DefaultGenerateConfig()))
+	history, err := rules.OpenHistory("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule, err := delivery.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	studentID := itmotest.StudentID(0)
+	subscriptions := []rating.Subscription{{
+		Degree:    rating.DegreeMaster,
+		StudentID: studentID,
+		Recipients: []rating.Recipient{
+			{ChatID: 1, Rules: []rating.Rule{{Kind: rating.RuleChange}}},
+			{ChatID: 2, Rules: []rating.Rule{{Kind: rating.RuleChange}}},
+		},
+	}}
+
+	// chat 2 blocked the bot, chat 1 must not get the same summary again because of it
+	sender := &chatSender{failing: map[int64]bool{2: true}, sent: map[int64]int{}}
+	tests := []struct {
+		name     string
+		failing  map[int64]bool
+		sent     map[int64]bool
+		recorded map[int64]bool
+	}{
+		{name: "first", failing: map[int64]bool{2: true}, sent: map[int64]bool{1: true}, recorded: map[int64]bool{1: true}},
+		{name: "unchanged", failing: map[int64]bool{2: true}, recorded: map[int64]bool{1: true}},
+		{name: "unblocked", sent: map[int64]bool{2: true}, recorded: map[int64]bool{1: true, 2: true}},
+	}
+	for _, tt := range tests {
+		sender.failing = tt.failing
+		sender.sent = map[int64]int{}
+		deliveries, err := service.Notify(context.Background(), sender, history, schedule, subscriptions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range deliveries {
+			if failed := d.Err != nil; failed != tt.failing[d.ChatID] {
+				t.Errorf("%s: chat %d: got error %v", tt.name, d.ChatID, d.Err)
+			}
+			if sent := sender.sent[d.ChatID] > 0; sent != tt.sent[d.ChatID] {
+				t.Errorf("%s: chat %d: got %d messages, want sent %t", tt.name, d.ChatID, sender.sent[d.ChatID], tt.sent[d.ChatID])
+			}
+			// a failed recipient must be checked against its old baseline again
+			if recorded := history.Previous(d.ChatID, rating.DegreeMaster, studentID) != nil; recorded != tt.recorded[d.ChatID] {
+				t.Errorf("%s: chat %d: got summary recorded %t", tt.name, d.ChatID, recorded)
+			}
+		}
+	}
+}
@@ -2,128 +2,347 @@ package sender
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"log/slog"
 	"slices"
-	"strings"
 	"sync"
 	"time"
 
 	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/admission"
+	"itmo-ratings/internal/domain/rating/report"
+	"itmo-ratings/internal/domain/rating/stats"
 
 	"github.com/samber/lo"
 )
 
 type Service struct {
-	parser     parser
-	programMap map[int]rating.ProgramData
-	students   map[string][]rating.StudentEntry
-	mu         sync.RWMutex
+	parser  parser
+	degrees []rating.Degree
+	caches  map[rating.Degree]*cache
+	now     func() time.Time
 }
 
-func New(parser parser) *Service {
+// cache holds the ratings of a single degree.
+type cache struct {
+	programMap   map[int]rating.ProgramData
+	students     map[string][]rating.StudentEntry
+	simulation   admission.Result
+	schemaIssues []rating.SchemaIssue
+	lastUpdated  time.Time
+	// application counts of the previous update, the leaderboard shows growth against them;
+	// kept in memory only, so growth starts over after a restart
+	previous        map[int]int
+	previousUpdated time.Time
+	mu              sync.RWMutex
+}
+
+// New creates a service tracking the given degrees, master only when none are given.
+func New(parser parser, degrees ...rating.Degree) *Service {
+	if len(degrees) == 0 {
+		degrees = []rating.Degree{rating.DegreeMaster}
+	}
+
 	s := &Service{
-		parser:     parser,
-		programMap: make(map[int]rating.ProgramData),
-		students:   make(map[string][]rating.StudentEntry),
+		parser:  parser,
+		degrees: degrees,
+		caches:  make(map[rating.Degree]*cache, len(degrees)),
+		now:     time.Now,
+	}
+	for _, degree := range degrees {
+		s.caches[degree] = &cache{
+			programMap: make(map[int]rating.ProgramData),
+			students:   make(map[string][]rating.StudentEntry),
+		}
 	}
 	return s
 }
 
-func (s *Service) Enrich(ctx context.Context) error {
-	programs, err := s.parser.GetAllPrograms(ctx)
+// Degrees returns the tracked degrees.
+func (s *Service) Degrees() []rating.Degree {
+	return s.degrees
+}
+
+func (s *Service) cache(degree rating.Degree) (*cache, error) {
+	c, ok := s.caches[degree]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", rating.ErrDegreeNotTracked, degree)
+	}
+	return c, nil
+}
+
+// EnrichAll refreshes the caches of all tracked degrees.
+func (s *Service) EnrichAll(ctx context.Context) error {
+	var errs []error
+	for _, degree := range s.degrees {
+		if err := s.Enrich(ctx, degree); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", degree, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Service) Enrich(ctx context.Context, degree rating.Degree) error {
+	c, err := s.cache(degree)
+	if err != nil {
+		return err
+	}
+
+	programs, err := s.parser.GetAllPrograms(ctx, degree)
 	if err != nil {
 		return fmt.Errorf("failed to get available programs: %w", err)
 	}
 
 	programMap := make(map[int]rating.ProgramData)
 	students := make(map[string][]rating.StudentEntry)
+	var schemaIssues []rating.SchemaIssue
 
 	for _, program := range programs {
-		entries, lastUpdated, err := s.parser.GetEntries(ctx, int64(program.CompetitiveGroupID))
+		// Don't swap in a partial cache when shutdown interrupts the update
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("update interrupted: %w", err)
+		}
+
+		entries, lastUpdated, issue, err := s.getEntries(ctx, degree, rating.BasisBudget, &program)
+		if issue != nil {
+			schemaIssues = append(schemaIssues, *issue)
+		}
 		if err != nil {
-			slog.Info("failed to get rating entries",
-				"err", err.Error(),
-				"programID", program.CompetitiveGroupID,
-			)
 			continue
 		}
+		general, target := lo.FilterReject(entries, func(v rating.Entry, _ int) bool {
+			return !v.TargetQuota
+		})
 		pd := rating.ProgramData{
-			Data:        &program,
-			Entries:     entries,
-			LastUpdated: lastUpdated,
+			Degree:        degree,
+			Data:          &program,
+			Entries:       general,
+			TargetEntries: target,
+			LastUpdated:   lastUpdated,
+		}
+
+		// a broken contract list shouldn't hide the budget one
+		if program.Contract > 0 {
+			contractEntries, _, issue, err := s.getEntries(ctx, degree, rating.BasisContract, &program)
+			if issue != nil {
+				schemaIssues = append(schemaIssues, *issue)
+			}
+			if err == nil {
+				pd.ContractEntries = lo.Reject(contractEntries, func(v rating.Entry, _ int) bool {
+					return v.TargetQuota
+				})
+			}
 		}
 		programMap[program.CompetitiveGroupID] = pd
 
-		for _, student := range entries {
-			students[student.SSPVOID] = append(students[student.SSPVOID], rating.StudentEntry{
-				StudentID: student.SSPVOID,
-				Entry:     &student,
-				Program:   &pd,
-			})
+		for _, list := range [][]rating.Entry{pd.Entries, pd.ContractEntries} {
+			for _, student := range list {
+				students[student.SSPVOID] = append(students[student.SSPVOID], rating.StudentEntry{
+					StudentID: student.SSPVOID,
+					Entry:     &student,
+					Program:   &pd,
+				})
+			}
 		}
 	}
 
-	s.mu.Lock()
-	s.programMap = programMap
-	s.students = students
-	s.mu.Unlock()
+	simulation := admission.Simulate(programMap)
+
+	c.mu.Lock()
+	if len(c.programMap) > 0 {
+		c.previous = make(map[int]int, len(c.programMap))
+		for id, program := range c.programMap {
+			c.previous[id] = len(program.Entries)
+		}
+		c.previousUpdated = c.lastUpdated
+	}
+	c.programMap = programMap
+	c.students = students
+	c.simulation = simulation
+	c.schemaIssues = schemaIssues
+	c.lastUpdated = time.Now()
+	c.mu.Unlock()
 	return nil
 }
 
-func (s *Service) getStudents(ctx context.Context) map[string][]rating.StudentEntry {
-	s.mu.RLock()
-	students := s.students
-	s.mu.RUnlock()
-	if len(students) == 0 {
-		if err := s.Enrich(ctx); err != nil {
-			slog.Error("failed to update cache", "err", err.Error())
-			return nil
+// getEntries fetches a single admission list. Failures are logged here, schema changes are also
+// returned as an issue for Health.
+func (s *Service) getEntries(
+	ctx context.Context,
+	degree rating.Degree,
+	basis rating.Basis,
+	program *rating.ProgramDirection,
+) ([]rating.Entry, time.Time, *rating.SchemaIssue, error) {
+	entries, lastUpdated, err := s.parser.GetEntries(ctx, degree, basis, int64(program.CompetitiveGroupID))
+	var schemaErr *rating.SchemaChangedError
+	if errors.As(err, &schemaErr) {
+		slog.Error("rating page schema changed",
+			"degree", degree,
+			"basis", basis,
+			"programID", program.CompetitiveGroupID,
+			"problems", schemaErr.Problems,
+		)
+		return nil, time.Time{}, &rating.SchemaIssue{
+			ProgramID: program.CompetitiveGroupID,
+			Basis:     basis,
+			Title:     program.DirectionTitle,
+			Problems:  schemaErr.Problems,
+		}, err
+	}
+	if err != nil {
+		slog.Info("failed to get rating entries",
+			"err", err.Error(),
+			"degree", degree,
+			"basis", basis,
+			"programID", program.CompetitiveGroupID,
+		)
+		return nil, time.Time{}, nil, err
+	}
+	return entries, lastUpdated, nil, nil
+}
+
+// Health reports the state of each degree cache after its last successful Enrich.
+func (s *Service) Health() []rating.Health {
+	out := make([]rating.Health, 0, len(s.degrees))
+	for _, degree := range s.degrees {
+		c := s.caches[degree]
+		c.mu.RLock()
+		out = append(out, rating.Health{
+			Degree:       degree,
+			LastUpdated:  c.lastUpdated,
+			Programs:     len(c.programMap),
+			SchemaIssues: c.schemaIssues,
+		})
+		c.mu.RUnlock()
+	}
+	return out
+}
+
+// snapshot is a consistent view of a degree cache.
+type snapshot struct {
+	programMap      map[int]rating.ProgramData
+	students        map[string][]rating.StudentEntry
+	simulation      admission.Result
+	previous        map[int]int
+	previousUpdated time.Time
+}
+
+func (c *cache) snapshot() snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return snapshot{
+		programMap:      c.programMap,
+		students:        c.students,
+		simulation:      c.simulation,
+		previous:        c.previous,
+		previousUpdated: c.previousUpdated,
+	}
+}
+
+func (s *Service) snapshot(ctx context.Context, degree rating.Degree) (snapshot, error) {
+	c, err := s.cache(degree)
+	if err != nil {
+		return snapshot{}, err
+	}
+
+	snap := c.snapshot()
+	if len(snap.students) == 0 {
+		if err := s.Enrich(ctx, degree); err != nil {
+			slog.Error("failed to update cache", "degree", degree, "err", err.Error())
+			return snapshot{}, fmt.Errorf("failed to find students")
 		}
-		s.mu.RLock()
-		students = s.students
-		s.mu.RUnlock()
+		snap = c.snapshot()
+	}
+	return snap, nil
+}
+
+// GetPrograms returns the cached lists of all programs of a degree by competitive group ID.
+func (s *Service) GetPrograms(ctx context.Context, degree rating.Degree) (map[int]rating.ProgramData, error) {
+	snap, err := s.snapshot(ctx, degree)
+	if err != nil {
+		return nil, err
+	}
+	return snap.programMap, nil
+}
+
+// GetProgram returns the cached lists of a program.
+func (s *Service) GetProgram(ctx context.Context, degree rating.Degree, programID int) (*rating.ProgramData, error) {
+	snap, err := s.snapshot(ctx, degree)
+	if err != nil {
+		return nil, err
+	}
+	program, ok := snap.programMap[programID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", rating.ErrProgramNotFound, programID)
+	}
+	return &program, nil
+}
+
+// GetProgramStats aggregates the budget list of a program, see stats.Compute.
+func (s *Service) GetProgramStats(
+	ctx context.Context,
+	degree rating.Degree,
+	programID int,
+	binWidth float64,
+) (*stats.ProgramStats, error) {
+	program, err := s.GetProgram(ctx, degree, programID)
+	if err != nil {
+		return nil, err
 	}
-	return students
+	out := stats.Compute(program, binWidth)
+	return &out, nil
 }
 
+// GetLeaderboard ranks all programs of a degree, see stats.ComputeLeaderboard.
+func (s *Service) GetLeaderboard(ctx context.Context, degree rating.Degree, order stats.Order) (*stats.Leaderboard, error) {
+	snap, err := s.snapshot(ctx, degree)
+	if err != nil {
+		return nil, err
+	}
+	out := stats.ComputeLeaderboard(degree, snap.programMap, snap.simulation, snap.previous, order)
+	if snap.previous != nil {
+		out.PreviousUpdated = &snap.previousUpdated
+	}
+	return &out, nil
+}
+
+// GetStudentSummary renders the summary of a student as a Telegram message, see report.Render.
 func (s *Service) GetStudentSummary(
 	ctx context.Context,
+	degree rating.Degree,
 	studentID string,
+	format rating.SummaryFormat,
 ) (string, error) {
-	students := s.getStudents(ctx)
-	if students == nil {
-		return "", fmt.Errorf("failed to find students")
-	}
-	requestedStudentEntries, ok := students[studentID]
-	if !ok {
-		return "", fmt.Errorf("failed to find student in all programs")
+	summary, err := s.GetStudentSummaryRaw(ctx, degree, studentID)
+	if err != nil {
+		return "", err
 	}
-	return studentSummary(requestedStudentEntries), nil
+	return report.Render(summary, format)
 }
 
 func (s *Service) GetStudentSummaryRaw(
 	ctx context.Context,
+	degree rating.Degree,
 	studentID string,
 ) (*rating.StudentSummary, error) {
-	students := s.getStudents(ctx)
-	if students == nil {
-		return nil, fmt.Errorf("failed to find students")
+	snap, err := s.snapshot(ctx, degree)
+	if err != nil {
+		return nil, err
 	}
-	requestedStudentEntries, ok := students[studentID]
+	requestedStudentEntries, ok := snap.students[studentID]
 	if !ok {
-		return nil, fmt.Errorf("failed to find student in all programs")
+		return nil, fmt.Errorf("%w: %s", rating.ErrStudentNotFound, studentID)
 	}
 
-	summary := buildStudentSummary(studentID, requestedStudentEntries)
+	summary := buildStudentSummary(studentID, requestedStudentEntries, snap.simulation, s.parser.ProgramURL)
 	return &summary, nil
 }
 
-func buildStudentSummary(studentID string, data []rating.StudentEntry) rating.StudentSummary {
-	slices.SortFunc(data, func(a, b rating.StudentEntry) int {
-		return a.Entry.Priority - b.Entry.Priority
-	})
+func buildStudentSummary(studentID string, data []rating.StudentEntry, simulation admission.Result,
+	programURL func(rating.Degree, rating.Basis, int) string) rating.StudentSummary {
+	sortStudentEntries(data)
 
 	out := rating.StudentSummary{
 		StudentID: studentID,
@@ -131,64 +350,54 @@ func buildStudentSummary(studentID string, data []rating.StudentEntry) rating.St
 	}
 
 	for _, row := range data {
-		withLowerPriority := lo.Filter(row.Program.Entries, func(v rating.Entry, _ int) bool {
+		list := row.Program.List(row.Entry.Basis)
+		withLowerPriority := lo.Filter(list, func(v rating.Entry, _ int) bool {
 			return v.Priority > row.Entry.Priority && v.Position < row.Entry.Position
 		})
 
-		out.Entries = append(out.Entries, rating.StudentSummaryEntry{
+		summaryEntry := rating.StudentSummaryEntry{
 			Priority:             row.Entry.Priority,
-			Program:              formatProgram(row.Program.Data),
+			ProgramID:            row.Program.Data.CompetitiveGroupID,
+			ProgramTitle:         row.Program.Data.DirectionTitle,
+			ProgramURL:           programURL(row.Program.Degree, row.Entry.Basis, row.Program.Data.CompetitiveGroupID),
+			Basis:                row.Entry.Basis,
 			Position:             row.Entry.Position,
 			BudgetMin:            row.Program.Data.BudgetMin,
-			TotalApplications:    len(row.Program.Entries),
+			Seats:                admission.ListSeats(row.Program, row.Entry.Basis),
+			TotalApplications:    len(list),
 			LowerPriorityAhead:   len(withLowerPriority),
+			LastUpdated:          row.Program.LastUpdated,
 			LastUpdatedFormatted: row.Program.LastUpdated.Format(time.RFC822),
-		})
+		}
+		if row.Entry.Basis == rating.BasisBudget {
+			programID := row.Program.Data.CompetitiveGroupID
+			summaryEntry.ProjectedPass = simulation.Passes(studentID, programID)
+			summaryEntry.ProjectedCutoff = simulation.Cutoffs[programID].Score
+		}
+		out.Entries = append(out.Entries, summaryEntry)
 	}
 
 	return out
 }
 
-func studentSummary(data []rating.StudentEntry) string {
-	slices.SortFunc(data, func(a, b rating.StudentEntry) int {
-		return a.Entry.Priority - b.Entry.Priority
-	})
-
-	msgRow := `
-Приоритет: %d
-Программа: %s
-Позиция: %d / %d (всего подано заявлений: %d)
-Студентов с приоритетов ниже чем у студента: %d
-Последнее обновление: %s
-`
-
-	msgBuilder := strings.Builder{}
-	for _, row := range data {
-		withLowerPriority := lo.Filter(row.Program.Entries, func(v rating.Entry, _ int) bool {
-			if v.Priority > row.Entry.Priority && v.Position < row.Entry.Position {
-				return true
-			}
-			return false
-		})
-
-		msgBuilder.WriteString(fmt.Sprintf(msgRow,
-			row.Entry.Priority,
-			formatProgram(row.Program.Data),
-			row.Entry.Position,
-			row.Program.Data.BudgetMin,
-			len(row.Program.Entries),
-			len(withLowerPriority),
-			row.Program.LastUpdated.Format(time.RFC822),
-		),
-		)
-	}
-
-	return msgBuilder.String()
+// ProgramURL is the rating page of a program list on the site the ratings are loaded from.
+func (s *Service) ProgramURL(degree rating.Degree, basis rating.Basis, programID int) string {
+	return s.parser.ProgramURL(degree, basis, programID)
 }
 
-func formatProgram(program *rating.ProgramDirection) string {
-	if program == nil {
-		return ""
-	}
-	return fmt.Sprintf("[%s](https://abit.itmo.ru/rating/master/budget/%d)", program.DirectionTitle, program.CompetitiveGroupID)
+// sortStudentEntries orders rows by priority, the budget list before the contract one.
+func sortStudentEntries(data []rating.StudentEntry) {
+	slices.SortFunc(data, func(a, b rating.StudentEntry) int {
+		if a.Entry.Priority != b.Entry.Priority {
+			return a.Entry.Priority - b.Entry.Priority
+		}
+		switch {
+		case a.Entry.Basis == b.Entry.Basis:
+			return 0
+		case a.Entry.Basis == rating.BasisBudget:
+			return -1
+		default:
+			return 1
+		}
+	})
 }
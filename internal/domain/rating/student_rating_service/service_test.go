@@ -0,0 +1,152 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/scrapper"
+	"itmo-ratings/internal/itmotest"
+)
+
+func startService(t *testing.T, programs []itmotest.Program) (*Service, *itmotest.Server) {
+	t.Helper()
+	srv := itmotest.Start(t, programs...)
+	return New(scrapper.New(srv.Client(), srv.ScrapperOptions()...)), srv
+}
+
+// applications counts the lists of a student the cache keeps: general competition and contract.
+func applications(programs []itmotest.Program, studentID string) int {
+	n := 0
+	for _, p := range programs {
+		for _, list := range [][]scrapper.RatingEntry{p.General, p.Contract} {
+			for _, e := range list {
+				if e.SSPVO == studentID {
+					n++
+				}
+			}
+		}
+	}
+	return n
+}
+
+func TestEnrich(t *testing.T) {
+	cfg := itmotest.DefaultGenerateConfig()
+	programs := itmotest.Generate(cfg)
+	service, _ := startService(t, programs)
+
+	if err := service.Enrich(context.Background(), rating.DegreeMaster); err != nil {
+		t.Fatalf("Enrich: %v", err)
+	}
+	health := service.Health()[0]
+	if health.Programs != cfg.Programs || len(health.SchemaIssues) != 0 {
+		t.Errorf("got %d programs and issues %v, want %d programs", health.Programs, health.SchemaIssues, cfg.Programs)
+	}
+
+	for _, n := range []int{0, 1, cfg.Students - 1} {
+		studentID := itmotest.StudentID(n)
+		want := applications(programs, studentID)
+		summary, err := service.GetStudentSummaryRaw(context.Background(), rating.DegreeMaster, studentID)
+		if want == 0 {
+			if !errors.Is(err, rating.ErrStudentNotFound) {
+				t.Errorf("student %s: got %v, want ErrStudentNotFound", studentID, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("student %s: %v", studentID, err)
+		}
+		if len(summary.Entries) != want {
+			t.Errorf("student %s: got %d entries, want %d", studentID, len(summary.Entries), want)
+		}
+	}
+
+	program, err := service.GetProgram(context.Background(), rating.DegreeMaster, cfg.FirstProgramID)
+	if err != nil {
+		t.Fatalf("GetProgram: %v", err)
+	}
+	if len(program.Entries) != len(programs[0].General) || len(program.TargetEntries) != len(programs[0].TargetQuota) ||
+		len(program.ContractEntries) != len(programs[0].Contract) {
+		t.Errorf("got %d/%d/%d general/target/contract entries, want %d/%d/%d",
+			len(program.Entries), len(program.TargetEntries), len(program.ContractEntries),
+			len(programs[0].General), len(programs[0].TargetQuota), len(programs[0].Contract))
+	}
+	if !program.LastUpdated.Equal(cfg.UpdateTime) {
+		t.Errorf("got update time %v, want %v", program.LastUpdated, cfg.UpdateTime)
+	}
+}
+
+func TestEnrichFaults(t *testing.T) {
+	cfg := itmotest.DefaultGenerateConfig()
+	budget := itmotest.ProgramPath(rating.DegreeMaster, rating.BasisBudget, cfg.FirstProgramID+1)
+	contract := itmotest.ProgramPath(rating.DegreeMaster, rating.BasisContract, cfg.FirstProgramID+2)
+
+	tests := []struct {
+		name    string
+		path    string
+		fault   itmotest.Fault
+		timeout time.Duration
+		// err is set when Enrich must fail, programs is the number cached otherwise
+		err          bool
+		programs     int
+		schemaIssues int
+	}{
+		{name: "programs API down", path: itmotest.ProgramsPath(), fault: itmotest.Fault{Status: http.StatusServiceUnavailable}, err: true},
+		{name: "programs API malformed", path: itmotest.ProgramsPath(), fault: itmotest.Fault{Body: `{"ok":true,"result":`}, err: true},
+		{name: "programs API slow", path: itmotest.ProgramsPath(), fault: itmotest.Fault{Delay: time.Second}, timeout: 50 * time.Millisecond, err: true},
+		{name: "page down", path: budget, fault: itmotest.Fault{Status: http.StatusInternalServerError}, programs: cfg.Programs - 1},
+		{name: "page without data", path: budget, fault: itmotest.Fault{Body: `<html><body>Технические работы</body></html>`}, programs: cfg.Programs - 1},
+		{name: "page schema changed", path: budget, fault: itmotest.Fault{Body: `<script id="__NEXT_DATA__">{"props":{"pageProps":{"programList":{"general_competition":[]}}}}</script>`},
+			programs: cfg.Programs - 1, schemaIssues: 1},
+		{name: "page slow", path: budget, fault: itmotest.Fault{Status: http.StatusOK, Delay: 20 * time.Millisecond}, programs: cfg.Programs},
+		// the budget list is still shown without the contract one
+		{name: "contract page down", path: contract, fault: itmotest.Fault{Status: http.StatusBadGateway}, programs: cfg.Programs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, srv := startService(t, itmotest.Generate(cfg))
+			srv.InjectFault(tt.path, tt.fault)
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			err := service.Enrich(ctx, rating.DegreeMaster)
+			if tt.err {
+				if err == nil {
+					t.Fatal("Enrich succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Enrich: %v", err)
+			}
+			health := service.Health()[0]
+			if health.Programs != tt.programs || len(health.SchemaIssues) != tt.schemaIssues {
+				t.Errorf("got %d programs and %d schema issues, want %d and %d",
+					health.Programs, len(health.SchemaIssues), tt.programs, tt.schemaIssues)
+			}
+		})
+	}
+}
+
+func TestEnrichKeepsCacheOnFailure(t *testing.T) {
+	cfg := itmotest.DefaultGenerateConfig()
+	service, srv := startService(t, itmotest.Generate(cfg))
+	if err := service.Enrich(context.Background(), rating.DegreeMaster); err != nil {
+		t.Fatalf("Enrich: %v", err)
+	}
+
+	srv.InjectFault(itmotest.ProgramsPath(), itmotest.Fault{Status: http.StatusServiceUnavailable})
+	if err := service.Enrich(context.Background(), rating.DegreeMaster); err == nil {
+		t.Fatal("Enrich succeeded with the programs API down")
+	}
+	if _, err := service.GetStudentSummaryRaw(context.Background(), rating.DegreeMaster, itmotest.StudentID(0)); err != nil {
+		t.Errorf("cached summary lost: %v", err)
+	}
+}
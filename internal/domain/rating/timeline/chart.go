@@ -0,0 +1,56 @@
+package timeline
+
+import (
+	"fmt"
+	"image/color"
+
+	"itmo-ratings/pkg/chart"
+)
+
+var (
+	colorPosition = color.RGBA{0x25, 0x63, 0xeb, 0xff}
+	colorSeats    = color.RGBA{0x9c, 0xa3, 0xaf, 0xff}
+	colorCutoff   = color.RGBA{0xdc, 0x26, 0x26, 0xff}
+	colorScore    = color.RGBA{0x16, 0xa3, 0x4a, 0xff}
+)
+
+// Chart draws the position against the seats and the projected cutoff against the score of the student.
+func (s *Series) Chart(studentID string) *chart.Chart {
+	position := chart.Series{Name: "позиция", Color: colorPosition}
+	seats := chart.Series{Name: "мест", Color: colorSeats, Dashed: true}
+	cutoff := chart.Series{Name: "проходной балл", Color: colorCutoff}
+	score := chart.Series{Name: "баллы студента", Color: colorScore, Dashed: true}
+	for _, p := range s.Points {
+		position.Points = append(position.Points, chart.Point{X: p.Time, Y: float64(p.Position)})
+		seats.Points = append(seats.Points, chart.Point{X: p.Time, Y: float64(p.Seats)})
+		score.Points = append(score.Points, chart.Point{X: p.Time, Y: p.Score})
+		if p.Cutoff > 0 {
+			cutoff.Points = append(cutoff.Points, chart.Point{X: p.Time, Y: p.Cutoff})
+		}
+	}
+
+	return &chart.Chart{
+		Title: fmt.Sprintf("%s — %s", studentID, s.ProgramTitle),
+		Panels: []chart.Panel{
+			{Title: "Позиция в списке", Series: []chart.Series{seats, position}, Invert: true},
+			{Title: "Проходной балл (прогноз)", Series: []chart.Series{score, cutoff}},
+		},
+	}
+}
+
+// Caption describes the chart in text, PNG charts have no legend.
+func (s *Series) Caption(studentID string) string {
+	caption := fmt.Sprintf("%s — %s, приоритет %d\nСверху: позиция (синяя линия) и количество мест (серый пунктир).\n"+
+		"Снизу: прогноз проходного балла (красная линия) и баллы студента (зелёный пунктир).",
+		studentID, s.ProgramTitle, s.Priority)
+	if n := len(s.Points); n > 0 {
+		last := s.Points[n-1]
+		caption += fmt.Sprintf("\nСейчас: позиция %d из %d мест", last.Position, last.Seats)
+		if last.Passes {
+			caption += ", проходит"
+		} else {
+			caption += ", не проходит"
+		}
+	}
+	return caption
+}
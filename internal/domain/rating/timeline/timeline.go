@@ -0,0 +1,237 @@
+// Package timeline follows the budget applications of a student through the saved snapshots.
+package timeline
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/admission"
+	"itmo-ratings/internal/domain/rating/snapshot"
+)
+
+// DefaultMaxPoints bounds the snapshots read for a timeline, older history is sampled evenly.
+const DefaultMaxPoints = 48
+
+// Point is the state of a budget application in a snapshot.
+type Point struct {
+	Time         time.Time `json:"time"`
+	Position     int       `json:"position"`
+	Seats        int       `json:"seats"`
+	Applications int       `json:"applications"`
+	Score        float64   `json:"score"`
+	// Cutoff is the projected cutoff of the program, zero while it isn't full.
+	Cutoff float64 `json:"cutoff"`
+	Passes bool    `json:"passes"`
+}
+
+// Series is the history of the application to one program.
+type Series struct {
+	ProgramID    int     `json:"programId"`
+	ProgramTitle string  `json:"programTitle"`
+	Priority     int     `json:"priority"`
+	Points       []Point `json:"points"`
+}
+
+type Timeline struct {
+	Degree    rating.Degree `json:"degree"`
+	StudentID string        `json:"studentId"`
+	Series    []Series      `json:"series"`
+}
+
+type store interface {
+	Manifest() (*snapshot.Manifest, error)
+	Load(ref string) (*snapshot.Snapshot, error)
+}
+
+type Option func(*Builder)
+
+func WithMaxPoints(n int) Option {
+	return func(b *Builder) {
+		if n > 1 {
+			b.maxPoints = n
+		}
+	}
+}
+
+// Builder reads snapshots once and keeps the compact per student data of the sampled ones.
+type Builder struct {
+	store     store
+	maxPoints int
+
+	mu     sync.Mutex
+	frames map[string]*frame // by snapshot ID
+}
+
+// frame is what a snapshot contributes to timelines.
+type frame struct {
+	createdAt time.Time
+	degrees   map[rating.Degree]*degreeFrame
+}
+
+type degreeFrame struct {
+	titles   map[int]string
+	students map[string][]application
+}
+
+type application struct {
+	programID int
+	priority  int
+	point     Point
+}
+
+func New(store store, options ...Option) *Builder {
+	b := &Builder{
+		store:     store,
+		maxPoints: DefaultMaxPoints,
+		frames:    make(map[string]*frame),
+	}
+	for _, option := range options {
+		option(b)
+	}
+	return b
+}
+
+// Student returns the history of every budget application of the student, ordered by priority.
+func (b *Builder) Student(degree rating.Degree, studentID string) (*Timeline, error) {
+	frames, err := b.load()
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Timeline{Degree: degree, StudentID: studentID}
+	series := make(map[int]*Series)
+	for _, f := range frames {
+		df, ok := f.degrees[degree]
+		if !ok {
+			continue
+		}
+		for _, app := range df.students[studentID] {
+			s, ok := series[app.programID]
+			if !ok {
+				s = &Series{ProgramID: app.programID}
+				series[app.programID] = s
+			}
+			// the latest title and priority win, both can change during the campaign
+			s.ProgramTitle = df.titles[app.programID]
+			s.Priority = app.priority
+			s.Points = append(s.Points, app.point)
+		}
+	}
+	if len(series) == 0 {
+		return nil, fmt.Errorf("%w: %s", rating.ErrStudentNotFound, studentID)
+	}
+
+	for _, s := range series {
+		out.Series = append(out.Series, *s)
+	}
+	slices.SortFunc(out.Series, func(a, b Series) int {
+		if a.Priority != b.Priority {
+			return a.Priority - b.Priority
+		}
+		return a.ProgramID - b.ProgramID
+	})
+	return out, nil
+}
+
+// Program returns the history of the application of the student to a program.
+func (b *Builder) Program(degree rating.Degree, studentID string, programID int) (*Series, error) {
+	timeline, err := b.Student(degree, studentID)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(timeline.Series, func(s Series) bool { return s.ProgramID == programID })
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %d", rating.ErrProgramNotFound, programID)
+	}
+	return &timeline.Series[i], nil
+}
+
+// load returns the frames of the sampled snapshots in time order, reading the ones not cached yet.
+func (b *Builder) load() ([]*frame, error) {
+	m, err := b.store.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	infos := slices.DeleteFunc(slices.Clone(m.Snapshots), func(info snapshot.Info) bool {
+		return !slices.ContainsFunc(info.Files, func(f snapshot.File) bool { return f.Format == snapshot.FormatNDJSON })
+	})
+	infos = sample(infos, b.maxPoints)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	frames := make([]*frame, 0, len(infos))
+	keep := make(map[string]*frame, len(infos))
+	for _, info := range infos {
+		f, ok := b.frames[info.ID]
+		if !ok {
+			snap, err := b.store.Load(info.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load snapshot %s: %w", info.ID, err)
+			}
+			f = newFrame(snap)
+		}
+		keep[info.ID] = f
+		frames = append(frames, f)
+	}
+	// frames that are no longer sampled would only grow the memory
+	b.frames = keep
+	return frames, nil
+}
+
+// sample keeps n snapshots evenly spread over the history, always including the first and the last one.
+func sample(infos []snapshot.Info, n int) []snapshot.Info {
+	if len(infos) <= n {
+		return infos
+	}
+	out := make([]snapshot.Info, 0, n)
+	for i := range n {
+		out = append(out, infos[i*(len(infos)-1)/(n-1)])
+	}
+	return out
+}
+
+func newFrame(snap *snapshot.Snapshot) *frame {
+	f := &frame{
+		createdAt: snap.CreatedAt,
+		degrees:   make(map[rating.Degree]*degreeFrame),
+	}
+	for degree, programs := range snapshot.Programs(snap.Rows) {
+		simulation := admission.Simulate(programs)
+		df := &degreeFrame{
+			titles:   make(map[int]string, len(programs)),
+			students: make(map[string][]application),
+		}
+		for id, program := range programs {
+			df.titles[id] = program.Data.DirectionTitle
+			cutoff := simulation.Cutoffs[id]
+			seats := admission.Seats(&program).Effective
+			for _, e := range program.Entries {
+				if e.SSPVOID == "" {
+					continue
+				}
+				point := Point{
+					Time:         snap.CreatedAt,
+					Position:     e.Position,
+					Seats:        seats,
+					Applications: len(program.Entries),
+					Score:        e.TotalScores,
+					Passes:       simulation.Passes(e.SSPVOID, id),
+				}
+				if cutoff.Full {
+					point.Cutoff = cutoff.Score
+				}
+				df.students[e.SSPVOID] = append(df.students[e.SSPVOID], application{
+					programID: id,
+					priority:  e.Priority,
+					point:     point,
+				})
+			}
+		}
+		f.degrees[degree] = df
+	}
+	return f
+}
@@ -2,9 +2,15 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"itmo-ratings/pkg/tgmarkup"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -37,18 +43,145 @@ func New(apiToken string, options ...Option) *Bot {
 	}
 }
 
+// SendMessage sends content in the HTML parse mode of tgmarkup. Content longer than the Telegram limit
+// is sent as several messages, a part whose markup Telegram rejects is resent as plain text.
 func (b *Bot) SendMessage(ctx context.Context, userID int64, content string) error {
+	return b.SendKeyboard(ctx, userID, content, nil)
+}
+
+// SendKeyboard sends content like SendMessage with the keyboard under its last part.
+func (b *Bot) SendKeyboard(ctx context.Context, userID int64, content string, keyboard Keyboard) error {
 	if userID == 0 {
 		return fmt.Errorf("invalid userID: %v", userID)
 	}
 	if content == "" {
 		return fmt.Errorf("invalid message content must be not empty string")
 	}
-	msg := tgbotapi.NewMessage(userID, content)
-	msg.ParseMode = tgbotapi.ModeMarkdown
+	parts := tgmarkup.Split(content, tgmarkup.MaxLength)
+	for i, part := range parts {
+		msg := tgbotapi.NewMessage(userID, part)
+		msg.ParseMode = tgmarkup.ParseMode
+		if i == len(parts)-1 && len(keyboard) > 0 {
+			msg.ReplyMarkup = keyboard.markup()
+		}
+		_, err := b.BotAPI.Send(msg)
+		if isEntitiesError(err) {
+			slog.Warn("telegram rejected message markup, sending plain text", "userID", userID, "err", err.Error())
+			msg.Text, msg.ParseMode = tgmarkup.Plain(part), ""
+			_, err = b.BotAPI.Send(msg)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to send message to user: %w", err)
+		}
+	}
+	return nil
+}
+
+// EditMessage replaces the text and keyboard of a sent message. Telegram can't make a message longer
+// than the limit, so content is cut to the first part SendMessage would send.
+func (b *Bot) EditMessage(ctx context.Context, chatID int64, messageID int, content string, keyboard Keyboard) error {
+	part := tgmarkup.Split(content, tgmarkup.MaxLength)[0]
+	msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, part, *keyboard.markup())
+	msg.ParseMode = tgmarkup.ParseMode
 	_, err := b.BotAPI.Send(msg)
+	if isEntitiesError(err) {
+		slog.Warn("telegram rejected message markup, sending plain text", "chatID", chatID, "err", err.Error())
+		msg.Text, msg.ParseMode = tgmarkup.Plain(part), ""
+		_, err = b.BotAPI.Send(msg)
+	}
+	// pressing refresh when nothing changed
+	if isAPIError(err, "message is not modified") {
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to send message to user: %w", err)
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+	return nil
+}
+
+// AnswerCallback confirms a pressed button, a non-empty text is shown to the user as a notification.
+func (b *Bot) AnswerCallback(ctx context.Context, callbackID string, text string) error {
+	if _, err := b.BotAPI.Request(tgbotapi.NewCallback(callbackID, text)); err != nil {
+		return fmt.Errorf("failed to answer callback: %w", err)
+	}
+	return nil
+}
+
+// isEntitiesError reports whether Telegram failed to parse the markup of a message.
+func isEntitiesError(err error) bool {
+	return isAPIError(err, "can't parse entities")
+}
+
+// isAPIError reports whether Telegram rejected a request as bad with a description containing text.
+func isAPIError(err error, text string) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == 400 && strings.Contains(apiErr.Message, text)
+}
+
+// RetryAfter is the wait Telegram asked for when it rate limited the request.
+func RetryAfter(err error) (time.Duration, bool) {
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusTooManyRequests && apiErr.RetryAfter > 0 {
+		return time.Duration(apiErr.RetryAfter) * time.Second, true
+	}
+	return 0, false
+}
+
+// IsPermanent reports whether Telegram rejected the request for a reason a retry won't fix,
+// like a deleted chat or a user who blocked the bot.
+func IsPermanent(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && (apiErr.Code == http.StatusBadRequest || apiErr.Code == http.StatusForbidden)
+}
+
+// File is an attachment sent from memory.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// SendPhoto sends an image shown inline in the chat, caption is plain text.
+func (b *Bot) SendPhoto(ctx context.Context, userID int64, photo File, caption string) error {
+	if userID == 0 {
+		return fmt.Errorf("invalid userID: %v", userID)
+	}
+	msg := tgbotapi.NewPhoto(userID, tgbotapi.FileBytes{Name: photo.Name, Bytes: photo.Data})
+	msg.Caption = caption
+	if _, err := b.BotAPI.Send(msg); err != nil {
+		return fmt.Errorf("failed to send photo to user: %w", err)
 	}
 	return nil
 }
+
+// SendDocument sends a file as an attachment, for formats Telegram doesn't preview as photos.
+func (b *Bot) SendDocument(ctx context.Context, userID int64, document File, caption string) error {
+	if userID == 0 {
+		return fmt.Errorf("invalid userID: %v", userID)
+	}
+	msg := tgbotapi.NewDocument(userID, tgbotapi.FileBytes{Name: document.Name, Bytes: document.Data})
+	msg.Caption = caption
+	if _, err := b.BotAPI.Send(msg); err != nil {
+		return fmt.Errorf("failed to send document to user: %w", err)
+	}
+	return nil
+}
+
+// Listen long-polls Telegram and passes every update to handle until ctx is cancelled.
+func (b *Bot) Listen(ctx context.Context, handle func(context.Context, tgbotapi.Update)) {
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+	updates := b.BotAPI.GetUpdatesChan(u)
+	defer b.BotAPI.StopReceivingUpdates()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			handle(ctx, update)
+		}
+	}
+}
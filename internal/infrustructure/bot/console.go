@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"itmo-ratings/pkg/tgmarkup"
+)
+
+// Console prints messages instead of sending them, for offline runs without a Telegram token.
+type Console struct {
+	w io.Writer
+}
+
+func NewConsole(w io.Writer) *Console {
+	return &Console{w: w}
+}
+
+// SendMessage prints the message as plain text, one block per part Telegram would receive.
+func (c *Console) SendMessage(_ context.Context, userID int64, content string) error {
+	for _, part := range tgmarkup.Split(content, tgmarkup.MaxLength) {
+		if _, err := fmt.Fprintf(c.w, "--- message to %d ---\n%s\n", userID, tgmarkup.Plain(part)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Console) SendKeyboard(ctx context.Context, userID int64, content string, keyboard Keyboard) error {
+	if err := c.SendMessage(ctx, userID, content); err != nil {
+		return err
+	}
+	return c.printKeyboard(keyboard)
+}
+
+func (c *Console) EditMessage(_ context.Context, chatID int64, messageID int, content string, keyboard Keyboard) error {
+	part := tgmarkup.Split(content, tgmarkup.MaxLength)[0]
+	if _, err := fmt.Fprintf(c.w, "--- edit message %d in %d ---\n%s\n", messageID, chatID, tgmarkup.Plain(part)); err != nil {
+		return err
+	}
+	return c.printKeyboard(keyboard)
+}
+
+func (c *Console) AnswerCallback(_ context.Context, callbackID string, text string) error {
+	_, err := fmt.Fprintf(c.w, "--- answer callback %s ---\n%s\n", callbackID, text)
+	return err
+}
+
+// printKeyboard writes a row of buttons per line as [text → data].
+func (c *Console) printKeyboard(keyboard Keyboard) error {
+	for _, row := range keyboard {
+		var line strings.Builder
+		for _, button := range row {
+			fmt.Fprintf(&line, "[%s → %s] ", button.Text, button.Data)
+		}
+		if _, err := fmt.Fprintln(c.w, strings.TrimSpace(line.String())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Console) SendPhoto(_ context.Context, userID int64, photo File, caption string) error {
+	_, err := fmt.Fprintf(c.w, "--- photo %s (%d bytes) to %d ---\n%s\n", photo.Name, len(photo.Data), userID, caption)
+	return err
+}
+
+func (c *Console) SendDocument(_ context.Context, userID int64, document File, caption string) error {
+	_, err := fmt.Fprintf(c.w, "--- document %s (%d bytes) to %d ---\n%s\n", document.Name, len(document.Data), userID, caption)
+	return err
+}
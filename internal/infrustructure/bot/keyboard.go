@@ -0,0 +1,26 @@
+package bot
+
+import tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+// Button of an inline keyboard, Data comes back in the callback query when it's pressed.
+// Telegram limits Data to 64 bytes.
+type Button struct {
+	Text string
+	Data string
+}
+
+// Keyboard is an inline keyboard shown under a message, rows of buttons.
+type Keyboard [][]Button
+
+func (k Keyboard) markup() *tgbotapi.InlineKeyboardMarkup {
+	// an empty markup removes the keyboard of an edited message
+	markup := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	for _, row := range k {
+		buttons := make([]tgbotapi.InlineKeyboardButton, 0, len(row))
+		for _, button := range row {
+			buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(button.Text, button.Data))
+		}
+		markup.InlineKeyboard = append(markup.InlineKeyboard, buttons)
+	}
+	return &markup
+}
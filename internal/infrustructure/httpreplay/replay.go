@@ -0,0 +1,143 @@
+package httpreplay
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"itmo-ratings/pkg/fsutil"
+)
+
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Recorder passes requests to the wrapped client and saves every successful response body to dir.
+// Bodies over maxSize are read only up to the limit and not saved.
+type Recorder struct {
+	next    Doer
+	dir     string
+	maxSize int64
+}
+
+func NewRecorder(next Doer, dir string, maxSize int64) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create record directory: %w", err)
+	}
+	return &Recorder{next: next, dir: dir, maxSize: maxSize}, nil
+}
+
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, r.maxSize+1))
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(content))
+	// the caller sees the body over the limit and rejects it as it would without recording
+	if int64(len(content)) > r.maxSize {
+		return resp, nil
+	}
+
+	if err := fsutil.WriteFileAtomic(filepath.Join(r.dir, FileName(req.URL)), content, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to record response for %s: %w", req.URL, err)
+	}
+
+	return resp, nil
+}
+
+// Replayer serves responses previously saved by Recorder. Requests without a recording get 404.
+type Replayer struct {
+	dir string
+}
+
+func NewReplayer(dir string) (*Replayer, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("replay path %s is not a directory", dir)
+	}
+	return &Replayer{dir: dir}, nil
+}
+
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(filepath.Join(r.dir, FileName(req.URL)))
+	if os.IsNotExist(err) {
+		return response(req, http.StatusNotFound, nil), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded response for %s: %w", req.URL, err)
+	}
+
+	return response(req, http.StatusOK, content), nil
+}
+
+// FileName returns the name of the file holding the recorded response for u.
+func FileName(u *url.URL) string {
+	name := u.Host + u.EscapedPath()
+	if u.RawQuery != "" {
+		name += "?" + u.RawQuery
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '.', r == '-', r == '=':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+func response(req *http.Request, status int, content []byte) *http.Response {
+	header := make(http.Header)
+	if content != nil {
+		header.Set("Content-Type", http.DetectContentType(content))
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(content)),
+		ContentLength: int64(len(content)),
+		Request:       req,
+	}
+}
+
+// NewClient picks the client for the scrapper: a replayer when replayDir is set,
+// a recorder around next when recordDir is set, otherwise next itself. maxSize is the page size
+// limit of the scrapper.
+func NewClient(next Doer, recordDir, replayDir string, maxSize int64) (Doer, error) {
+	switch {
+	case replayDir != "":
+		return NewReplayer(replayDir)
+	case recordDir != "":
+		return NewRecorder(next, recordDir, maxSize)
+	default:
+		return next, nil
+	}
+}
@@ -0,0 +1,104 @@
+package httpreplay
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/scrapper"
+	"itmo-ratings/internal/itmotest"
+)
+
+func TestRecordReplay(t *testing.T) {
+	cfg := itmotest.DefaultGenerateConfig()
+	cfg.Programs, cfg.Students = 2, 20
+	programs := itmotest.Generate(cfg)
+	srv := itmotest.Start(t, programs...)
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	recorder, err := NewRecorder(srv.Client(), dir, scrapper.DefaultMaxPageSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := scrapper.New(recorder, srv.ScrapperOptions()...)
+	directions, err := live.GetAllPrograms(ctx, rating.DegreeMaster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, updated, err := live.GetEntries(ctx, rating.DegreeMaster, rating.BasisBudget, int64(cfg.FirstProgramID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the replay doesn't reach the server, the same URLs map to the recorded files
+	srv.Close()
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := scrapper.New(replayer, srv.ScrapperOptions()...)
+	replayedDirections, err := replay.GetAllPrograms(ctx, rating.DegreeMaster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(replayedDirections, directions) {
+		t.Errorf("replayed programs differ from the recorded ones")
+	}
+	replayedEntries, replayedUpdated, err := replay.GetEntries(ctx, rating.DegreeMaster, rating.BasisBudget, int64(cfg.FirstProgramID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(replayedEntries, entries) || !replayedUpdated.Equal(updated) {
+		t.Errorf("replayed entries differ from the recorded ones")
+	}
+
+	// the other program was never recorded
+	if _, _, err := replay.GetEntries(ctx, rating.DegreeMaster, rating.BasisBudget, int64(cfg.FirstProgramID+1)); err == nil {
+		t.Error("missing recording replayed without an error")
+	}
+}
+
+func TestReplayerMissing(t *testing.T) {
+	replayer, err := NewReplayer(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://abit.itmo.ru/rating/master/budget/1", nil)
+	resp, err := replayer.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("got status %d, want 404", resp.StatusCode)
+	}
+
+	if _, err := NewReplayer(t.TempDir() + "/missing"); err == nil {
+		t.Error("missing replay directory accepted")
+	}
+}
+
+func TestRecorderLimit(t *testing.T) {
+	cfg := itmotest.DefaultGenerateConfig()
+	cfg.Programs, cfg.Students = 1, 20
+	srv := itmotest.Start(t, itmotest.Generate(cfg)...)
+	dir := t.TempDir()
+
+	const limit = 1024
+	recorder, err := NewRecorder(srv.Client(), dir, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := scrapper.New(recorder, append(srv.ScrapperOptions(), scrapper.WithMaxPageSize(limit))...)
+	_, _, err = live.GetEntries(context.Background(), rating.DegreeMaster, rating.BasisBudget, int64(cfg.FirstProgramID))
+	if !errors.Is(err, scrapper.ErrPageTooLarge) {
+		t.Errorf("got %v, want %v", err, scrapper.ErrPageTooLarge)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("recorded %d files of a page over the limit", len(files))
+	}
+}
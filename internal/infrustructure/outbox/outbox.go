@@ -0,0 +1,406 @@
+// Package outbox keeps outgoing Telegram notifications in a JSON file until they are delivered.
+// Notifications are written before the first attempt, so a failed or interrupted run loses nothing:
+// Deliver retries them with exponential backoff, in this run or the next one. A text over the Telegram
+// limit is sent in parts, a retry resumes from the first part not delivered.
+package outbox
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"itmo-ratings/internal/infrustructure/bot"
+	"itmo-ratings/pkg/fsutil"
+	"itmo-ratings/pkg/tgmarkup"
+)
+
+const (
+	DefaultBackoff     = 5 * time.Second
+	DefaultMaxBackoff  = 30 * time.Minute
+	DefaultMaxAttempts = 10
+	// DefaultRetention is how long delivered and failed notifications are kept for inspection and replay.
+	DefaultRetention = 7 * 24 * time.Hour
+
+	fileVersion = 1
+)
+
+// State is the delivery state of a notification.
+type State string
+
+const (
+	StatePending State = "pending"
+	StateSent    State = "sent"
+	// StateFailed notifications ran out of attempts or were rejected for good, they wait for a replay.
+	StateFailed State = "failed"
+)
+
+func States() []State {
+	return []State{StatePending, StateSent, StateFailed}
+}
+
+type Notification struct {
+	// ID is the hash of the chat and the text, a pending notification absorbs the same one added again.
+	ID            string    `json:"id"`
+	ChatID        int64     `json:"chatId"`
+	Text          string    `json:"text"`
+	State         State     `json:"state"`
+	CreatedAt     time.Time `json:"createdAt"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"nextAttemptAt,omitzero"`
+	LastError     string    `json:"lastError,omitempty"`
+	SentAt        time.Time `json:"sentAt,omitzero"`
+	// SentParts counts the parts of a text over the Telegram limit already delivered, a retry resumes after them.
+	SentParts int `json:"sentParts,omitempty"`
+}
+
+// parts are the messages the text is sent as.
+func (n Notification) parts() []string {
+	return tgmarkup.Split(n.Text, tgmarkup.MaxLength)
+}
+
+// ContentID is the ID of a notification with the text for the chat.
+func ContentID(chatID int64, text string) string {
+	sum := sha256.Sum256([]byte(strconv.FormatInt(chatID, 10) + "\n" + text))
+	return hex.EncodeToString(sum[:8])
+}
+
+type sender interface {
+	SendMessage(ctx context.Context, userID int64, content string) error
+}
+
+type file struct {
+	Version       int            `json:"version"`
+	Notifications []Notification `json:"notifications"`
+}
+
+// Outbox is safe for concurrent use, every change is written to the file right away.
+type Outbox struct {
+	path          string
+	backoff       time.Duration
+	maxBackoff    time.Duration
+	maxAttempts   int
+	retention     time.Duration
+	now           func() time.Time
+	mu            sync.Mutex
+	notifications []Notification
+}
+
+type Option func(*Outbox)
+
+// WithBackoff sets the delay after the first failed attempt, it doubles with every next one up to max.
+func WithBackoff(initial, max time.Duration) Option {
+	return func(o *Outbox) {
+		o.backoff, o.maxBackoff = initial, max
+	}
+}
+
+func WithMaxAttempts(n int) Option {
+	return func(o *Outbox) {
+		o.maxAttempts = n
+	}
+}
+
+// Open reads the outbox from path, empty when the file doesn't exist yet. With an empty path
+// notifications are kept in memory only and are lost when the process exits.
+func Open(path string, options ...Option) (*Outbox, error) {
+	o := &Outbox{
+		path:        path,
+		backoff:     DefaultBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+		maxAttempts: DefaultMaxAttempts,
+		retention:   DefaultRetention,
+		now:         time.Now,
+	}
+	for _, option := range options {
+		option(o)
+	}
+	if path == "" {
+		return o, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox: %w", err)
+	}
+	var f file
+	if err := json.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse outbox %s: %w", path, err)
+	}
+	o.notifications = f.Notifications
+	return o, nil
+}
+
+// SendMessage adds the message to the outbox instead of sending it, Deliver sends it later.
+// A message still waiting in the outbox for the chat is not added again.
+func (o *Outbox) SendMessage(_ context.Context, chatID int64, content string) error {
+	_, _, err := o.Add(chatID, content)
+	return err
+}
+
+// Add writes a pending notification, it reports false with the existing notification when the same one
+// is still pending. A sent or failed notification with the same content is replaced by the new one.
+func (o *Outbox) Add(chatID int64, text string) (Notification, bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	id := ContentID(chatID, text)
+	previous := slices.Clone(o.notifications)
+	if i := o.index(id); i >= 0 {
+		if o.notifications[i].State == StatePending {
+			return o.notifications[i], false, nil
+		}
+		o.notifications = slices.Delete(o.notifications, i, i+1)
+	}
+	n := Notification{ID: id, ChatID: chatID, Text: text, State: StatePending, CreatedAt: o.now()}
+	o.notifications = append(o.notifications, n)
+	if err := o.save(); err != nil {
+		o.notifications = previous
+		return Notification{}, false, err
+	}
+	return n, true, nil
+}
+
+// List returns the notifications in the order they were added, all of them when no state is given.
+func (o *Outbox) List(states ...State) []Notification {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var list []Notification
+	for _, n := range o.notifications {
+		if len(states) == 0 || slices.Contains(states, n.State) {
+			list = append(list, n)
+		}
+	}
+	return list
+}
+
+// Replay makes the notifications pending again with fresh attempts, sent ones are sent once more.
+// An ID may be a prefix of the full one. It returns the replayed notifications.
+func (o *Outbox) Replay(ids ...string) ([]Notification, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var indexes []int
+	for _, id := range ids {
+		i, err := o.find(id)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, i)
+	}
+	var replayed []Notification
+	for _, i := range indexes {
+		n := &o.notifications[i]
+		n.State, n.Attempts, n.NextAttemptAt, n.LastError, n.SentAt, n.SentParts = StatePending, 0, time.Time{}, "", time.Time{}, 0
+		replayed = append(replayed, *n)
+	}
+	return replayed, o.save()
+}
+
+// Result counts the notifications by their state after Deliver.
+type Result struct {
+	Sent    int
+	Failed  int
+	Pending int
+}
+
+// Deliver sends the pending notifications whose next attempt is due. A failed attempt is retried after
+// an exponentially growing delay, or after the delay Telegram asked for when it rate limited the bot.
+// It waits for retries due within maxWait and leaves the later ones to the next call.
+// Sent and Failed count the notifications delivered or given up on by this call.
+func (o *Outbox) Deliver(ctx context.Context, sender sender, maxWait time.Duration) (Result, error) {
+	deadline := o.now().Add(maxWait)
+	var result Result
+	for {
+		for _, n := range o.due() {
+			if ctx.Err() != nil {
+				break
+			}
+			state, err := o.attempt(ctx, sender, n)
+			if err != nil {
+				return result, err
+			}
+			switch state {
+			case StateSent:
+				result.Sent++
+			case StateFailed:
+				result.Failed++
+			}
+		}
+
+		next, ok := o.nextAttempt()
+		result.Pending = len(o.List(StatePending))
+		if !ok || next.After(deadline) || ctx.Err() != nil {
+			return result, nil
+		}
+		select {
+		case <-ctx.Done():
+			return result, nil
+		case <-time.After(next.Sub(o.now())):
+		}
+	}
+}
+
+// attempt sends the parts of a notification not delivered yet and records the outcome, it returns
+// an empty state when the notification isn't due anymore. Every delivered part is saved right away,
+// so a retry never sends it twice.
+func (o *Outbox) attempt(ctx context.Context, sender sender, n Notification) (State, error) {
+	o.mu.Lock()
+	i := o.index(n.ID)
+	due := i >= 0 && o.notifications[i].State == StatePending && !o.notifications[i].NextAttemptAt.After(o.now())
+	if due {
+		n = o.notifications[i]
+	}
+	o.mu.Unlock()
+	if !due {
+		return "", nil
+	}
+
+	var sendErr error
+	for part, parts := n.SentParts, n.parts(); part < len(parts); part++ {
+		if sendErr = sender.SendMessage(ctx, n.ChatID, parts[part]); sendErr != nil {
+			break
+		}
+		if err := o.update(n.ID, func(stored *Notification) { stored.SentParts = part + 1 }); err != nil {
+			return "", err
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if i = o.index(n.ID); i < 0 {
+		return "", fmt.Errorf("notification %s left the outbox while being sent", n.ID)
+	}
+	stored := &o.notifications[i]
+	now := o.now()
+	if sendErr == nil {
+		stored.Attempts++
+		stored.State, stored.SentAt, stored.LastError, stored.NextAttemptAt = StateSent, now, "", time.Time{}
+		return StateSent, o.save()
+	}
+
+	stored.LastError = sendErr.Error()
+	// Telegram asked to wait, that is not a failed attempt
+	if delay, rateLimited := bot.RetryAfter(sendErr); rateLimited {
+		// the limit is on the bot, other notifications would only be rejected too
+		retryAt := now.Add(delay)
+		for j := range o.notifications {
+			if other := &o.notifications[j]; other.State == StatePending && other.NextAttemptAt.Before(retryAt) {
+				other.NextAttemptAt = retryAt
+			}
+		}
+		slog.Warn("telegram rate limited notifications", "id", n.ID, "chatID", n.ChatID, "retryAfter", delay)
+		return stored.State, o.save()
+	}
+
+	stored.Attempts++
+	if bot.IsPermanent(sendErr) || stored.Attempts >= o.maxAttempts {
+		stored.State, stored.NextAttemptAt = StateFailed, time.Time{}
+		slog.Error("failed to deliver notification, giving up", "id", n.ID, "chatID", n.ChatID, "attempts", stored.Attempts, "err", sendErr)
+	} else {
+		stored.NextAttemptAt = now.Add(o.delay(stored.Attempts))
+		slog.Warn("failed to deliver notification, will retry", "id", n.ID, "chatID", n.ChatID, "attempts", stored.Attempts, "retryAt", stored.NextAttemptAt, "err", sendErr)
+	}
+	return stored.State, o.save()
+}
+
+// update changes a notification and saves the outbox.
+func (o *Outbox) update(id string, change func(*Notification)) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	i := o.index(id)
+	if i < 0 {
+		return fmt.Errorf("notification %s left the outbox while being sent", id)
+	}
+	change(&o.notifications[i])
+	return o.save()
+}
+
+// delay is the backoff after the given number of failed attempts.
+func (o *Outbox) delay(attempts int) time.Duration {
+	d := o.backoff
+	for i := 1; i < attempts && d < o.maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, o.maxBackoff)
+}
+
+// due returns the pending notifications whose next attempt has come.
+func (o *Outbox) due() []Notification {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := o.now()
+	var due []Notification
+	for _, n := range o.notifications {
+		if n.State == StatePending && !n.NextAttemptAt.After(now) {
+			due = append(due, n)
+		}
+	}
+	return due
+}
+
+// nextAttempt is the earliest next attempt of the pending notifications.
+func (o *Outbox) nextAttempt() (time.Time, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var next time.Time
+	found := false
+	for _, n := range o.notifications {
+		if n.State == StatePending && (!found || n.NextAttemptAt.Before(next)) {
+			next, found = n.NextAttemptAt, true
+		}
+	}
+	return next, found
+}
+
+func (o *Outbox) index(id string) int {
+	return slices.IndexFunc(o.notifications, func(n Notification) bool { return n.ID == id })
+}
+
+// find looks a notification up by its ID or an unambiguous prefix of it.
+func (o *Outbox) find(prefix string) (int, error) {
+	found := -1
+	for i, n := range o.notifications {
+		if len(prefix) > 0 && len(prefix) <= len(n.ID) && n.ID[:len(prefix)] == prefix {
+			if found >= 0 {
+				return -1, fmt.Errorf("notification ID %q is ambiguous", prefix)
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return -1, fmt.Errorf("notification %q not found", prefix)
+	}
+	return found, nil
+}
+
+// save drops the delivered and failed notifications past retention and writes the file.
+func (o *Outbox) save() error {
+	cutoff := o.now().Add(-o.retention)
+	o.notifications = slices.DeleteFunc(o.notifications, func(n Notification) bool {
+		return n.State != StatePending && n.CreatedAt.Before(cutoff)
+	})
+	if o.path == "" {
+		return nil
+	}
+	content, err := json.MarshalIndent(file{Version: fileVersion, Notifications: o.notifications}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(o.path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	return nil
+}
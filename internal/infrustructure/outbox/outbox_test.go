@@ -0,0 +1,217 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"itmo-ratings/pkg/tgmarkup"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeSender records the delivered messages and fails with errs in turn, then succeeds.
+type fakeSender struct {
+	errs []error
+	sent []string
+}
+
+func (f *fakeSender) SendMessage(_ context.Context, _ int64, content string) error {
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	f.sent = append(f.sent, content)
+	return nil
+}
+
+func open(t *testing.T, options ...Option) (*Outbox, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	o, err := Open(path, append([]Option{WithBackoff(time.Millisecond, 4*time.Millisecond)}, options...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o, path
+}
+
+func TestDeliverRetries(t *testing.T) {
+	tests := []struct {
+		name    string
+		errs    []error
+		result  Result
+		state   State
+		attempt int
+	}{
+		{name: "sent", result: Result{Sent: 1}, state: StateSent, attempt: 1},
+		{name: "retried", errs: []error{errors.New("connection reset"), errors.New("timeout")}, result: Result{Sent: 1}, state: StateSent, attempt: 3},
+		{name: "blocked", errs: []error{&tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}},
+			result: Result{Failed: 1}, state: StateFailed, attempt: 1},
+		{name: "out of attempts", errs: []error{errors.New("a"), errors.New("b"), errors.New("c")}, result: Result{Failed: 1}, state: StateFailed, attempt: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, path := open(t, WithMaxAttempts(3))
+			if _, _, err := o.Add(1, "Приоритет: 1"); err != nil {
+				t.Fatal(err)
+			}
+			result, err := o.Deliver(context.Background(), &fakeSender{errs: tt.errs}, time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.result {
+				t.Errorf("got %+v, want %+v", result, tt.result)
+			}
+
+			reopened, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n := reopened.List()[0]
+			if n.State != tt.state || n.Attempts != tt.attempt {
+				t.Errorf("saved %s after %d attempts, want %s after %d", n.State, n.Attempts, tt.state, tt.attempt)
+			}
+		})
+	}
+}
+
+func TestDeliverRateLimited(t *testing.T) {
+	o, _ := open(t)
+	o.Add(1, "first")
+	o.Add(2, "second")
+
+	start := time.Now()
+	limited := &tgbotapi.Error{Code: 429, Message: "Too Many Requests", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 30}}
+	result, err := o.Deliver(context.Background(), &fakeSender{errs: []error{limited}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Pending: 2}) {
+		t.Errorf("got %+v, want both pending", result)
+	}
+	// the limit is on the bot, so the second one waits as well
+	for _, n := range o.List() {
+		if n.NextAttemptAt.Before(start.Add(30 * time.Second)) {
+			t.Errorf("notification to %d retries at %v, before retry_after", n.ChatID, n.NextAttemptAt)
+		}
+	}
+}
+
+func TestDeliverRateLimitedIsNotAnAttempt(t *testing.T) {
+	o, _ := open(t, WithMaxAttempts(1))
+	o.Add(1, "first")
+
+	limited := &tgbotapi.Error{Code: 429, Message: "Too Many Requests", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 30}}
+	result, err := o.Deliver(context.Background(), &fakeSender{errs: []error{limited, limited}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{Pending: 1}) {
+		t.Errorf("got %+v, want the notification pending", result)
+	}
+	if n := o.List()[0]; n.State != StatePending || n.Attempts != 0 {
+		t.Errorf("got %s after %d attempts, want pending without attempts", n.State, n.Attempts)
+	}
+}
+
+func TestDeliverResumesParts(t *testing.T) {
+	var programs []string
+	for i := range 60 {
+		programs = append(programs, fmt.Sprintf("Приоритет: %d\nПрограмма: «Нейротехнологии и программная инженерия»\n%s", i+1, strings.Repeat("Позиция: 12 / 26 ", 5)))
+	}
+	text := strings.Join(programs, "\n\n")
+	parts := tgmarkup.Split(text, tgmarkup.MaxLength)
+	if len(parts) < 3 {
+		t.Fatalf("text is split into %d parts, want at least 3", len(parts))
+	}
+
+	o, path := open(t)
+	o.Add(1, text)
+	sender := &fakeSender{errs: []error{nil, errors.New("connection reset")}}
+	if _, err := o.Deliver(context.Background(), sender, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent) != 1 || o.List()[0].SentParts != 1 {
+		t.Fatalf("sent %d parts and saved %d, want the first one", len(sender.sent), o.List()[0].SentParts)
+	}
+
+	// the next run continues from the file
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	result, err := reopened.Deliver(context.Background(), sender, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Sent != 1 {
+		t.Errorf("got %+v, want the notification sent", result)
+	}
+	if strings.Join(sender.sent, "\n\n") != strings.Join(parts, "\n\n") || len(sender.sent) != len(parts) {
+		t.Errorf("sent %d parts, want each of the %d once", len(sender.sent), len(parts))
+	}
+}
+
+func TestReplay(t *testing.T) {
+	o, _ := open(t)
+	n, _, _ := o.Add(1, "text")
+	o.Deliver(context.Background(), &fakeSender{errs: []error{&tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"}}}, 0)
+
+	if _, err := o.Replay("zz"); err == nil {
+		t.Error("replayed an unknown ID")
+	}
+	replayed, err := o.Replay(n.ID[:4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed) != 1 || replayed[0].State != StatePending || replayed[0].Attempts != 0 {
+		t.Fatalf("got %+v, want the notification pending again", replayed)
+	}
+	result, _ := o.Deliver(context.Background(), &fakeSender{}, 0)
+	if result.Sent != 1 {
+		t.Errorf("got %+v, want the replayed notification sent", result)
+	}
+}
+
+func TestAddDeduplicatesPending(t *testing.T) {
+	o, _ := open(t)
+	first, added, _ := o.Add(1, "Приоритет: 1")
+	if !added {
+		t.Fatal("first notification not added")
+	}
+	if _, added, _ := o.Add(1, "Приоритет: 1"); added {
+		t.Error("pending duplicate added")
+	}
+	if _, added, _ := o.Add(2, "Приоритет: 1"); !added {
+		t.Error("same text for another chat not added")
+	}
+	if len(o.List()) != 2 {
+		t.Fatalf("got %d notifications, want 2", len(o.List()))
+	}
+
+	tests := []struct {
+		name string
+		errs []error
+	}{
+		{name: "after sent"},
+		{name: "after failed", errs: []error{&tgbotapi.Error{Code: 403, Message: "Forbidden"}}},
+	}
+	for _, tt := range tests {
+		o.Deliver(context.Background(), &fakeSender{errs: tt.errs}, 0)
+		n, added, err := o.Add(1, "Приоритет: 1")
+		if err != nil || !added || n.ID != first.ID || n.State != StatePending || n.Attempts != 0 {
+			t.Errorf("%s: got %+v added=%v err=%v, want a fresh pending notification", tt.name, n, added, err)
+		}
+		if got := len(o.List()); got != 2 {
+			t.Errorf("%s: got %d notifications, want the old one replaced", tt.name, got)
+		}
+	}
+}
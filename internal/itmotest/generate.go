@@ -0,0 +1,128 @@
+package itmotest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/scrapper"
+	"itmo-ratings/internal/infrustructure/ptr"
+)
+
+type GenerateConfig struct {
+	Seed   uint64
+	Degree rating.Degree
+	// Programs is the number of programs, competitive group IDs start at FirstProgramID.
+	Programs       int
+	FirstProgramID int
+	// Students is the size of the applicant pool shared by all programs.
+	Students int
+	// MaxApplications is the maximum number of programs a student applies to.
+	MaxApplications int
+	BudgetSeats     int
+	TargetSeats     int
+	UpdateTime      time.Time
+}
+
+// DefaultGenerateConfig is a small deterministic campaign.
+func DefaultGenerateConfig() GenerateConfig {
+	return GenerateConfig{
+		Seed:            1,
+		Degree:          rating.DegreeMaster,
+		Programs:        5,
+		FirstProgramID:  1000,
+		Students:        200,
+		MaxApplications: 3,
+		BudgetSeats:     20,
+		TargetSeats:     2,
+		UpdateTime:      time.Date(2025, time.July, 26, 17, 43, 0, 0, time.FixedZone("MSK", 3*60*60)),
+	}
+}
+
+// StudentID returns the SSPVO id of the n-th generated student.
+func StudentID(n int) string {
+	return strconv.Itoa(4000000 + n)
+}
+
+// Generate builds programs with applicants. A student applies to up to MaxApplications
+// distinct programs with priorities 1..k and keeps the same scores everywhere.
+func Generate(cfg GenerateConfig) []Program {
+	rnd := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed))
+
+	programs := make([]Program, cfg.Programs)
+	for i := range programs {
+		programs[i] = Program{
+			Degree: cfg.Degree,
+			Direction: scrapper.ProgramDirection{
+				DirectionTitle:     fmt.Sprintf("09.04.%02d «Тестовая программа_%d»", i+1, i+1),
+				BudgetMin:          cfg.BudgetSeats,
+				Contract:           cfg.BudgetSeats / 2,
+				TargetReception:    cfg.TargetSeats,
+				CompetitiveGroupID: cfg.FirstProgramID + i,
+			},
+			UpdateTime: cfg.UpdateTime,
+		}
+	}
+	if cfg.Programs == 0 {
+		return programs
+	}
+
+	contest := "общий конкурс"
+	for n := range cfg.Students {
+		exam := float64(40 + rnd.IntN(61))
+		achievements := float64(rnd.IntN(11))
+		applications := 1 + rnd.IntN(max(1, min(cfg.MaxApplications, cfg.Programs)))
+		for priority, idx := range rnd.Perm(cfg.Programs)[:applications] {
+			entry := scrapper.RatingEntry{
+				Contest:         ptr.To(contest),
+				ExamType:        ptr.To("ВИ"),
+				DiplomaAverage:  3 + rnd.Float64()*2,
+				Priority:        priority + 1,
+				IAScores:        achievements,
+				ExamScores:      exam,
+				TotalScores:     exam + achievements,
+				IsSendAgreement: rnd.IntN(3) == 0,
+				SSPVO:           StudentID(n),
+				CaseNumber:      strconv.Itoa(100000 + n),
+				Status:          ptr.To("Подано"),
+				MainTopPriority: priority == 0,
+			}
+			switch {
+			case cfg.TargetSeats > 0 && rnd.IntN(20) == 0:
+				programs[idx].TargetQuota = append(programs[idx].TargetQuota, entry)
+			case programs[idx].Direction.Contract > 0 && rnd.IntN(4) == 0:
+				programs[idx].Contract = append(programs[idx].Contract, entry)
+			default:
+				programs[idx].General = append(programs[idx].General, entry)
+			}
+		}
+	}
+
+	for i := range programs {
+		rank(programs[i].General)
+		rank(programs[i].TargetQuota)
+		rank(programs[i].Contract)
+	}
+
+	return programs
+}
+
+// rank orders entries by total score like the ITMO list and assigns positions.
+func rank(entries []scrapper.RatingEntry) {
+	slices.SortStableFunc(entries, func(a, b scrapper.RatingEntry) int {
+		switch {
+		case a.TotalScores > b.TotalScores:
+			return -1
+		case a.TotalScores < b.TotalScores:
+			return 1
+		default:
+			return 0
+		}
+	})
+	for i := range entries {
+		entries[i].Position = i + 1
+	}
+}
@@ -0,0 +1,279 @@
+// Package itmotest runs a local imitation of the ITMO rating site and API
+// so that the scrapper, Enrich and HTTP handlers can be exercised without network.
+//
+// Point the scrapper at the server with scrapper.WithBaseURLs(srv.URL, srv.URL)
+// or use Server.ScrapperOptions.
+package itmotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/scrapper"
+)
+
+const ProgramsAPIPath = "/api/v1/rating/directions"
+
+type Program struct {
+	// Degree of the admission list, master when empty.
+	Degree      rating.Degree
+	Direction   scrapper.ProgramDirection
+	General     []scrapper.RatingEntry
+	TargetQuota []scrapper.RatingEntry
+	// Contract is served as the general competition of the contract page.
+	Contract   []scrapper.RatingEntry
+	UpdateTime time.Time
+}
+
+// Fault changes how the server answers requests to a single path.
+type Fault struct {
+	// Status is sent instead of 200 when not zero.
+	Status int
+	// Delay is waited before answering or until the client goes away.
+	Delay time.Duration
+	// Body replaces the generated payload, e.g. to serve malformed JSON or HTML.
+	Body string
+	// Times limits the fault to the first n matching requests, zero means always.
+	Times int
+}
+
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	programs []Program
+	faults   map[string]*Fault
+	requests map[string]int
+}
+
+// New starts a server serving programs. Close it when done.
+func New(programs ...Program) *Server {
+	s := &Server{
+		programs: programs,
+		faults:   make(map[string]*Fault),
+		requests: make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+ProgramsAPIPath, s.servePrograms)
+	mux.HandleFunc("GET /rating/{degree}/{basis}/{id}", s.serveProgramPage)
+	s.Server = httptest.NewServer(s.withFaults(mux))
+
+	return s
+}
+
+// Start is New for tests: the server is closed by tb.Cleanup.
+func Start(tb testing.TB, programs ...Program) *Server {
+	tb.Helper()
+	s := New(programs...)
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// ScrapperOptions points scrapper.Service at the server without retry delays.
+func (s *Server) ScrapperOptions() []scrapper.Option {
+	return []scrapper.Option{
+		scrapper.WithBaseURLs(s.URL, s.URL),
+		scrapper.WithRetries(1, 0),
+		scrapper.WithTimeout(10 * time.Second),
+	}
+}
+
+// SetPrograms replaces the served programs.
+func (s *Server) SetPrograms(programs ...Program) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.programs = programs
+}
+
+// InjectFault makes requests to path misbehave, see ProgramsPath and ProgramPath.
+func (s *Server) InjectFault(path string, fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[path] = &fault
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.faults)
+}
+
+// Requests returns the number of requests received for path.
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// ProgramsPath is the fault key of the programs API.
+func ProgramsPath() string {
+	return ProgramsAPIPath
+}
+
+// ProgramPath is the fault key of the rating page of a program.
+func ProgramPath(degree rating.Degree, basis rating.Basis, competitiveGroupID int) string {
+	return fmt.Sprintf("/rating/%s/%s/%d", degree, basis, competitiveGroupID)
+}
+
+func (s *Server) withFaults(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path]++
+		var fault Fault
+		if f, ok := s.faults[r.URL.Path]; ok {
+			fault = *f
+			if f.Times > 0 {
+				f.Times--
+				if f.Times == 0 {
+					delete(s.faults, r.URL.Path)
+				}
+			}
+		}
+		s.mu.Unlock()
+
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.Body != "" || fault.Status >= http.StatusBadRequest {
+			if fault.Status != 0 {
+				w.WriteHeader(fault.Status)
+			}
+			_, _ = w.Write([]byte(fault.Body))
+			return
+		}
+		// the handler sets its headers first, they are lost once the status is written
+		if fault.Status != 0 {
+			w = &statusWriter{ResponseWriter: w, status: fault.Status}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusWriter replaces the status written by the handler.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(w.status)
+	return w.ResponseWriter.Write(b)
+}
+
+func (s *Server) servePrograms(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	programs := slices.Clone(s.programs)
+	s.mu.Unlock()
+
+	degree := rating.Degree(r.URL.Query().Get("degree"))
+
+	var response scrapper.ProgramsAPIResponse
+	response.OK = true
+	for _, p := range programs {
+		if p.degree() == degree {
+			response.Result.Items = append(response.Result.Items, p.Direction)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) serveProgramPage(w http.ResponseWriter, r *http.Request) {
+	degree := rating.Degree(r.PathValue("degree"))
+	basis := rating.Basis(r.PathValue("basis"))
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	idx := slices.IndexFunc(s.programs, func(p Program) bool {
+		return p.degree() == degree && p.Direction.CompetitiveGroupID == id
+	})
+	var program Program
+	if idx >= 0 {
+		program = s.programs[idx]
+	}
+	s.mu.Unlock()
+
+	if idx < 0 || (basis != rating.BasisBudget && basis != rating.BasisContract) {
+		http.NotFound(w, r)
+		return
+	}
+
+	payload, err := json.Marshal(nextData(program, basis))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html><html><head><title>%s</title></head><body><div id="__next"></div>`+
+		`<script id="__NEXT_DATA__" type="application/json">%s</script></body></html>`,
+		html.EscapeString(program.Direction.DirectionTitle), payload)
+}
+
+func (p Program) degree() rating.Degree {
+	if p.Degree == "" {
+		return rating.DegreeMaster
+	}
+	return p.Degree
+}
+
+func nextData(p Program, basis rating.Basis) scrapper.RatingsNextJSData {
+	var data scrapper.RatingsNextJSData
+	list := &data.Props.PageProps.ProgramList
+	list.GeneralCompetition = p.General
+	list.ByTargetQuota = p.TargetQuota
+	if basis == rating.BasisContract {
+		list.GeneralCompetition = p.Contract
+		list.ByTargetQuota = nil
+	}
+	list.UpdateTime = p.UpdateTime
+
+	d := p.Direction
+	list.Direction.DirectionTitle = d.DirectionTitle
+	list.Direction.BudgetMin = d.BudgetMin
+	list.Direction.Contract = d.Contract
+	list.Direction.TargetReception = d.TargetReception
+	list.Direction.IsuID = d.IsuID
+	list.Direction.Invalid = d.Invalid
+	list.Direction.SpecialQuota = d.SpecialQuota
+	list.Direction.CompetitiveGroupID = d.CompetitiveGroupID
+
+	// ITMO sends empty lists as [], not null
+	if list.GeneralCompetition == nil {
+		list.GeneralCompetition = []scrapper.RatingEntry{}
+	}
+	if list.ByTargetQuota == nil {
+		list.ByTargetQuota = []scrapper.RatingEntry{}
+	}
+
+	return data
+}
@@ -0,0 +1,85 @@
+package itmotest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+func TestFaults(t *testing.T) {
+	cfg := DefaultGenerateConfig()
+	cfg.Programs, cfg.Students = 1, 10
+	page := ProgramPath(rating.DegreeMaster, rating.BasisBudget, cfg.FirstProgramID)
+
+	tests := []struct {
+		name        string
+		path        string
+		fault       Fault
+		status      int
+		contentType string
+		body        string
+	}{
+		{name: "none", status: http.StatusOK, contentType: "text/html; charset=utf-8", body: "__NEXT_DATA__"},
+		// the server would sniff text/html, the JSON API shows whether the handler headers survived
+		{name: "slow", path: ProgramsPath() + "?degree=master", fault: Fault{Status: http.StatusOK, Delay: 10 * time.Millisecond},
+			status: http.StatusOK, contentType: "application/json", body: "competitive_group_id"},
+		{name: "status only", fault: Fault{Status: http.StatusAccepted}, status: http.StatusAccepted,
+			contentType: "text/html; charset=utf-8", body: "__NEXT_DATA__"},
+		{name: "error", fault: Fault{Status: http.StatusServiceUnavailable}, status: http.StatusServiceUnavailable},
+		{name: "malformed", fault: Fault{Body: "<html><script id=\"__NEXT_DATA__\">{"}, status: http.StatusOK,
+			body: "<html><script"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := page
+			if tt.path != "" {
+				path = tt.path
+			}
+			s := Start(t, Generate(cfg)...)
+			if tt.fault != (Fault{}) {
+				s.InjectFault(strings.Split(path, "?")[0], tt.fault)
+			}
+			resp, err := http.Get(s.URL + path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.contentType != "" && resp.Header.Get("Content-Type") != tt.contentType {
+				t.Errorf("got Content-Type %q, want %q", resp.Header.Get("Content-Type"), tt.contentType)
+			}
+			if !strings.Contains(string(body), tt.body) {
+				t.Errorf("body %.80q doesn't contain %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestFaultTimes(t *testing.T) {
+	s := Start(t, Generate(DefaultGenerateConfig())...)
+	s.InjectFault(ProgramsPath(), Fault{Status: http.StatusBadGateway, Times: 2})
+
+	var statuses []int
+	for range 3 {
+		resp, err := http.Get(s.URL + ProgramsPath() + "?degree=master")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		statuses = append(statuses, resp.StatusCode)
+	}
+	if statuses[0] != http.StatusBadGateway || statuses[1] != http.StatusBadGateway || statuses[2] != http.StatusOK {
+		t.Errorf("got statuses %v, want two faults then 200", statuses)
+	}
+	if n := s.Requests(ProgramsPath()); n != 3 {
+		t.Errorf("got %d requests, want 3", n)
+	}
+}
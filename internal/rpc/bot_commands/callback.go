@@ -0,0 +1,246 @@
+package bot_commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/preferences"
+	"itmo-ratings/internal/domain/rating/report"
+	"itmo-ratings/internal/infrustructure/bot"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Views of a student a summary message switches between with its buttons.
+const (
+	viewSummary    = "s"
+	viewDetails    = "d"
+	viewNeighbours = "n"
+
+	// muteAction prefixes the view to show after muting or unmuting the program
+	muteAction = "m"
+)
+
+const (
+	neighboursSize = 5
+	buttonTitleLen = 28
+)
+
+// callback is the state a button brings back, encoded as "<view>:<degree>:<student>:<program>:<template>"
+// to stay within the 64 bytes Telegram allows. Buttons keep working after restarts as nothing is stored.
+type callback struct {
+	view      string
+	mute      bool
+	degree    rating.Degree
+	studentID string
+	// programID is 0 for the summary view
+	programID int
+	template  string
+}
+
+func (c callback) data() string {
+	view := c.view
+	if c.mute {
+		view = muteAction + view
+	}
+	return fmt.Sprintf("%s:%s:%s:%d:%s", view, c.degree, c.studentID, c.programID, c.template)
+}
+
+// to is the button data of another view.
+func (c callback) to(view string, programID int) string {
+	c.view, c.programID, c.mute = view, programID, false
+	return c.data()
+}
+
+// toggle is the button data muting or unmuting a program and showing the current view again.
+func (c callback) toggle(programID int) string {
+	c.programID, c.mute = programID, true
+	return c.data()
+}
+
+func parseCallback(data string) (callback, error) {
+	parts := strings.Split(data, ":")
+	if len(parts) != 5 {
+		return callback{}, fmt.Errorf("malformed callback data %q", data)
+	}
+	c := callback{view: parts[0], degree: rating.Degree(parts[1]), studentID: parts[2], template: parts[4]}
+	c.view, c.mute = strings.CutPrefix(c.view, muteAction)
+	if !slices.Contains([]string{viewSummary, viewDetails, viewNeighbours}, c.view) {
+		return callback{}, fmt.Errorf("unknown view in callback data %q", data)
+	}
+	var err error
+	if c.programID, err = strconv.Atoi(parts[3]); err != nil {
+		return callback{}, fmt.Errorf("malformed program in callback data %q", data)
+	}
+	return c, nil
+}
+
+// callback handles a pressed button by editing its message to the view the button asks for.
+func (h *Handler) callback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	var notice string
+	// buttons of inline mode messages come without the message, the bot doesn't send those
+	if query.Message != nil {
+		chatID := query.Message.Chat.ID
+		c, err := parseCallback(query.Data)
+		if err == nil && c.mute {
+			notice, err = h.toggleMute(chatID, c)
+		}
+		var text string
+		var keyboard bot.Keyboard
+		if err == nil {
+			text, keyboard, err = h.view(ctx, chatID, c)
+		}
+		if err == nil {
+			err = h.sender.EditMessage(ctx, chatID, query.Message.MessageID, text, keyboard)
+		}
+		if err != nil {
+			notice = errorText(err, "callback", query.Data, "chatID", chatID)
+		}
+	}
+
+	if err := h.sender.AnswerCallback(ctx, query.ID, notice); err != nil {
+		slog.Error("failed to answer callback", "callback", query.Data, "err", err.Error())
+	}
+}
+
+func (h *Handler) view(ctx context.Context, chatID int64, c callback) (string, bot.Keyboard, error) {
+	switch c.view {
+	case viewDetails:
+		return h.detailsView(ctx, chatID, c)
+	case viewNeighbours:
+		return h.neighboursView(ctx, c)
+	default:
+		return h.summaryView(ctx, chatID, c)
+	}
+}
+
+// summaryView is the summary of all programs with a row of buttons per program.
+func (h *Handler) summaryView(ctx context.Context, chatID int64, c callback) (string, bot.Keyboard, error) {
+	summary, err := h.studentSummary(ctx, c.degree, c.studentID)
+	if err != nil {
+		return "", nil, err
+	}
+	text, err := report.Render(summary, h.format(chatID, c.template))
+	if err != nil {
+		return "", nil, err
+	}
+
+	chat := h.preferences.Chat(chatID)
+	keyboard := bot.Keyboard{{{Text: "🔄 Обновить", Data: c.to(viewSummary, 0)}}}
+	var shown []int
+	for _, e := range summary.Entries {
+		if slices.Contains(shown, e.ProgramID) {
+			continue
+		}
+		shown = append(shown, e.ProgramID)
+		keyboard = append(keyboard, []bot.Button{
+			{Text: fmt.Sprintf("%d. %s", e.Priority, shorten(e.ProgramTitle, buttonTitleLen)), Data: c.to(viewDetails, e.ProgramID)},
+			{Text: "👥", Data: c.to(viewNeighbours, e.ProgramID)},
+			{Text: muteIcon(&chat, c, e.ProgramID), Data: c.toggle(e.ProgramID)},
+		})
+	}
+	return text, keyboard, nil
+}
+
+// detailsView is the detailed summary of a single program.
+func (h *Handler) detailsView(ctx context.Context, chatID int64, c callback) (string, bot.Keyboard, error) {
+	summary, err := h.studentSummary(ctx, c.degree, c.studentID)
+	if err != nil {
+		return "", nil, err
+	}
+	program := *summary
+	program.Entries = slices.DeleteFunc(slices.Clone(summary.Entries), func(e rating.StudentSummaryEntry) bool {
+		return e.ProgramID != c.programID
+	})
+	if len(program.Entries) == 0 {
+		return "", nil, usageError(fmt.Sprintf("Студент %s больше не подаёт заявление на программу %d", c.studentID, c.programID))
+	}
+	text, err := report.Render(&program, h.format(chatID, report.TemplateDetailed))
+	if err != nil {
+		return "", nil, err
+	}
+
+	chat := h.preferences.Chat(chatID)
+	muteText := "🔕 Не присылать в сводках"
+	if chat.IsMuted(mutedProgram(c, c.programID)) {
+		muteText = "🔔 Присылать в сводках"
+	}
+	keyboard := bot.Keyboard{
+		{{Text: "« Все программы", Data: c.to(viewSummary, 0)}, {Text: "🔄 Обновить", Data: c.to(viewDetails, c.programID)}},
+		{{Text: "👥 Соседи по списку", Data: c.to(viewNeighbours, c.programID)}, {Text: muteText, Data: c.toggle(c.programID)}},
+	}
+	return text, keyboard, nil
+}
+
+// neighboursView lists the applicants around the student in the program list.
+func (h *Handler) neighboursView(ctx context.Context, c callback) (string, bot.Keyboard, error) {
+	neighbourhood, err := h.rating.GetNeighbourhood(ctx, c.degree, c.studentID, c.programID, "", neighboursSize)
+	switch {
+	case errors.Is(err, rating.ErrDegreeNotTracked):
+		return "", nil, usageError(fmt.Sprintf("Списки %s не отслеживаются", c.degree))
+	case errors.Is(err, rating.ErrStudentNotFound):
+		return "", nil, usageError(fmt.Sprintf("Студент %s не найден в списках %s", c.studentID, c.degree))
+	case errors.Is(err, rating.ErrProgramNotFound):
+		return "", nil, usageError(fmt.Sprintf("Студент %s не подаёт заявление на программу %d", c.studentID, c.programID))
+	case err != nil:
+		return "", nil, err
+	}
+
+	keyboard := bot.Keyboard{{
+		{Text: "« Все программы", Data: c.to(viewSummary, 0)},
+		{Text: "ℹ️ Подробнее", Data: c.to(viewDetails, c.programID)},
+		{Text: "🔄", Data: c.to(viewNeighbours, c.programID)},
+	}}
+	return formatNeighbourhood(neighbourhood), keyboard, nil
+}
+
+func (h *Handler) studentSummary(ctx context.Context, degree rating.Degree, studentID string) (*rating.StudentSummary, error) {
+	summary, err := h.rating.GetStudentSummaryRaw(ctx, degree, studentID)
+	switch {
+	case errors.Is(err, rating.ErrDegreeNotTracked):
+		return nil, usageError(fmt.Sprintf("Списки %s не отслеживаются", degree))
+	case errors.Is(err, rating.ErrStudentNotFound):
+		return nil, usageError(fmt.Sprintf("Студент %s не найден в списках %s", studentID, degree))
+	}
+	return summary, err
+}
+
+func (h *Handler) toggleMute(chatID int64, c callback) (string, error) {
+	var muted bool
+	err := h.preferences.Update(chatID, func(chat *preferences.Chat) {
+		muted = chat.ToggleMute(mutedProgram(c, c.programID))
+	})
+	if err != nil {
+		return "", err
+	}
+	if muted {
+		return fmt.Sprintf("Программы %d не будет в сводках этого чата", c.programID), nil
+	}
+	return fmt.Sprintf("Программа %d снова в сводках этого чата", c.programID), nil
+}
+
+func mutedProgram(c callback, programID int) preferences.Program {
+	return preferences.Program{Degree: c.degree, StudentID: c.studentID, ProgramID: programID}
+}
+
+func muteIcon(chat *preferences.Chat, c callback, programID int) string {
+	if chat.IsMuted(mutedProgram(c, programID)) {
+		return "🔔"
+	}
+	return "🔕"
+}
+
+// shorten cuts s to n runes with an ellipsis.
+func shorten(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
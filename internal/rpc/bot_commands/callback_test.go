@@ -0,0 +1,97 @@
+package bot_commands
+
+import (
+	"testing"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+func TestCallbackData(t *testing.T) {
+	c := callback{view: viewSummary, degree: rating.DegreeMaster, studentID: "4000008", template: "compact"}
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "summary", data: c.data(), want: "s:master:4000008:0:compact"},
+		{name: "details", data: c.to(viewDetails, 1000), want: "d:master:4000008:1000:compact"},
+		{name: "neighbours", data: c.to(viewNeighbours, 1000), want: "n:master:4000008:1000:compact"},
+		{name: "mute on the summary", data: c.toggle(1000), want: "ms:master:4000008:1000:compact"},
+		// leaving a view after muting shows it without muting again
+		{name: "back from mute", data: callback{view: viewDetails, mute: true, programID: 1000}.to(viewSummary, 0), want: "s:::0:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.data != tt.want {
+				t.Errorf("got %q, want %q", tt.data, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallbackDataFits(t *testing.T) {
+	// the longest data a button gets: the longest degree and template, a long student and program id
+	c := callback{view: viewNeighbours, mute: true, degree: rating.DegreePostgraduate, studentID: "1234567890123", programID: 1234567890, template: "detailed"}
+	if data := c.data(); len(data) > 64 {
+		t.Errorf("%q is %d bytes, Telegram allows 64", data, len(data))
+	}
+}
+
+func TestParseCallback(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    callback
+		wantErr bool
+	}{
+		{data: "s:master:4000008:0:", want: callback{view: viewSummary, degree: rating.DegreeMaster, studentID: "4000008"}},
+		{data: "d:bachelor:4000008:1000:table", want: callback{view: viewDetails, degree: rating.DegreeBachelor, studentID: "4000008", programID: 1000, template: "table"}},
+		{data: "mn:master:4000008:1000:compact", want: callback{view: viewNeighbours, mute: true, degree: rating.DegreeMaster, studentID: "4000008", programID: 1000, template: "compact"}},
+		{data: "", wantErr: true},
+		{data: "s:master:4000008:0", wantErr: true},
+		{data: "s:master:4000008:0:compact:extra", wantErr: true},
+		{data: "x:master:4000008:0:", wantErr: true},
+		{data: "m:master:4000008:0:", wantErr: true},
+		{data: "d:master:4000008:abc:", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			got, err := parseCallback(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	// every button parses back to its state
+	c := callback{view: viewDetails, degree: rating.DegreeMaster, studentID: "4000008", programID: 1000, template: "detailed"}
+	for _, data := range []string{c.data(), c.to(viewNeighbours, 1001), c.toggle(1001), c.to(viewSummary, 0)} {
+		parsed, err := parseCallback(data)
+		if err != nil {
+			t.Errorf("parseCallback(%q): %v", data, err)
+			continue
+		}
+		if parsed.data() != data {
+			t.Errorf("%q parsed back to %q", data, parsed.data())
+		}
+	}
+}
+
+func TestShorten(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{s: "Финтех", n: 6, want: "Финтех"},
+		{s: "Нейротехнологии", n: 6, want: "Нейро…"},
+		{s: "", n: 3, want: ""},
+	}
+	for _, tt := range tests {
+		if got := shorten(tt.s, tt.n); got != tt.want {
+			t.Errorf("shorten(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package bot_commands
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/stats"
+	"itmo-ratings/pkg/tgmarkup"
+)
+
+const histogramWidth = 12
+
+func formatStats(st *stats.ProgramStats) string {
+	b := strings.Builder{}
+
+	fmt.Fprintf(&b, "Программа: %s\n", tgmarkup.Escape(st.Title))
+	fmt.Fprintf(&b, "Заявлений: %d, бюджетных мест: %d (с учётом квот: %d)\n", st.Applications, st.BudgetSeats, st.EffectiveSeats)
+	if st.EffectiveSeats > 0 {
+		fmt.Fprintf(&b, "Конкурс: %.1f на место\n", st.CompetitionRatio)
+	}
+	fmt.Fprintf(&b, "Согласий на зачисление: %d (%.0f%%)\n", st.Agreements, st.AgreementShare*100)
+
+	b.WriteString("\nСумма баллов:\n")
+	writeDistribution(&b, st.TotalScores)
+	b.WriteString("\nБаллы за испытания:\n")
+	writeDistribution(&b, st.ExamScores)
+
+	b.WriteString("\nПриоритеты:\n")
+	for _, priority := range slices.Sorted(maps.Keys(st.Priorities)) {
+		fmt.Fprintf(&b, "  %d — %d\n", priority, st.Priorities[priority])
+	}
+	b.WriteString("\nВид испытаний:\n")
+	writeCounts(&b, st.ExamTypes)
+	b.WriteString("\nКонкурс:\n")
+	writeCounts(&b, st.Contests)
+
+	fmt.Fprintf(&b, "\nПоследнее обновление: %s\n", st.LastUpdated.Format(time.RFC822))
+
+	return b.String()
+}
+
+func writeDistribution(b *strings.Builder, d stats.Distribution) {
+	if len(d.Histogram) == 0 {
+		b.WriteString("  нет данных\n")
+		return
+	}
+
+	fmt.Fprintf(b, "  медиана %.1f, p25 %.1f, p75 %.1f, p90 %.1f (от %.1f до %.1f)\n",
+		d.Median, d.Percentiles.P25, d.Percentiles.P75, d.Percentiles.P90, d.Min, d.Max)
+
+	peak := slices.MaxFunc(d.Histogram, func(a, b stats.Bin) int { return a.Count - b.Count }).Count
+	for _, bin := range d.Histogram {
+		bar := 0
+		if peak > 0 {
+			bar = (bin.Count*histogramWidth + peak - 1) / peak
+		}
+		fmt.Fprintf(b, "  %3.0f–%-3.0f %s %d\n", bin.From, bin.To, strings.Repeat("▇", bar), bin.Count)
+	}
+}
+
+func writeCounts(b *strings.Builder, counts map[string]int) {
+	keys := slices.SortedFunc(maps.Keys(counts), func(x, y string) int {
+		return counts[y] - counts[x]
+	})
+	for _, key := range keys {
+		if key == "" {
+			fmt.Fprintf(b, "  не указано — %d\n", counts[key])
+			continue
+		}
+		fmt.Fprintf(b, "  %s — %d\n", tgmarkup.Escape(key), counts[key])
+	}
+}
+
+const leaderboardSize = 15
+
+var orderTitles = map[stats.Order]string{
+	stats.OrderCompetition:   "по конкурсу на бюджетное место",
+	stats.OrderCutoff:        "по прогнозу проходного балла",
+	stats.OrderFirstPriority: "по первым приоритетам на место",
+	stats.OrderGrowth:        "по приросту заявлений",
+}
+
+func formatLeaderboard(lb *stats.Leaderboard, limit int) string {
+	b := strings.Builder{}
+
+	fmt.Fprintf(&b, "Программы %s\n", orderTitles[lb.Order])
+	if lb.PreviousUpdated != nil {
+		fmt.Fprintf(&b, "Прирост с предыдущего обновления, %s\n", lb.PreviousUpdated.Format(time.RFC822))
+	}
+
+	for _, row := range lb.Programs[:min(limit, len(lb.Programs))] {
+		fmt.Fprintf(&b, "\n%d. %s (%d)\n", row.Rank, tgmarkup.Escape(row.Title), row.ProgramID)
+		fmt.Fprintf(&b, "   заявлений %d, мест %d", row.Applications, row.EffectiveSeats)
+		if row.EffectiveSeats > 0 {
+			fmt.Fprintf(&b, ", конкурс %.1f, первых приоритетов %.1f на место", row.ApplicantsPerSeat, row.FirstPriorityPerSeat)
+		}
+		b.WriteString("\n")
+		if row.ProjectedCutoff > 0 {
+			fmt.Fprintf(&b, "   прогноз проходного балла %.0f", row.ProjectedCutoff)
+		} else {
+			b.WriteString("   недобор, проходят все")
+		}
+		if row.Growth != nil {
+			fmt.Fprintf(&b, ", прирост %+d", *row.Growth)
+		}
+		b.WriteString("\n")
+	}
+	if len(lb.Programs) == 0 {
+		b.WriteString("\nнет данных\n")
+	}
+
+	return b.String()
+}
+
+func formatNeighbourhood(n *rating.Neighbourhood) string {
+	b := strings.Builder{}
+
+	basis := "бюджет"
+	if n.Basis == rating.BasisContract {
+		basis = "контракт"
+	}
+	fmt.Fprintf(&b, "Соседи по списку: %s (%d), %s, мест: %d\n\n", tgmarkup.Escape(n.ProgramTitle), n.ProgramID, basis, n.Seats)
+
+	b.WriteString("<pre>\n")
+	fmt.Fprintf(&b, "  %-5s %-5s %-4s %-3s %-4s %s\n", "Место", "Баллы", "ВИ", "Пр", "Согл", "Прогноз")
+	for _, a := range n.Applicants {
+		marker := " "
+		if a.Self {
+			marker = "→"
+		}
+		agreement := "-"
+		if a.Agreement {
+			agreement = "да"
+		}
+		forecast := "-"
+		switch {
+		case a.ProjectedProgramID == n.ProgramID:
+			forecast = "здесь"
+		case a.LeavesForHigherPriority:
+			forecast = fmt.Sprintf("выше: %d", a.ProjectedProgramID)
+		case a.ProjectedProgramID != 0:
+			forecast = fmt.Sprintf("ниже: %d", a.ProjectedProgramID)
+		}
+		fmt.Fprintf(&b, "%s %-5d %-5.0f %-4.0f %-3d %-4s %s\n", marker, a.Position, a.TotalScores, a.ExamScores, a.Priority, agreement, forecast)
+	}
+	b.WriteString("</pre>\n")
+	b.WriteString("Прогноз по бюджету: «здесь» — проходит на эту программу, «выше» — на программу с более высоким приоритетом " +
+		"и место здесь не займёт, «ниже» — на программу с более низким приоритетом.")
+
+	return b.String()
+}
@@ -0,0 +1,397 @@
+package bot_commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/preferences"
+	"itmo-ratings/internal/domain/rating/report"
+	"itmo-ratings/internal/domain/rating/stats"
+	"itmo-ratings/internal/domain/rating/timeline"
+	"itmo-ratings/internal/infrustructure/bot"
+	"itmo-ratings/pkg/tgmarkup"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type ratingService interface {
+	GetStudentSummaryRaw(ctx context.Context, degree rating.Degree, studentID string) (*rating.StudentSummary, error)
+	GetNeighbourhood(ctx context.Context, degree rating.Degree, studentID string, programID int, basis rating.Basis, n int) (*rating.Neighbourhood, error)
+	GetProgramStats(ctx context.Context, degree rating.Degree, programID int, binWidth float64) (*stats.ProgramStats, error)
+	GetLeaderboard(ctx context.Context, degree rating.Degree, order stats.Order) (*stats.Leaderboard, error)
+}
+
+type timelineService interface {
+	Program(degree rating.Degree, studentID string, programID int) (*timeline.Series, error)
+}
+
+type sender interface {
+	SendMessage(ctx context.Context, userID int64, content string) error
+	SendKeyboard(ctx context.Context, userID int64, content string, keyboard bot.Keyboard) error
+	EditMessage(ctx context.Context, chatID int64, messageID int, content string, keyboard bot.Keyboard) error
+	AnswerCallback(ctx context.Context, callbackID string, text string) error
+	SendPhoto(ctx context.Context, userID int64, photo bot.File, caption string) error
+}
+
+// command returns the reply to a command called with args in the chat, in tgmarkup HTML.
+type command func(ctx context.Context, chatID int64, args []string) (string, error)
+
+// keyboardCommand returns a reply with buttons, pressing them edits the reply, see callback.
+type keyboardCommand func(ctx context.Context, chatID int64, args []string) (string, bot.Keyboard, error)
+
+// photoCommand returns an image and its caption, chatID lets it default to the student subscribed in the chat.
+type photoCommand func(ctx context.Context, chatID int64, args []string) (bot.File, string, error)
+
+// usageError is shown to the user as is.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+const helpText = `Команды:
+/summary <id> [template] [degree] — сводка по студенту во всех программах,
+  template: compact, detailed или table, по умолчанию — как в сводках этого чата
+/stats <program_id> [degree] — статистика рейтингового списка программы
+/top [order] [degree] — самые конкурсные программы
+/neighbours <program_id> [id] [degree] — абитуриенты выше и ниже студента в списке программы
+/chart <program_id> [id] [degree] — график позиции и проходного балла по сохранённым снимкам,
+  без id у /neighbours и /chart — для студента, на которого подписан этот чат
+/rules — правила, по которым в чат приходят сводки: /rules add budget, /rules del 1
+/schedule — сводки сразу или дайджестом раз в день, тихие часы
+
+order: competition (конкурс на место, по умолчанию), cutoff (проходной балл),
+first_priority (первые приоритеты на место), growth (прирост заявлений с предыдущего обновления)
+
+degree: bachelor, master (по умолчанию), postgraduate`
+
+// Handler answers bot commands sent to the bot.
+type Handler struct {
+	rating        ratingService
+	sender        sender
+	timeline      timelineService
+	subscriptions []rating.Subscription
+	preferences   *preferences.Store
+
+	commands         map[string]command
+	keyboardCommands map[string]keyboardCommand
+	photoCommands    map[string]photoCommand
+}
+
+type Option func(*Handler)
+
+// WithTimeline enables /chart, it needs the snapshot history.
+func WithTimeline(timeline timelineService) Option {
+	return func(h *Handler) {
+		h.timeline = timeline
+	}
+}
+
+// WithPreferences keeps the programs muted, the rules and the schedules set from the bot in the store, in memory by default.
+func WithPreferences(store *preferences.Store) Option {
+	return func(h *Handler) {
+		h.preferences = store
+	}
+}
+
+// WithSubscriptions lets commands default to the student whose summaries are sent to the chat.
+func WithSubscriptions(subscriptions []rating.Subscription) Option {
+	return func(h *Handler) {
+		h.subscriptions = subscriptions
+	}
+}
+
+func New(rating ratingService, sender sender, options ...Option) *Handler {
+	// an in-memory store can't fail to open
+	memory, _ := preferences.Open("")
+	h := &Handler{
+		rating:      rating,
+		sender:      sender,
+		preferences: memory,
+	}
+	for _, option := range options {
+		option(h)
+	}
+	h.commands = map[string]command{
+		"start":    h.help,
+		"help":     h.help,
+		"stats":    h.stats,
+		"top":      h.top,
+		"rules":    h.rules,
+		"schedule": h.schedule,
+	}
+	h.keyboardCommands = map[string]keyboardCommand{
+		"summary":    h.summary,
+		"neighbours": h.neighbours,
+	}
+	h.photoCommands = map[string]photoCommand{
+		"chart": h.chart,
+	}
+	return h
+}
+
+func (h *Handler) Handle(ctx context.Context, update tgbotapi.Update) {
+	if update.CallbackQuery != nil {
+		h.callback(ctx, update.CallbackQuery)
+		return
+	}
+
+	msg := update.Message
+	if msg == nil || !msg.IsCommand() {
+		return
+	}
+
+	args := strings.Fields(msg.CommandArguments())
+	if photoCmd, ok := h.photoCommands[msg.Command()]; ok {
+		photo, caption, err := photoCmd(ctx, msg.Chat.ID, args)
+		if err == nil {
+			if err := h.sender.SendPhoto(ctx, msg.Chat.ID, photo, caption); err != nil {
+				slog.Error("failed to send bot reply", "command", msg.Command(), "chatID", msg.Chat.ID, "err", err.Error())
+			}
+			return
+		}
+		h.reply(ctx, msg, "", err)
+		return
+	}
+	if keyboardCmd, ok := h.keyboardCommands[msg.Command()]; ok {
+		text, keyboard, err := keyboardCmd(ctx, msg.Chat.ID, args)
+		if err == nil {
+			if err := h.sender.SendKeyboard(ctx, msg.Chat.ID, text, keyboard); err != nil {
+				slog.Error("failed to send bot reply", "command", msg.Command(), "chatID", msg.Chat.ID, "err", err.Error())
+			}
+			return
+		}
+		h.reply(ctx, msg, "", err)
+		return
+	}
+
+	cmd, ok := h.commands[msg.Command()]
+	if !ok {
+		cmd = h.help
+	}
+	reply, err := cmd(ctx, msg.Chat.ID, args)
+	h.reply(ctx, msg, reply, err)
+}
+
+// reply sends the text of a command, or the error when it failed.
+func (h *Handler) reply(ctx context.Context, msg *tgbotapi.Message, reply string, err error) {
+	if err != nil {
+		reply = tgmarkup.Escape(errorText(err, "command", msg.Command(), "chatID", msg.Chat.ID))
+	}
+
+	if err := h.sender.SendMessage(ctx, msg.Chat.ID, reply); err != nil {
+		slog.Error("failed to send bot reply", "command", msg.Command(), "chatID", msg.Chat.ID, "err", err.Error())
+	}
+}
+
+// errorText is shown when a command fails, unexpected errors are logged with logArgs and hidden.
+func errorText(err error, logArgs ...any) string {
+	var usage usageError
+	if errors.As(err, &usage) {
+		return usage.Error()
+	}
+	slog.Error("failed to handle bot command", append(logArgs, "err", err.Error())...)
+	return "Не удалось получить данные, попробуйте позже"
+}
+
+func (h *Handler) help(context.Context, int64, []string) (string, error) {
+	return tgmarkup.Escape(helpText), nil
+}
+
+func (h *Handler) summary(ctx context.Context, chatID int64, args []string) (string, bot.Keyboard, error) {
+	if len(args) == 0 {
+		return "", nil, usageError("Использование: /summary <id> [template] [degree]")
+	}
+	c := callback{view: viewSummary, studentID: args[0]}
+	if _, err := strconv.Atoi(c.studentID); err != nil {
+		return "", nil, usageError("Идентификатор студента должен быть числом")
+	}
+	args = args[1:]
+	if len(args) > 0 && slices.Contains(report.Templates(), args[0]) {
+		c.template = args[0]
+		args = args[1:]
+	}
+	var err error
+	if c.degree, err = parseDegree(args); err != nil {
+		return "", nil, err
+	}
+	return h.summaryView(ctx, chatID, c)
+}
+
+func (h *Handler) neighbours(ctx context.Context, chatID int64, args []string) (string, bot.Keyboard, error) {
+	if len(args) == 0 {
+		return "", nil, usageError("Использование: /neighbours <program_id> [id] [degree]")
+	}
+	programID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "", nil, usageError("Идентификатор программы должен быть числом")
+	}
+	studentID, degree, err := h.student(chatID, "neighbours", args[1:])
+	if err != nil {
+		return "", nil, err
+	}
+	return h.neighboursView(ctx, callback{view: viewNeighbours, degree: degree, studentID: studentID, programID: programID})
+}
+
+func (h *Handler) stats(ctx context.Context, _ int64, args []string) (string, error) {
+	if len(args) == 0 {
+		return "", usageError("Использование: /stats <program_id> [degree]")
+	}
+	programID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "", usageError("Идентификатор программы должен быть числом")
+	}
+	degree, err := parseDegree(args[1:])
+	if err != nil {
+		return "", err
+	}
+
+	programStats, err := h.rating.GetProgramStats(ctx, degree, programID, stats.DefaultBinWidth)
+	switch {
+	case errors.Is(err, rating.ErrDegreeNotTracked):
+		return "", usageError(fmt.Sprintf("Списки %s не отслеживаются", degree))
+	case errors.Is(err, rating.ErrProgramNotFound):
+		return "", usageError(fmt.Sprintf("Программа %d не найдена", programID))
+	case err != nil:
+		return "", err
+	}
+
+	return formatStats(programStats), nil
+}
+
+func (h *Handler) top(ctx context.Context, _ int64, args []string) (string, error) {
+	order := stats.OrderCompetition
+	if len(args) > 0 {
+		if parsed, err := stats.ParseOrder(args[0]); err == nil {
+			order = parsed
+			args = args[1:]
+		}
+	}
+	degree, err := parseDegree(args)
+	if err != nil {
+		return "", err
+	}
+
+	leaderboard, err := h.rating.GetLeaderboard(ctx, degree, order)
+	if errors.Is(err, rating.ErrDegreeNotTracked) {
+		return "", usageError(fmt.Sprintf("Списки %s не отслеживаются", degree))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return formatLeaderboard(leaderboard, leaderboardSize), nil
+}
+
+func (h *Handler) chart(_ context.Context, chatID int64, args []string) (bot.File, string, error) {
+	if h.timeline == nil {
+		return bot.File{}, "", usageError("История рейтингов не сохраняется, графики недоступны")
+	}
+	if len(args) == 0 {
+		return bot.File{}, "", usageError("Использование: /chart <program_id> [id] [degree]")
+	}
+	programID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return bot.File{}, "", usageError("Идентификатор программы должен быть числом")
+	}
+	studentID, degree, err := h.student(chatID, "chart", args[1:])
+	if err != nil {
+		return bot.File{}, "", err
+	}
+
+	series, err := h.timeline.Program(degree, studentID, programID)
+	switch {
+	case errors.Is(err, rating.ErrStudentNotFound):
+		return bot.File{}, "", usageError(fmt.Sprintf("Студента %s нет в сохранённых снимках", studentID))
+	case errors.Is(err, rating.ErrProgramNotFound):
+		return bot.File{}, "", usageError(fmt.Sprintf("Студент %s не подавал заявление на бюджет программы %d", studentID, programID))
+	case err != nil:
+		return bot.File{}, "", err
+	}
+
+	var buf bytes.Buffer
+	if err := series.Chart(studentID).PNG(&buf); err != nil {
+		return bot.File{}, "", fmt.Errorf("failed to render chart: %w", err)
+	}
+	photo := bot.File{Name: fmt.Sprintf("%s-%d.png", studentID, programID), Data: buf.Bytes()}
+	return photo, series.Caption(studentID), nil
+}
+
+// student parses "[id] [degree]" of a program command, without id it's the student whose summaries
+// the chat receives, in the degree of the subscription.
+func (h *Handler) student(chatID int64, command string, args []string) (string, rating.Degree, error) {
+	var studentID string
+	var degree rating.Degree
+	if len(args) > 0 {
+		if _, err := strconv.Atoi(args[0]); err == nil {
+			studentID = args[0]
+			args = args[1:]
+		}
+	}
+	if studentID == "" {
+		sub, err := h.subscription(chatID, command)
+		if err != nil {
+			return "", "", err
+		}
+		studentID, degree = sub.StudentID, sub.Degree
+	}
+	if len(args) > 0 || degree == "" {
+		var err error
+		if degree, err = parseDegree(args); err != nil {
+			return "", "", err
+		}
+	}
+	return studentID, degree, nil
+}
+
+// subscription finds the student whose summaries the chat receives.
+func (h *Handler) subscription(chatID int64, command string) (rating.Subscription, error) {
+	var found []rating.Subscription
+	for _, sub := range h.subscriptions {
+		if slices.ContainsFunc(sub.Recipients, func(r rating.Recipient) bool { return r.ChatID == chatID }) {
+			found = append(found, sub)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return rating.Subscription{}, usageError(fmt.Sprintf("Этот чат не подписан на студента, укажите id: /%s <program_id> <id>", command))
+	case 1:
+		return found[0], nil
+	default:
+		return rating.Subscription{}, usageError(fmt.Sprintf("Чат подписан на нескольких студентов, укажите id: /%s <program_id> <id>", command))
+	}
+}
+
+// format is the summary format configured for the chat, the default one for chats without a subscription.
+// A non-empty template replaces the configured one.
+func (h *Handler) format(chatID int64, template string) rating.SummaryFormat {
+	var format rating.SummaryFormat
+	for _, sub := range h.subscriptions {
+		for _, recipient := range sub.Recipients {
+			if recipient.ChatID == chatID {
+				format = recipient.Format
+			}
+		}
+	}
+	if template != "" {
+		format.Template = template
+	}
+	return format
+}
+
+func parseDegree(args []string) (rating.Degree, error) {
+	if len(args) == 0 {
+		return rating.DegreeMaster, nil
+	}
+	degree, err := rating.ParseDegree(args[0])
+	if err != nil {
+		return "", usageError("Уровень образования: bachelor, master или postgraduate")
+	}
+	return degree, nil
+}
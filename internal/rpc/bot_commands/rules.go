@@ -0,0 +1,159 @@
+package bot_commands
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/preferences"
+	"itmo-ratings/pkg/tgmarkup"
+)
+
+const rulesUsage = `Использование:
+/rules — правила этого чата
+/rules add change [program=<id>] [priority=<n>] — любое изменение позиции или прогноза
+/rules add budget [program=<id>] [priority=<n>] — позиция вошла в бюджетные места или вышла из них
+/rules add ahead <n> [program=<id>] [priority=<n>] — выше с более низким приоритетом стало меньше n
+/rules add cutoff <points> [program=<id>] [priority=<n>] — прогноз проходного балла сдвинулся на points и больше
+/rules del <номер> — удалить правило
+/rules clear — удалить все правила
+
+priority=2 — только программы с приоритетом 1–2`
+
+// rules manages the notification rules of the chat, notify only sends a summary when one of them fires.
+func (h *Handler) rules(_ context.Context, chatID int64, args []string) (string, error) {
+	if len(args) == 0 {
+		return formatRules(h.preferences.Chat(chatID).Rules), nil
+	}
+
+	var update func(*preferences.Chat) error
+	switch args[0] {
+	case "add":
+		rule, err := parseRule(args[1:])
+		if err != nil {
+			return "", err
+		}
+		update = func(c *preferences.Chat) error {
+			if slices.Contains(c.Rules, rule) {
+				return usageError("Такое правило уже есть")
+			}
+			c.Rules = append(c.Rules, rule)
+			return nil
+		}
+	case "del":
+		if len(args) != 2 {
+			return "", usageError(rulesUsage)
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			return "", usageError("Номер правила должен быть числом")
+		}
+		update = func(c *preferences.Chat) error {
+			if n < 1 || n > len(c.Rules) {
+				return usageError(fmt.Sprintf("Правила %d нет", n))
+			}
+			c.Rules = slices.Delete(c.Rules, n-1, n)
+			return nil
+		}
+	case "clear":
+		update = func(c *preferences.Chat) error {
+			c.Rules = nil
+			return nil
+		}
+	default:
+		return "", usageError(rulesUsage)
+	}
+
+	var updateErr error
+	err := h.preferences.Update(chatID, func(c *preferences.Chat) {
+		updateErr = update(c)
+	})
+	if updateErr != nil {
+		return "", updateErr
+	}
+	if err != nil {
+		return "", err
+	}
+	return formatRules(h.preferences.Chat(chatID).Rules), nil
+}
+
+// parseRule parses "<kind> [threshold] [program=<id>] [priority=<n>]".
+func parseRule(args []string) (rating.Rule, error) {
+	if len(args) == 0 {
+		return rating.Rule{}, usageError(rulesUsage)
+	}
+	kind, err := rating.ParseRuleKind(args[0])
+	if err != nil {
+		return rating.Rule{}, usageError("Правила: change, budget, ahead или cutoff")
+	}
+	rule := rating.Rule{Kind: kind}
+	args = args[1:]
+	if kind == rating.RuleAhead || kind == rating.RuleCutoff {
+		if len(args) == 0 {
+			return rating.Rule{}, usageError(fmt.Sprintf("Укажите порог: /rules add %s <число>", kind))
+		}
+		// ParseFloat accepts NaN and Inf, neither is a threshold
+		if rule.Threshold, err = strconv.ParseFloat(args[0], 64); err != nil || !(rule.Threshold > 0) || math.IsInf(rule.Threshold, 1) {
+			return rating.Rule{}, usageError("Порог должен быть положительным числом")
+		}
+		args = args[1:]
+	}
+
+	for _, arg := range args {
+		key, value, _ := strings.Cut(arg, "=")
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return rating.Rule{}, usageError(fmt.Sprintf("Ожидалось program=<id> или priority=<n>, получено %q", arg))
+		}
+		switch key {
+		case "program":
+			rule.ProgramID = n
+		case "priority":
+			rule.MaxPriority = n
+		default:
+			return rating.Rule{}, usageError(fmt.Sprintf("Ожидалось program=<id> или priority=<n>, получено %q", arg))
+		}
+	}
+	if err := rule.Validate(); err != nil {
+		return rating.Rule{}, usageError(err.Error())
+	}
+	return rule, nil
+}
+
+func formatRules(rules []rating.Rule) string {
+	if len(rules) == 0 {
+		return tgmarkup.Escape("Правил нет, в чат приходит каждая сводка.\n\n" + rulesUsage)
+	}
+	var b strings.Builder
+	b.WriteString("Сводки приходят, когда срабатывает одно из правил:\n")
+	for i, rule := range rules {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, tgmarkup.Escape(describeRule(rule)))
+	}
+	b.WriteString("\nУдалить правило: /rules del &lt;номер&gt;")
+	return b.String()
+}
+
+func describeRule(rule rating.Rule) string {
+	var text string
+	switch rule.Kind {
+	case rating.RuleChange:
+		text = "изменилась позиция или прогноз"
+	case rating.RuleBudget:
+		text = "позиция вошла в бюджетные места или вышла из них"
+	case rating.RuleAhead:
+		text = fmt.Sprintf("выше с более низким приоритетом стало меньше %g", rule.Threshold)
+	case rating.RuleCutoff:
+		text = fmt.Sprintf("прогноз проходного балла сдвинулся на %g и больше", rule.Threshold)
+	}
+	if rule.ProgramID != 0 {
+		text += fmt.Sprintf(", программа %d", rule.ProgramID)
+	}
+	if rule.MaxPriority != 0 {
+		text += fmt.Sprintf(", приоритеты 1–%d", rule.MaxPriority)
+	}
+	return text
+}
@@ -0,0 +1,54 @@
+package bot_commands
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		args    string
+		want    rating.Rule
+		wantErr string
+	}{
+		{args: "change", want: rating.Rule{Kind: rating.RuleChange}},
+		{args: "budget program=1000", want: rating.Rule{Kind: rating.RuleBudget, ProgramID: 1000}},
+		{args: "ahead 3 priority=2", want: rating.Rule{Kind: rating.RuleAhead, Threshold: 3, MaxPriority: 2}},
+		{args: "cutoff 2.5 program=1000 priority=1", want: rating.Rule{Kind: rating.RuleCutoff, Threshold: 2.5, ProgramID: 1000, MaxPriority: 1}},
+		// a threshold of a rule without one is read as a filter
+		{args: "change 3", wantErr: `получено "3"`},
+		{args: "", wantErr: "Использование"},
+		{args: "position", wantErr: "Правила:"},
+		{args: "ahead", wantErr: "Укажите порог"},
+		{args: "ahead program=1000", wantErr: "Порог"},
+		{args: "ahead 0", wantErr: "Порог"},
+		{args: "cutoff -1", wantErr: "Порог"},
+		{args: "cutoff NaN", wantErr: "Порог"},
+		{args: "cutoff Inf", wantErr: "Порог"},
+		{args: "budget program=abc", wantErr: `получено "program=abc"`},
+		{args: "budget program=0", wantErr: `получено "program=0"`},
+		{args: "budget priority=", wantErr: `получено "priority="`},
+		{args: "budget seats=3", wantErr: `получено "seats=3"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			got, err := parseRule(strings.Fields(tt.args))
+			if tt.wantErr != "" {
+				var usage usageError
+				if !errors.As(err, &usage) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got %v, want a usage error with %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package bot_commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/preferences"
+	"itmo-ratings/pkg/tgmarkup"
+)
+
+const scheduleUsage = `Использование:
+/schedule — когда в этот чат приходят сводки
+/schedule now — сразу после обновления списков
+/schedule digest 20:00 — раз в день сводкой изменений за сутки
+/schedule quiet 23:00-08:00 — не присылать в эти часы, сообщения придут после них
+/schedule quiet off — без тихих часов
+/schedule reset — как в конфигурации`
+
+// schedule sets when notify delivers summaries to the chat, it replaces the configured schedule.
+func (h *Handler) schedule(_ context.Context, chatID int64, args []string) (string, error) {
+	if len(args) == 0 {
+		return h.formatSchedule(chatID), nil
+	}
+
+	var update func(*rating.Schedule)
+	switch {
+	case args[0] == "now" && len(args) == 1:
+		update = func(s *rating.Schedule) { s.Digest = nil }
+	case args[0] == "digest" && len(args) == 2:
+		at, err := rating.ParseClock(args[1])
+		if err != nil {
+			return "", usageError("Время дайджеста — в формате ЧЧ:ММ, например 20:00")
+		}
+		update = func(s *rating.Schedule) { s.Digest = &at }
+	case args[0] == "quiet" && len(args) == 2 && args[1] == "off":
+		update = func(s *rating.Schedule) { s.Quiet = nil }
+	case args[0] == "quiet" && len(args) == 2:
+		quiet, err := rating.ParseClockRange(args[1])
+		if err != nil {
+			return "", usageError("Тихие часы — в формате ЧЧ:ММ-ЧЧ:ММ, например 23:00-08:00")
+		}
+		update = func(s *rating.Schedule) { s.Quiet = &quiet }
+	case args[0] == "reset" && len(args) == 1:
+		err := h.preferences.Update(chatID, func(c *preferences.Chat) { c.Schedule = nil })
+		if err != nil {
+			return "", err
+		}
+		return h.formatSchedule(chatID), nil
+	default:
+		return "", usageError(scheduleUsage)
+	}
+
+	configured := h.configuredSchedule(chatID)
+	err := h.preferences.Update(chatID, func(c *preferences.Chat) {
+		if c.Schedule == nil {
+			c.Schedule = &configured
+		}
+		update(c.Schedule)
+	})
+	if err != nil {
+		return "", err
+	}
+	return h.formatSchedule(chatID), nil
+}
+
+// configuredSchedule is the schedule of the chat in the configuration, the first one for chats
+// receiving several students.
+func (h *Handler) configuredSchedule(chatID int64) rating.Schedule {
+	for _, sub := range h.subscriptions {
+		for _, recipient := range sub.Recipients {
+			if recipient.ChatID == chatID {
+				return recipient.Schedule
+			}
+		}
+	}
+	return rating.Schedule{}
+}
+
+func (h *Handler) formatSchedule(chatID int64) string {
+	schedule := h.configuredSchedule(chatID)
+	if chat := h.preferences.Chat(chatID); chat.Schedule != nil {
+		schedule = *chat.Schedule
+	}
+	location := rating.Recipient{Format: h.format(chatID, "")}.Location()
+
+	var b strings.Builder
+	if schedule.Digest != nil {
+		fmt.Fprintf(&b, "Сводка изменений приходит раз в день в %s.\n", schedule.Digest)
+	} else {
+		b.WriteString("Сводки приходят сразу после обновления списков.\n")
+	}
+	if schedule.Quiet != nil {
+		fmt.Fprintf(&b, "Тихие часы: %s, сообщения за это время придут после них.\n", schedule.Quiet)
+	}
+	fmt.Fprintf(&b, "Время указано в поясе %s.\n\n%s", location, scheduleUsage)
+	return tgmarkup.Escape(b.String())
+}
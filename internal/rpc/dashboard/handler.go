@@ -0,0 +1,236 @@
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"html/template"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/admission"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+//go:embed templates/*.html
+var templates embed.FS
+
+type ratingService interface {
+	Degrees() []rating.Degree
+	GetStudentSummaryRaw(ctx context.Context, degree rating.Degree, studentID string) (*rating.StudentSummary, error)
+	GetProgram(ctx context.Context, degree rating.Degree, programID int) (*rating.ProgramData, error)
+	ProgramURL(degree rating.Degree, basis rating.Basis, programID int) string
+}
+
+// Handler renders the HTML pages: the search form, the student summary and the program lists.
+type Handler struct {
+	rating ratingService
+	pages  map[string]*template.Template
+}
+
+func New(rating ratingService) *Handler {
+	h := &Handler{
+		rating: rating,
+		pages:  make(map[string]*template.Template),
+	}
+	funcs := template.FuncMap{
+		"programURL": rating.ProgramURL,
+		"basisTitle": basisTitle,
+	}
+	for _, page := range []string{"search", "student", "program", "error"} {
+		h.pages[page] = template.Must(template.New("layout.html").Funcs(funcs).ParseFS(templates, "templates/layout.html", "templates/"+page+".html"))
+	}
+	return h
+}
+
+type page struct {
+	Title   string
+	Degrees []rating.Degree
+	Degree  rating.Degree
+	Query   string
+}
+
+// Search serves GET / with the student ID form, a submitted form redirects to the student page.
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	degree, ok := h.degree(w, r)
+	if !ok {
+		return
+	}
+	if id := r.URL.Query().Get("id"); id != "" {
+		if _, err := strconv.Atoi(id); err != nil {
+			h.error(w, r, degree, http.StatusBadRequest, "ID студента должен быть числом")
+			return
+		}
+		http.Redirect(w, r, "/students/"+id+"?degree="+url.QueryEscape(string(degree)), http.StatusSeeOther)
+		return
+	}
+	h.render(w, http.StatusOK, "search", h.page(degree, "Поиск студента", ""))
+}
+
+// Student serves GET /students/{id}?degree=master.
+func (h *Handler) Student(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	degree, ok := h.degree(w, r)
+	if !ok {
+		return
+	}
+	studentID := r.PathValue("id")
+	if _, err := strconv.Atoi(studentID); err != nil {
+		h.error(w, r, degree, http.StatusBadRequest, "ID студента должен быть числом")
+		return
+	}
+
+	summary, err := h.rating.GetStudentSummaryRaw(r.Context(), degree, studentID)
+	if errors.Is(err, rating.ErrStudentNotFound) {
+		h.error(w, r, degree, http.StatusNotFound, "Студент "+studentID+" не найден в списках")
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get student summary", "degree", degree, "studentID", studentID, "err", err.Error())
+		h.error(w, r, degree, http.StatusInternalServerError, "Не удалось получить данные, попробуйте позже")
+		return
+	}
+
+	h.render(w, http.StatusOK, "student", struct {
+		page
+		Summary *rating.StudentSummary
+	}{h.page(degree, "Студент "+studentID, studentID), summary})
+}
+
+type programRow struct {
+	rating.Entry
+	Highlighted bool
+}
+
+// Program serves GET /programs/{id}?degree=master&basis=budget&student=123, the student's row is highlighted.
+func (h *Handler) Program(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	degree, ok := h.degree(w, r)
+	if !ok {
+		return
+	}
+	programID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		h.error(w, r, degree, http.StatusBadRequest, "ID программы должен быть числом")
+		return
+	}
+	basis := rating.BasisBudget
+	if v := r.URL.Query().Get("basis"); v != "" {
+		if basis, err = rating.ParseBasis(v); err != nil {
+			h.error(w, r, degree, http.StatusBadRequest, "Неизвестная основа обучения")
+			return
+		}
+	}
+	studentID := r.URL.Query().Get("student")
+
+	program, err := h.rating.GetProgram(r.Context(), degree, programID)
+	if errors.Is(err, rating.ErrProgramNotFound) {
+		h.error(w, r, degree, http.StatusNotFound, "Программа "+strconv.Itoa(programID)+" не найдена")
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get program", "degree", degree, "programID", programID, "err", err.Error())
+		h.error(w, r, degree, http.StatusInternalServerError, "Не удалось получить данные, попробуйте позже")
+		return
+	}
+
+	entries := program.List(basis)
+	rows := make([]programRow, 0, len(entries))
+	found := false
+	for _, e := range entries {
+		highlighted := studentID != "" && e.SSPVOID == studentID
+		found = found || highlighted
+		rows = append(rows, programRow{Entry: e, Highlighted: highlighted})
+	}
+
+	h.render(w, http.StatusOK, "program", struct {
+		page
+		Program *rating.ProgramData
+		Basis   rating.Basis
+		Bases   []rating.Basis
+		Seats   int
+		Rows    []programRow
+		Found   bool
+	}{
+		page:    h.page(degree, program.Data.DirectionTitle, studentID),
+		Program: program,
+		Basis:   basis,
+		Bases:   rating.Bases(),
+		Seats:   admission.ListSeats(program, basis),
+		Rows:    rows,
+		Found:   found,
+	})
+}
+
+// degree parses ?degree=, master by default. Untracked degrees get the error page.
+func (h *Handler) degree(w http.ResponseWriter, r *http.Request) (rating.Degree, bool) {
+	degree := rating.DegreeMaster
+	if v := r.URL.Query().Get("degree"); v != "" {
+		var err error
+		if degree, err = rating.ParseDegree(v); err != nil {
+			h.error(w, r, rating.DegreeMaster, http.StatusBadRequest, "Неизвестный уровень образования")
+			return "", false
+		}
+	}
+	for _, tracked := range h.rating.Degrees() {
+		if tracked == degree {
+			return degree, true
+		}
+	}
+	h.error(w, r, h.rating.Degrees()[0], http.StatusNotFound, "Уровень образования не отслеживается")
+	return "", false
+}
+
+func (h *Handler) page(degree rating.Degree, title, query string) page {
+	return page{
+		Title:   title,
+		Degrees: h.rating.Degrees(),
+		Degree:  degree,
+		Query:   query,
+	}
+}
+
+func (h *Handler) error(w http.ResponseWriter, r *http.Request, degree rating.Degree, status int, message string) {
+	h.render(w, status, "error", struct {
+		page
+		Message string
+	}{h.page(degree, errorTitles[status], r.URL.Query().Get("id")), message})
+}
+
+var errorTitles = map[int]string{
+	http.StatusBadRequest:          "Неверный запрос",
+	http.StatusNotFound:            "Не найдено",
+	http.StatusInternalServerError: "Ошибка",
+}
+
+// render executes into a buffer first, so a failing template doesn't leave a half written page.
+func (h *Handler) render(w http.ResponseWriter, status int, name string, data any) {
+	var buf bytes.Buffer
+	if err := h.pages[name].Execute(&buf, data); err != nil {
+		slog.Error("failed to render page", "page", name, "err", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+func basisTitle(basis rating.Basis) string {
+	if basis == rating.BasisContract {
+		return "контракт"
+	}
+	return "бюджет"
+}
@@ -0,0 +1,73 @@
+package leaderboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/stats"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type ratingService interface {
+	GetLeaderboard(ctx context.Context, degree rating.Degree, order stats.Order) (*stats.Leaderboard, error)
+}
+
+type Handler struct {
+	rating ratingService
+}
+
+func New(rating ratingService) *Handler {
+	return &Handler{
+		rating: rating,
+	}
+}
+
+// ServeHTTP serves GET /api/v1/programs/leaderboard?degree=master&order=competition&limit=20.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	degree := rating.DegreeMaster
+	var err error
+	if v := query.Get("degree"); v != "" {
+		if degree, err = rating.ParseDegree(v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	order, err := stats.ParseOrder(query.Get("order"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	limit := 0
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	leaderboard, err := h.rating.GetLeaderboard(r.Context(), degree, order)
+	if errors.Is(err, rating.ErrDegreeNotTracked) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get leaderboard", "degree", degree, "order", order, "err", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if limit > 0 && limit < len(leaderboard.Programs) {
+		leaderboard.Programs = leaderboard.Programs[:limit]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(leaderboard)
+}
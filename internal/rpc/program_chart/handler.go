@@ -0,0 +1,76 @@
+package program_chart
+
+import (
+	"bytes"
+	"errors"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/timeline"
+	"itmo-ratings/pkg/chart"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type timelineService interface {
+	Program(degree rating.Degree, studentID string, programID int) (*timeline.Series, error)
+}
+
+type Handler struct {
+	timeline timelineService
+	format   chart.Format
+}
+
+func New(timeline timelineService, format chart.Format) *Handler {
+	return &Handler{
+		timeline: timeline,
+		format:   format,
+	}
+}
+
+// ServeHTTP serves GET /api/v1/programs/{id}/chart.svg?student=123&degree=master with the position
+// and cutoff of the student over the saved snapshots, the .png route differs only in the format.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	programID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	studentID := query.Get("student")
+	if _, err := strconv.Atoi(studentID); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	degree := rating.DegreeMaster
+	if v := query.Get("degree"); v != "" {
+		if degree, err = rating.ParseDegree(v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	series, err := h.timeline.Program(degree, studentID, programID)
+	if errors.Is(err, rating.ErrStudentNotFound) || errors.Is(err, rating.ErrProgramNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to build timeline", "degree", degree, "studentID", studentID, "programID", programID, "err", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := series.Chart(studentID).Write(&buf, h.format); err != nil {
+		slog.Error("failed to render chart", "programID", programID, "format", h.format, "err", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", h.format.ContentType())
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
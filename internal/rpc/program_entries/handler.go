@@ -0,0 +1,75 @@
+package program_entries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/export"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type ratingService interface {
+	GetProgram(ctx context.Context, degree rating.Degree, programID int) (*rating.ProgramData, error)
+}
+
+type Handler struct {
+	rating ratingService
+	format export.Format
+}
+
+func New(rating ratingService, format export.Format) *Handler {
+	return &Handler{
+		rating: rating,
+		format: format,
+	}
+}
+
+// ServeHTTP serves GET /api/v1/programs/{id}/entries.csv?degree=master&basis=budget,
+// the .xlsx route differs only in the format.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	programID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	degree := rating.DegreeMaster
+	if v := query.Get("degree"); v != "" {
+		if degree, err = rating.ParseDegree(v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	basis := rating.BasisBudget
+	if v := query.Get("basis"); v != "" {
+		if basis, err = rating.ParseBasis(v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	program, err := h.rating.GetProgram(r.Context(), degree, programID)
+	if errors.Is(err, rating.ErrDegreeNotTracked) || errors.Is(err, rating.ErrProgramNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get program", "degree", degree, "programID", programID, "err", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", h.format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%d-%s.%s"`, degree, programID, basis, h.format))
+	w.WriteHeader(http.StatusOK)
+	if err := export.Write(w, h.format, export.Program(program, basis)); err != nil {
+		slog.Error("failed to write program entries", "programID", programID, "format", h.format, "err", err.Error())
+	}
+}
@@ -0,0 +1,86 @@
+package program_neighbours
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"itmo-ratings/internal/domain/rating"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// maxNeighbours caps n so a request can't dump a whole list, entries.csv is there for that.
+const maxNeighbours = 50
+
+type ratingService interface {
+	GetNeighbourhood(ctx context.Context, degree rating.Degree, studentID string, programID int, basis rating.Basis, n int) (*rating.Neighbourhood, error)
+}
+
+type Handler struct {
+	rating     ratingService
+	neighbours int
+}
+
+// New creates the handler, neighbours is the default number of applicants on each side of the student.
+func New(rating ratingService, neighbours int) *Handler {
+	return &Handler{
+		rating:     rating,
+		neighbours: neighbours,
+	}
+}
+
+// ServeHTTP serves GET /api/v1/programs/{id}/neighbours?student=123&degree=master&basis=budget&n=5.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	programID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	studentID := query.Get("student")
+	if _, err := strconv.Atoi(studentID); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	degree := rating.DegreeMaster
+	if v := query.Get("degree"); v != "" {
+		if degree, err = rating.ParseDegree(v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	var basis rating.Basis
+	if v := query.Get("basis"); v != "" {
+		if basis, err = rating.ParseBasis(v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	n := h.neighbours
+	if v := query.Get("n"); v != "" {
+		if n, err = strconv.Atoi(v); err != nil || n < 0 || n > maxNeighbours {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	neighbourhood, err := h.rating.GetNeighbourhood(r.Context(), degree, studentID, programID, basis, n)
+	if errors.Is(err, rating.ErrDegreeNotTracked) || errors.Is(err, rating.ErrStudentNotFound) || errors.Is(err, rating.ErrProgramNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get neighbourhood", "degree", degree, "studentID", studentID, "programID", programID, "err", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(neighbourhood)
+}
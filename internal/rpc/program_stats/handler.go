@@ -0,0 +1,70 @@
+package program_stats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/stats"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+type ratingService interface {
+	GetProgramStats(ctx context.Context, degree rating.Degree, programID int, binWidth float64) (*stats.ProgramStats, error)
+}
+
+type Handler struct {
+	rating ratingService
+}
+
+func New(rating ratingService) *Handler {
+	return &Handler{
+		rating: rating,
+	}
+}
+
+// ServeHTTP serves GET /api/v1/programs/{id}/stats?degree=master&bin=10.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	programID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	degree := rating.DegreeMaster
+	if v := r.URL.Query().Get("degree"); v != "" {
+		if degree, err = rating.ParseDegree(v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	var binWidth float64
+	if v := r.URL.Query().Get("bin"); v != "" {
+		// NaN fails the comparison too
+		if binWidth, err = strconv.ParseFloat(v, 64); err != nil || !(binWidth >= stats.MinBinWidth) || math.IsInf(binWidth, 0) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	programStats, err := h.rating.GetProgramStats(r.Context(), degree, programID, binWidth)
+	if errors.Is(err, rating.ErrDegreeNotTracked) || errors.Is(err, rating.ErrProgramNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get program stats", "degree", degree, "programID", programID, "err", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(programStats)
+}
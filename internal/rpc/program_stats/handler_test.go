@@ -0,0 +1,47 @@
+package program_stats
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/stats"
+)
+
+type fakeRating struct{}
+
+func (fakeRating) GetProgramStats(_ context.Context, _ rating.Degree, programID int, _ float64) (*stats.ProgramStats, error) {
+	return &stats.ProgramStats{ProgramID: programID}, nil
+}
+
+func TestServeHTTPBin(t *testing.T) {
+	tests := []struct {
+		bin  string
+		code int
+	}{
+		{bin: "", code: http.StatusOK},
+		{bin: "10", code: http.StatusOK},
+		{bin: "0.5", code: http.StatusOK},
+		{bin: "0", code: http.StatusBadRequest},
+		{bin: "-1", code: http.StatusBadRequest},
+		{bin: "0.1", code: http.StatusBadRequest},
+		{bin: "1e-300", code: http.StatusBadRequest},
+		{bin: "NaN", code: http.StatusBadRequest},
+		{bin: "Inf", code: http.StatusBadRequest},
+		{bin: "+Inf", code: http.StatusBadRequest},
+		{bin: "ten", code: http.StatusBadRequest},
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/v1/programs/{id}/stats", New(fakeRating{}))
+	for _, tt := range tests {
+		t.Run(tt.bin, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/programs/1000/stats?bin="+tt.bin, nil))
+			if rec.Code != tt.code {
+				t.Errorf("bin=%s: got status %d, want %d", tt.bin, rec.Code, tt.code)
+			}
+		})
+	}
+}
@@ -2,13 +2,16 @@ package rating_summary
 
 import (
 	"context"
+	"errors"
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/report"
 	"log/slog"
 	"net/http"
 	"strconv"
 )
 
 type ratingService interface {
-	GetStudentSummary(context.Context, string) (string, error)
+	GetStudentSummary(context.Context, rating.Degree, string, rating.SummaryFormat) (string, error)
 }
 
 type Handler struct {
@@ -26,6 +29,15 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	// routes without {degree} predate bachelor support and always mean master
+	degree := rating.DegreeMaster
+	if v := r.PathValue("degree"); v != "" {
+		var err error
+		if degree, err = rating.ParseDegree(v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
 	studentID := r.PathValue("id")
 	if studentID == "" {
 		w.WriteHeader(http.StatusBadRequest)
@@ -36,9 +48,19 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	summary, err := h.rating.GetStudentSummary(r.Context(), studentID)
+	query := r.URL.Query()
+	format, err := report.ParseFormat(query.Get("template"), query.Get("locale"), query.Get("tz"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	summary, err := h.rating.GetStudentSummary(r.Context(), degree, studentID, format)
+	if errors.Is(err, rating.ErrDegreeNotTracked) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		slog.Error("failed to get student summary", "studentID", studentID, "err", err.Error())
+		slog.Error("failed to get student summary", "degree", degree, "studentID", studentID, "err", err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
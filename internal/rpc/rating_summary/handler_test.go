@@ -0,0 +1,75 @@
+package rating_summary
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/scrapper"
+	sender "itmo-ratings/internal/domain/rating/student_rating_service"
+	"itmo-ratings/internal/itmotest"
+)
+
+func TestServeHTTP(t *testing.T) {
+	cfg := itmotest.DefaultGenerateConfig()
+	programs := itmotest.Generate(cfg)
+	srv := itmotest.Start(t, programs...)
+	service := sender.New(scrapper.New(srv.Client(), srv.ScrapperOptions()...))
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v1/rating/summary/{id}", New(service))
+	mux.Handle("/api/v1/rating/{degree}/summary/{id}", New(service))
+
+	studentID := programs[0].General[0].SSPVO
+	tests := []struct {
+		name   string
+		url    string
+		status int
+		body   []string
+	}{
+		{name: "summary", url: "/api/v1/rating/summary/" + studentID, status: http.StatusOK,
+			body: []string{"Приоритет:", "«Тестовая программа_1»", srv.URL + itmotest.ProgramPath(rating.DegreeMaster, rating.BasisBudget, 1000)}},
+		{name: "degree", url: "/api/v1/rating/master/summary/" + studentID, status: http.StatusOK, body: []string{"Приоритет:"}},
+		{name: "template and locale", url: "/api/v1/rating/summary/" + studentID + "?template=compact&locale=en", status: http.StatusOK,
+			body: []string{"«Тестовая программа_1»"}},
+		{name: "untracked degree", url: "/api/v1/rating/bachelor/summary/" + studentID, status: http.StatusNotFound},
+		{name: "unknown degree", url: "/api/v1/rating/phd/summary/" + studentID, status: http.StatusBadRequest},
+		{name: "bad id", url: "/api/v1/rating/summary/abc", status: http.StatusBadRequest},
+		{name: "bad template", url: "/api/v1/rating/summary/" + studentID + "?template=fancy", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			for _, want := range tt.body {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("body doesn't contain %q:\n%s", want, rec.Body)
+				}
+			}
+		})
+	}
+
+	// the first request fills the cache, later ones don't reach ITMO
+	if n := srv.Requests(itmotest.ProgramsPath()); n != 1 {
+		t.Errorf("programs API requested %d times, want once", n)
+	}
+}
+
+func TestServeHTTPUpstreamDown(t *testing.T) {
+	srv := itmotest.Start(t, itmotest.Generate(itmotest.DefaultGenerateConfig())...)
+	srv.InjectFault(itmotest.ProgramsPath(), itmotest.Fault{Status: http.StatusServiceUnavailable})
+	handler := New(sender.New(scrapper.New(srv.Client(), srv.ScrapperOptions()...), rating.DegreeMaster))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/rating/summary/4000000", nil)
+	req.SetPathValue("id", "4000000")
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
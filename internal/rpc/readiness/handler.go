@@ -0,0 +1,49 @@
+package readiness
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+type ratingService interface {
+	Health() []rating.Health
+}
+
+type Handler struct {
+	rating ratingService
+}
+
+func New(rating ratingService) *Handler {
+	return &Handler{
+		rating: rating,
+	}
+}
+
+type response struct {
+	Status  string          `json:"status"`
+	Degrees []rating.Health `json:"degrees"`
+}
+
+// ServeHTTP answers 503 until the cache of every tracked degree is loaded, then 200. Program pages
+// that didn't match the expected schema only make the status degraded: the service still answers
+// from the rest, and restarting it wouldn't fix the pages.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resp := response{Status: "ok", Degrees: h.rating.Health()}
+	code := http.StatusOK
+	for _, health := range resp.Degrees {
+		if health.LastUpdated.IsZero() {
+			resp.Status = "not_loaded"
+			code = http.StatusServiceUnavailable
+			break
+		}
+		if len(health.SchemaIssues) > 0 {
+			resp.Status = "degraded"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,49 @@
+package readiness
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+)
+
+type fakeService []rating.Health
+
+func (s fakeService) Health() []rating.Health { return s }
+
+func TestServeHTTP(t *testing.T) {
+	loaded := rating.Health{Degree: rating.DegreeMaster, LastUpdated: time.Now(), Programs: 3}
+	changed := loaded
+	changed.SchemaIssues = []rating.SchemaIssue{{ProgramID: 1000}}
+
+	tests := []struct {
+		name    string
+		degrees []rating.Health
+		status  int
+		want    string
+	}{
+		{name: "ok", degrees: []rating.Health{loaded}, status: http.StatusOK, want: "ok"},
+		{name: "not loaded", degrees: []rating.Health{loaded, {Degree: rating.DegreeBachelor}}, status: http.StatusServiceUnavailable, want: "not_loaded"},
+		// a changed page doesn't take the whole service out of rotation
+		{name: "schema issues", degrees: []rating.Health{changed, loaded}, status: http.StatusOK, want: "degraded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			New(fakeService(tt.degrees)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rec.Code != tt.status {
+				t.Errorf("got status %d, want %d", rec.Code, tt.status)
+			}
+			var resp response
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Status != tt.want || len(resp.Degrees) != len(tt.degrees) {
+				t.Errorf("got %+v, want status %s", resp, tt.want)
+			}
+		})
+	}
+}
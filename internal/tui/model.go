@@ -0,0 +1,315 @@
+package tui
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/pkg/term"
+)
+
+type view int
+
+const (
+	viewPrograms view = iota
+	viewProgram
+	viewStudent
+)
+
+// cursor is the selected row and the first visible row of a list.
+type cursor struct {
+	row, offset int
+}
+
+// model is the state of the interface. Every key and refresh updates it, render draws it.
+type model struct {
+	ctx     context.Context
+	service ratingService
+
+	degrees []rating.Degree
+	degree  rating.Degree
+	tracked []string
+
+	loaded   bool
+	programs []rating.ProgramData // sorted by competitive group ID
+	summary  *rating.StudentSummary
+	updated  time.Time
+	err      error
+
+	views     []view // navigation stack, the last one is shown
+	cursors   map[view]*cursor
+	programID int
+	basis     rating.Basis
+	studentID string
+	// input is the student ID being typed, nil when the prompt is closed
+	input *[]rune
+
+	width, height    int
+	quit             bool
+	refreshRequested bool
+	refreshing       bool
+}
+
+func newModel(ctx context.Context, service ratingService, tracked []string) *model {
+	degrees := service.Degrees()
+	return &model{
+		ctx:        ctx,
+		service:    service,
+		degrees:    degrees,
+		degree:     degrees[0],
+		tracked:    tracked,
+		views:      []view{viewPrograms},
+		cursors:    map[view]*cursor{viewPrograms: {}, viewProgram: {}, viewStudent: {}},
+		basis:      rating.BasisBudget,
+		refreshing: true,
+	}
+}
+
+func (m *model) view() view {
+	return m.views[len(m.views)-1]
+}
+
+func (m *model) open(v view) {
+	*m.cursors[v] = cursor{}
+	m.views = append(m.views, v)
+}
+
+func (m *model) back() {
+	if len(m.views) > 1 {
+		m.views = m.views[:len(m.views)-1]
+	}
+}
+
+// refreshed is called when a background update of the ratings is over.
+func (m *model) refreshed(err error) {
+	m.refreshing = false
+	m.loaded = true
+	m.err = err
+	m.reload()
+}
+
+// reload reads the current degree from the service cache.
+func (m *model) reload() {
+	if !m.loaded {
+		return
+	}
+	for _, h := range m.service.Health() {
+		if h.Degree == m.degree {
+			m.updated = h.LastUpdated
+		}
+	}
+
+	programs, err := m.service.GetPrograms(m.ctx, m.degree)
+	if err != nil {
+		m.err = err
+		programs = nil
+	}
+	m.programs = m.programs[:0]
+	for _, id := range slices.Sorted(maps.Keys(programs)) {
+		m.programs = append(m.programs, programs[id])
+	}
+
+	m.summary = nil
+	if m.studentID != "" {
+		if m.summary, err = m.service.GetStudentSummaryRaw(m.ctx, m.degree, m.studentID); err != nil {
+			m.err = err
+		}
+	}
+}
+
+func (m *model) program() *rating.ProgramData {
+	i := slices.IndexFunc(m.programs, func(p rating.ProgramData) bool {
+		return p.Data.CompetitiveGroupID == m.programID
+	})
+	if i < 0 {
+		return nil
+	}
+	return &m.programs[i]
+}
+
+func (m *model) isTracked(studentID string) bool {
+	return studentID == m.studentID || slices.Contains(m.tracked, studentID)
+}
+
+// rows returns the number of selectable rows of the current view.
+func (m *model) rows() int {
+	switch m.view() {
+	case viewProgram:
+		if p := m.program(); p != nil {
+			return len(p.List(m.basis))
+		}
+		return 0
+	case viewStudent:
+		if m.summary != nil {
+			return len(m.summary.Entries)
+		}
+		return 0
+	default:
+		return len(m.programs)
+	}
+}
+
+func (m *model) handle(key term.Key) {
+	if m.input != nil {
+		m.handleInput(key)
+		return
+	}
+
+	c := m.cursors[m.view()]
+	page := max(1, m.listHeight()-1)
+	switch key.Code {
+	case term.KeyUp:
+		c.row--
+	case term.KeyDown:
+		c.row++
+	case term.KeyPageUp:
+		c.row -= page
+	case term.KeyPageDown:
+		c.row += page
+	case term.KeyHome:
+		c.row = 0
+	case term.KeyEnd:
+		c.row = m.rows() - 1
+	case term.KeyEnter, term.KeyRight:
+		m.enter()
+	case term.KeyEscape, term.KeyBackspace, term.KeyLeft:
+		m.back()
+	case term.KeyTab:
+		m.nextTracked()
+	case term.KeyRune:
+		switch key.Rune {
+		case 'q', 'й':
+			m.quit = true
+		case 'k':
+			c.row--
+		case 'j':
+			c.row++
+		case 'r', 'к':
+			m.refreshRequested = true
+			m.refreshing = true
+		case 'd', 'в':
+			m.nextDegree()
+		case 'b', 'и':
+			m.toggleBasis()
+		case 's', '/', 'ы':
+			m.input = &[]rune{}
+		}
+	}
+	m.clamp()
+}
+
+func (m *model) handleInput(key term.Key) {
+	switch key.Code {
+	case term.KeyEnter:
+		id := string(*m.input)
+		m.input = nil
+		if id != "" {
+			m.showStudent(id)
+		}
+	case term.KeyEscape:
+		m.input = nil
+	case term.KeyBackspace:
+		if n := len(*m.input); n > 0 {
+			*m.input = (*m.input)[:n-1]
+		}
+	case term.KeyRune:
+		if key.Rune >= '0' && key.Rune <= '9' {
+			*m.input = append(*m.input, key.Rune)
+		}
+	}
+}
+
+// enter opens the selected row: a program from the list, a student from a program, a program from a student.
+func (m *model) enter() {
+	row := m.cursors[m.view()].row
+	switch m.view() {
+	case viewPrograms:
+		if row < len(m.programs) {
+			m.programID = m.programs[row].Data.CompetitiveGroupID
+			m.open(viewProgram)
+			m.selectTracked()
+		}
+	case viewProgram:
+		if p := m.program(); p != nil && row < len(p.List(m.basis)) {
+			m.showStudent(p.List(m.basis)[row].SSPVOID)
+		}
+	case viewStudent:
+		if m.summary != nil && row < len(m.summary.Entries) {
+			e := m.summary.Entries[row]
+			m.programID = e.ProgramID
+			m.basis = e.Basis
+			m.open(viewProgram)
+			m.selectTracked()
+		}
+	}
+}
+
+func (m *model) showStudent(studentID string) {
+	m.studentID = studentID
+	m.reload()
+	if m.view() == viewStudent {
+		*m.cursors[viewStudent] = cursor{}
+		return
+	}
+	m.open(viewStudent)
+}
+
+func (m *model) nextTracked() {
+	if len(m.tracked) == 0 {
+		return
+	}
+	i := slices.Index(m.tracked, m.studentID)
+	m.showStudent(m.tracked[(i+1)%len(m.tracked)])
+}
+
+func (m *model) nextDegree() {
+	i := slices.Index(m.degrees, m.degree)
+	m.degree = m.degrees[(i+1)%len(m.degrees)]
+	m.views = []view{viewPrograms}
+	*m.cursors[viewPrograms] = cursor{}
+	m.reload()
+}
+
+func (m *model) toggleBasis() {
+	if m.view() != viewProgram {
+		return
+	}
+	if m.basis == rating.BasisBudget {
+		m.basis = rating.BasisContract
+	} else {
+		m.basis = rating.BasisBudget
+	}
+	*m.cursors[viewProgram] = cursor{}
+	m.selectTracked()
+}
+
+// selectTracked moves the cursor of a program list to the first highlighted student.
+func (m *model) selectTracked() {
+	p := m.program()
+	if p == nil {
+		return
+	}
+	i := slices.IndexFunc(p.List(m.basis), func(e rating.Entry) bool { return m.isTracked(e.SSPVOID) })
+	if i >= 0 {
+		c := m.cursors[viewProgram]
+		c.row = i
+		c.offset = max(0, i-m.listHeight()/2)
+		m.clamp()
+	}
+}
+
+// clamp keeps the cursor in the list and the selected row on the screen.
+func (m *model) clamp() {
+	c := m.cursors[m.view()]
+	c.row = max(0, min(c.row, m.rows()-1))
+	height := max(1, m.listHeight())
+	if c.row < c.offset {
+		c.offset = c.row
+	}
+	if c.row >= c.offset+height {
+		c.offset = c.row - height + 1
+	}
+	c.offset = max(0, min(c.offset, m.rows()-height))
+}
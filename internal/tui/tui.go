@@ -0,0 +1,151 @@
+// Package tui is a terminal interface for browsing the cached ratings and the tracked students.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/pkg/term"
+)
+
+type ratingService interface {
+	Degrees() []rating.Degree
+	EnrichAll(ctx context.Context) error
+	Health() []rating.Health
+	GetPrograms(ctx context.Context, degree rating.Degree) (map[int]rating.ProgramData, error)
+	GetStudentSummaryRaw(ctx context.Context, degree rating.Degree, studentID string) (*rating.StudentSummary, error)
+}
+
+type Option func(*options)
+
+type options struct {
+	students        []string
+	refreshInterval time.Duration
+}
+
+// WithStudents highlights the students in the lists, Tab cycles through their summaries.
+func WithStudents(ids ...string) Option {
+	return func(o *options) {
+		o.students = append(o.students, ids...)
+	}
+}
+
+// WithRefreshInterval sets how often the ratings are reloaded in the background.
+func WithRefreshInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.refreshInterval = d
+		}
+	}
+}
+
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+)
+
+// Run shows the interface on the terminal of in until the user quits or ctx is cancelled.
+// The ratings are refreshed in the background and the screen is redrawn after every update.
+func Run(ctx context.Context, service ratingService, in *os.File, out io.Writer, opts ...Option) error {
+	o := options{refreshInterval: 10 * time.Minute}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return errors.New("stdin is not a terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to switch terminal to raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+	fmt.Fprint(out, enterScreen)
+	defer fmt.Fprint(out, leaveScreen)
+
+	// log lines would be drawn over the screen
+	logger := slog.Default()
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+	defer slog.SetDefault(logger)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the reader stays blocked on the terminal after Run returns, it is only meant to run until exit
+	keys := make(chan term.Key, 16)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := in.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, key := range term.Decode(buf[:n]) {
+				select {
+				case keys <- key:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	resize := make(chan os.Signal, 1)
+	term.NotifyResize(resize)
+	defer signal.Stop(resize)
+
+	refresh := make(chan struct{}, 1)
+	refreshed := make(chan error)
+	go func() {
+		t := time.NewTicker(o.refreshInterval)
+		defer t.Stop()
+		for {
+			err := service.EnrichAll(ctx)
+			select {
+			case refreshed <- err:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case <-t.C:
+			case <-refresh:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	m := newModel(ctx, service, o.students)
+	m.width, m.height, _ = term.Size(fd)
+	for !m.quit {
+		m.render(out)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-resize:
+			m.width, m.height, _ = term.Size(fd)
+		case err := <-refreshed:
+			m.refreshed(err)
+		case key := <-keys:
+			m.handle(key)
+		}
+
+		if m.refreshRequested {
+			m.refreshRequested = false
+			select {
+			case refresh <- struct{}{}:
+			default:
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"itmo-ratings/internal/domain/rating"
+	"itmo-ratings/internal/domain/rating/admission"
+)
+
+const (
+	styleReset     = "\x1b[0m"
+	styleSelected  = "\x1b[7m"
+	styleTracked   = "\x1b[1;33m"
+	styleHeader    = "\x1b[1m"
+	styleDim       = "\x1b[2m"
+	styleError     = "\x1b[31m"
+	clearLine      = "\x1b[K"
+	cursorHome     = "\x1b[H"
+	clearRemaining = "\x1b[J"
+)
+
+// chrome is the number of lines around the list: title, description, header, status and help.
+const chrome = 5
+
+var basisTitles = map[rating.Basis]string{
+	rating.BasisBudget:   "бюджет",
+	rating.BasisContract: "контракт",
+}
+
+func (m *model) listHeight() int {
+	return m.height - chrome
+}
+
+// table aligns the header and the rows, the highlighted rows are drawn in the tracked style.
+type table struct {
+	header      string
+	rows        []string
+	highlighted []bool
+}
+
+func (t *table) add(highlighted bool, format string, args ...any) {
+	t.rows = append(t.rows, fmt.Sprintf(format, args...))
+	t.highlighted = append(t.highlighted, highlighted)
+}
+
+func (t *table) lines() []string {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, t.header)
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, row)
+	}
+	tw.Flush()
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+func (m *model) render(out io.Writer) {
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	w.WriteString(cursorHome)
+	line := func(style, s string) {
+		s = truncate(s, m.width)
+		if style != "" {
+			// pad so the style covers the whole row
+			s = style + s + strings.Repeat(" ", max(0, m.width-utf8.RuneCountInString(s))) + styleReset
+		}
+		w.WriteString(s + clearLine + "\r\n")
+	}
+
+	title, description, t := m.content()
+	line(styleHeader, "ИТМО рейтинги · "+string(m.degree)+" · "+title)
+	line("", description)
+
+	lines := t.lines()
+	line(styleDim, lines[0])
+	c := m.cursors[m.view()]
+	for i := c.offset; i < c.offset+m.listHeight(); i++ {
+		switch {
+		case i >= len(t.rows):
+			line("", "")
+		case i == c.row:
+			line(styleSelected, lines[i+1])
+		case t.highlighted[i]:
+			line(styleTracked, lines[i+1])
+		default:
+			line("", lines[i+1])
+		}
+	}
+
+	line(m.status())
+	help := "↑↓ выбор  Enter открыть  Esc назад  s студент  Tab отслеживаемые  d уровень  b основа  r обновить  q выход"
+	if m.input != nil {
+		help = "ID студента: " + string(*m.input) + "▏  Enter показать  Esc отмена"
+	}
+	w.WriteString(styleDim + truncate(help, m.width) + styleReset + clearLine + clearRemaining)
+}
+
+func (m *model) status() (string, string) {
+	var parts []string
+	switch {
+	case !m.loaded:
+		parts = append(parts, "загрузка рейтингов…")
+	case m.refreshing:
+		parts = append(parts, "обновление…")
+	}
+	if !m.updated.IsZero() {
+		parts = append(parts, "обновлено "+m.updated.Local().Format("02.01 15:04:05"))
+	}
+	if m.err != nil {
+		return styleError, strings.Join(append(parts, "ошибка: "+m.err.Error()), " · ")
+	}
+	return "", strings.Join(parts, " · ")
+}
+
+func (m *model) content() (title, description string, t table) {
+	switch m.view() {
+	case viewProgram:
+		return m.programContent()
+	case viewStudent:
+		return m.studentContent()
+	default:
+		return m.programsContent()
+	}
+}
+
+func (m *model) programsContent() (string, string, table) {
+	description := fmt.Sprintf("Программ: %d", len(m.programs))
+	if len(m.tracked) > 0 {
+		description += ", отслеживаются: " + strings.Join(m.tracked, ", ")
+	}
+
+	t := table{header: "ID\tПРОГРАММА\tБЮДЖЕТ\tМЕСТ С КВОТАМИ\tЦЕЛЕВАЯ\tКОНТРАКТ\tЗАЯВЛЕНИЙ\tОТСЛЕЖИВАЕМЫЕ"}
+	for i := range m.programs {
+		p := &m.programs[i]
+		tracked := 0
+		for _, e := range p.Entries {
+			if m.isTracked(e.SSPVOID) {
+				tracked++
+			}
+		}
+		t.add(tracked > 0, "%d\t%s\t%d\t%d\t%d\t%d\t%d\t%s",
+			p.Data.CompetitiveGroupID, p.Data.DirectionTitle, p.Data.BudgetMin, admission.Seats(p).Effective,
+			p.Data.TargetReception, p.Data.Contract, len(p.Entries), count(tracked))
+	}
+	return "Программы", description, t
+}
+
+func (m *model) programContent() (string, string, table) {
+	t := table{header: "ПОЗИЦИЯ\tПРИОРИТЕТ\tID\tСУММА\tВИ\tИД\tСОГЛАСИЕ\tСТАТУС"}
+	p := m.program()
+	if p == nil {
+		return fmt.Sprintf("Программа %d", m.programID), "программа не найдена", t
+	}
+
+	entries := p.List(m.basis)
+	description := fmt.Sprintf("%s, %s: мест %d, заявлений %d",
+		p.Data.DirectionTitle, basisTitles[m.basis], admission.ListSeats(p, m.basis), len(entries))
+	for _, e := range entries {
+		agreement := ""
+		if e.IsSendAgreement {
+			agreement = "да"
+		}
+		t.add(m.isTracked(e.SSPVOID), "%d\t%d\t%s\t%g\t%g\t%g\t%s\t%s",
+			e.Position, e.Priority, e.SSPVOID, e.TotalScores, e.ExamScores, e.IAScores, agreement, e.Status)
+	}
+	return fmt.Sprintf("Программа %d", p.Data.CompetitiveGroupID), description, t
+}
+
+func (m *model) studentContent() (string, string, table) {
+	t := table{header: "ПРИОРИТЕТ\tПРОГРАММА\tОСНОВА\tПОЗИЦИЯ\tМЕСТ\tЗАЯВЛЕНИЙ\tВЫШЕ С НИЗШИМ ПРИОРИТЕТОМ\tПРОГНОЗ"}
+	title := "Студент " + m.studentID
+	if m.summary == nil {
+		return title, "студент не найден в списках", t
+	}
+
+	for _, e := range m.summary.Entries {
+		projection := ""
+		if e.Basis == rating.BasisBudget {
+			projection = "не проходит"
+			if e.ProjectedPass {
+				projection = "проходит"
+			}
+			if e.ProjectedCutoff > 0 {
+				projection += fmt.Sprintf(", проходной %g", e.ProjectedCutoff)
+			}
+		}
+		t.add(e.ProjectedPass, "%d\t%s\t%s\t%d\t%d\t%d\t%d\t%s",
+			e.Priority, e.ProgramTitle, basisTitles[e.Basis], e.Position, e.Seats, e.TotalApplications,
+			e.LowerPriorityAhead, projection)
+	}
+	return title, fmt.Sprintf("Заявлений: %d, по приоритету", len(m.summary.Entries)), t
+}
+
+func count(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprint(n)
+}
+
+// truncate cuts s to width characters, the interface only draws single width text.
+func truncate(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
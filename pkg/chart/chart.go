@@ -0,0 +1,230 @@
+// Package chart draws time series line charts as SVG and PNG without external dependencies.
+package chart
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"time"
+)
+
+type Point struct {
+	X time.Time
+	Y float64
+}
+
+type Series struct {
+	Name   string
+	Color  color.RGBA
+	Dashed bool
+	Points []Point
+}
+
+// Panel is a plot area with its own Y axis, panels of a chart share the X axis and are stacked.
+type Panel struct {
+	Title  string
+	Series []Series
+	// Invert puts small values on top, as positions in a list are read.
+	Invert bool
+}
+
+type Chart struct {
+	Title  string
+	Width  int
+	Height int
+	Panels []Panel
+}
+
+const (
+	DefaultWidth  = 800
+	DefaultHeight = 500
+
+	marginLeft   = 56
+	marginRight  = 16
+	marginTop    = 60
+	marginBottom = 32
+	panelGap     = 40
+	xTicks       = 6
+	yTicks       = 5
+)
+
+var (
+	colorText = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	colorGrid = color.RGBA{0xe5, 0xe5, 0xe5, 0xff}
+	colorAxis = color.RGBA{0x99, 0x99, 0x99, 0xff}
+)
+
+// layout is the geometry shared by both renderers.
+type layout struct {
+	width, height int
+	minX, maxX    time.Time
+	xTicks        []time.Time
+	xFormat       string
+	panels        []panelLayout
+}
+
+type panelLayout struct {
+	Panel
+	top, bottom, left, right float64
+	minY, maxY               float64
+	yTicks                   []float64
+}
+
+func (c *Chart) layout() layout {
+	l := layout{width: c.Width, height: c.Height}
+	if l.width <= 0 {
+		l.width = DefaultWidth
+	}
+	if l.height <= 0 {
+		l.height = DefaultHeight
+	}
+
+	first := true
+	for _, panel := range c.Panels {
+		for _, s := range panel.Series {
+			for _, p := range s.Points {
+				if first || p.X.Before(l.minX) {
+					l.minX = p.X
+				}
+				if first || p.X.After(l.maxX) {
+					l.maxX = p.X
+				}
+				first = false
+			}
+		}
+	}
+	if !l.maxX.After(l.minX) {
+		// a single snapshot still gets a readable axis
+		l.minX = l.minX.Add(-12 * time.Hour)
+		l.maxX = l.maxX.Add(12 * time.Hour)
+	}
+	l.xTicks, l.xFormat = timeTicks(l.minX, l.maxX, xTicks)
+
+	n := max(1, len(c.Panels))
+	plotHeight := (float64(l.height-marginTop-marginBottom) - float64(n-1)*panelGap) / float64(n)
+	for i, panel := range c.Panels {
+		p := panelLayout{
+			Panel: panel,
+			left:  marginLeft,
+			right: float64(l.width - marginRight),
+			top:   marginTop + float64(i)*(plotHeight+panelGap),
+		}
+		p.bottom = p.top + plotHeight
+		p.minY, p.maxY = math.Inf(1), math.Inf(-1)
+		for _, s := range panel.Series {
+			for _, point := range s.Points {
+				p.minY = math.Min(p.minY, point.Y)
+				p.maxY = math.Max(p.maxY, point.Y)
+			}
+		}
+		if math.IsInf(p.minY, 1) {
+			p.minY, p.maxY = 0, 1
+		}
+		// keep lines off the frame
+		pad := (p.maxY - p.minY) * 0.05
+		p.minY, p.maxY = p.minY-pad, p.maxY+pad
+		p.yTicks, p.minY, p.maxY = niceTicks(p.minY, p.maxY, yTicks)
+		l.panels = append(l.panels, p)
+	}
+	return l
+}
+
+func (l *layout) x(t time.Time) float64 {
+	return marginLeft + float64(t.Sub(l.minX))/float64(l.maxX.Sub(l.minX))*float64(l.width-marginLeft-marginRight)
+}
+
+// label formats an X tick, hourly ticks show the date at midnight.
+func (l *layout) label(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 {
+		return t.Format("02.01")
+	}
+	return t.Format(l.xFormat)
+}
+
+func (p *panelLayout) y(v float64) float64 {
+	frac := (v - p.minY) / (p.maxY - p.minY)
+	if p.Invert {
+		return p.top + frac*(p.bottom-p.top)
+	}
+	return p.bottom - frac*(p.bottom-p.top)
+}
+
+// niceTicks rounds the range out to steps of 1, 2 or 5 times a power of ten.
+func niceTicks(lo, hi float64, n int) ([]float64, float64, float64) {
+	if hi-lo < 1 {
+		lo, hi = lo-0.5, hi+0.5
+	}
+	raw := (hi - lo) / float64(n)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude
+	for _, m := range []float64{1, 2, 5, 10} {
+		if step = m * magnitude; step >= raw {
+			break
+		}
+	}
+	lo = math.Floor(lo/step) * step
+	hi = math.Ceil(hi/step) * step
+
+	var ticks []float64
+	for v := lo; v <= hi+step/2; v += step {
+		ticks = append(ticks, math.Round(v/step)*step)
+	}
+	return ticks, lo, hi
+}
+
+// timeTicks picks day or hour aligned ticks and the format of their labels.
+func timeTicks(from, to time.Time, n int) ([]time.Time, string) {
+	span := to.Sub(from)
+	steps := []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour, 48 * time.Hour, 7 * 24 * time.Hour}
+	step := steps[len(steps)-1]
+	for _, s := range steps {
+		if span/s <= time.Duration(n) {
+			step = s
+			break
+		}
+	}
+	format := "02.01"
+	if step < 24*time.Hour {
+		format = "15:04"
+	}
+
+	start := from.Truncate(step)
+	if step >= 24*time.Hour {
+		y, m, d := from.Date()
+		start = time.Date(y, m, d, 0, 0, 0, 0, from.Location())
+	}
+	var ticks []time.Time
+	for t := start; !t.After(to); t = t.Add(step) {
+		if !t.Before(from) {
+			ticks = append(ticks, t)
+		}
+	}
+	return ticks, format
+}
+
+type Format string
+
+const (
+	FormatSVG Format = "svg"
+	FormatPNG Format = "png"
+)
+
+func (f Format) ContentType() string {
+	if f == FormatPNG {
+		return "image/png"
+	}
+	return "image/svg+xml"
+}
+
+// Write renders the chart in the format.
+func (c *Chart) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatSVG:
+		return c.SVG(w)
+	case FormatPNG:
+		return c.PNG(w)
+	default:
+		return fmt.Errorf("unknown chart format %q", format)
+	}
+}
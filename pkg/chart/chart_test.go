@@ -0,0 +1,232 @@
+package chart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"image/png"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var day = time.Date(2025, time.July, 20, 0, 0, 0, 0, time.UTC)
+
+func TestNiceTicks(t *testing.T) {
+	tests := []struct {
+		name   string
+		lo, hi float64
+		ticks  []float64
+	}{
+		{name: "round range", lo: 0, hi: 100, ticks: []float64{0, 20, 40, 60, 80, 100}},
+		{name: "rounded out", lo: 95, hi: 105.5, ticks: []float64{95, 100, 105, 110}},
+		{name: "positions", lo: 1, hi: 37, ticks: []float64{0, 10, 20, 30, 40}},
+		{name: "negative", lo: -12, hi: 7, ticks: []float64{-15, -10, -5, 0, 5, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticks, lo, hi := niceTicks(tt.lo, tt.hi, yTicks)
+			if !slices.Equal(ticks, tt.ticks) {
+				t.Errorf("got ticks %v, want %v", ticks, tt.ticks)
+			}
+			if lo != tt.ticks[0] || hi != tt.ticks[len(tt.ticks)-1] {
+				t.Errorf("got range %g..%g, want the first and last ticks", lo, hi)
+			}
+		})
+	}
+
+	// a flat series still gets a range around its value
+	ticks, lo, hi := niceTicks(80, 80, yTicks)
+	if len(ticks) < 2 || lo > 79.5 || hi < 80.5 {
+		t.Errorf("got ticks %v in %g..%g for a flat series", ticks, lo, hi)
+	}
+}
+
+func TestTimeTicks(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to time.Time
+		ticks    []time.Time
+		format   string
+	}{
+		{
+			name:   "hours",
+			from:   day.Add(10*time.Hour + 30*time.Minute),
+			to:     day.Add(15*time.Hour + 30*time.Minute),
+			ticks:  []time.Time{day.Add(11 * time.Hour), day.Add(12 * time.Hour), day.Add(13 * time.Hour), day.Add(14 * time.Hour), day.Add(15 * time.Hour)},
+			format: "15:04",
+		},
+		{
+			name:   "days",
+			from:   day,
+			to:     day.AddDate(0, 0, 6),
+			ticks:  []time.Time{day, day.AddDate(0, 0, 1), day.AddDate(0, 0, 2), day.AddDate(0, 0, 3), day.AddDate(0, 0, 4), day.AddDate(0, 0, 5), day.AddDate(0, 0, 6)},
+			format: "02.01",
+		},
+		{
+			// ticks start at midnight of the first day, the ones before the data are dropped
+			name:   "weeks",
+			from:   day.Add(6 * time.Hour),
+			to:     day.AddDate(0, 0, 30),
+			ticks:  []time.Time{day.AddDate(0, 0, 7), day.AddDate(0, 0, 14), day.AddDate(0, 0, 21), day.AddDate(0, 0, 28)},
+			format: "02.01",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticks, format := timeTicks(tt.from, tt.to, xTicks)
+			if !slices.EqualFunc(ticks, tt.ticks, time.Time.Equal) {
+				t.Errorf("got ticks %v, want %v", ticks, tt.ticks)
+			}
+			if format != tt.format {
+				t.Errorf("got format %q, want %q", format, tt.format)
+			}
+		})
+	}
+}
+
+func TestLayout(t *testing.T) {
+	c := &Chart{Panels: []Panel{
+		{Series: []Series{{Points: []Point{{X: day, Y: 1}, {X: day.Add(time.Hour), Y: 30}}}}, Invert: true},
+		{Series: []Series{{Points: []Point{{X: day, Y: 60}}}}},
+		{},
+	}}
+	l := c.layout()
+	if l.width != DefaultWidth || l.height != DefaultHeight {
+		t.Errorf("got %dx%d, want the default size", l.width, l.height)
+	}
+	if len(l.panels) != 3 {
+		t.Fatalf("got %d panels, want 3", len(l.panels))
+	}
+	for i := 1; i < len(l.panels); i++ {
+		if l.panels[i].top < l.panels[i-1].bottom+panelGap {
+			t.Errorf("panel %d overlaps the one above", i)
+		}
+	}
+	if bottom := l.panels[2].bottom; bottom > float64(l.height-marginBottom)+0.001 {
+		t.Errorf("last panel ends at %g, below the plot area", bottom)
+	}
+
+	// positions are read top down, scores bottom up
+	positions, scores := l.panels[0], l.panels[1]
+	if positions.y(1) >= positions.y(30) {
+		t.Error("position 1 drawn below position 30 on an inverted panel")
+	}
+	if scores.y(70) >= scores.y(50) {
+		t.Error("higher score drawn below a lower one")
+	}
+	if x := l.x(day); x != marginLeft {
+		t.Errorf("first snapshot at x %g, want %d", x, marginLeft)
+	}
+	if x := l.x(day.Add(time.Hour)); x != float64(l.width-marginRight) {
+		t.Errorf("last snapshot at x %g, want %d", x, l.width-marginRight)
+	}
+
+	// a single snapshot is centered on a day wide axis
+	single := &Chart{Panels: []Panel{{Series: []Series{{Points: []Point{{X: day, Y: 1}}}}}}}
+	l = single.layout()
+	if !l.minX.Equal(day.Add(-12*time.Hour)) || !l.maxX.Equal(day.Add(12*time.Hour)) {
+		t.Errorf("got axis %v..%v for a single snapshot", l.minX, l.maxX)
+	}
+}
+
+func sample() *Chart {
+	return &Chart{
+		Title:  `09.04.01 «R&D <ИТМО>»`,
+		Width:  400,
+		Height: 300,
+		Panels: []Panel{
+			{
+				Title:  "Позиция",
+				Invert: true,
+				Series: []Series{
+					{Name: "позиция", Color: color.RGBA{0x1f, 0x77, 0xb4, 0xff}, Points: []Point{{X: day, Y: 12}, {X: day.Add(6 * time.Hour), Y: 9}, {X: day.Add(12 * time.Hour), Y: 10}}},
+					{Name: "мест", Color: color.RGBA{0x99, 0x99, 0x99, 0xff}, Dashed: true, Points: []Point{{X: day, Y: 15}, {X: day.Add(12 * time.Hour), Y: 15}}},
+				},
+			},
+			{
+				Title:  "Баллы",
+				Series: []Series{{Name: "проходной балл", Color: color.RGBA{0xd6, 0x27, 0x28, 0xff}, Points: []Point{{X: day, Y: 71}, {X: day.Add(12 * time.Hour), Y: 74.5}}}},
+			},
+		},
+	}
+}
+
+func TestSVG(t *testing.T) {
+	var b bytes.Buffer
+	if err := sample().Write(&b, FormatSVG); err != nil {
+		t.Fatal(err)
+	}
+
+	var texts []string
+	elements := make(map[string]int)
+	d := xml.NewDecoder(&b)
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("SVG is not well formed: %v", err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			elements[token.Name.Local]++
+		case xml.CharData:
+			texts = append(texts, string(token))
+		}
+	}
+	if !slices.Contains(texts, `09.04.01 «R&D <ИТМО>»`) {
+		t.Errorf("no chart title in %q", texts)
+	}
+	for _, name := range []string{"Позиция", "Баллы", "позиция", "мест", "проходной балл"} {
+		if !slices.Contains(texts, name) {
+			t.Errorf("no %q in %q", name, texts)
+		}
+	}
+	if elements["polyline"] != 3 {
+		t.Errorf("got %d lines, want one per series", elements["polyline"])
+	}
+	// dashed series are drawn without points
+	if elements["circle"] != 5 {
+		t.Errorf("got %d points, want 5", elements["circle"])
+	}
+}
+
+func TestPNG(t *testing.T) {
+	c := sample()
+	var b bytes.Buffer
+	if err := c.Write(&b, FormatPNG); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != c.Width || size.Y != c.Height {
+		t.Errorf("got %v image, want %dx%d", size, c.Width, c.Height)
+	}
+
+	colors := make(map[color.RGBA]bool)
+	for y := 0; y < c.Height; y++ {
+		for x := 0; x < c.Width; x++ {
+			colors[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)] = true
+		}
+	}
+	for _, panel := range c.Panels {
+		for _, s := range panel.Series {
+			if !colors[s.Color] {
+				t.Errorf("series %q isn't drawn", s.Name)
+			}
+		}
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	err := sample().Write(io.Discard, "gif")
+	if err == nil || !strings.Contains(err.Error(), "gif") {
+		t.Errorf("got %v, want an unknown format error", err)
+	}
+}
@@ -0,0 +1,110 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+// pngScale enlarges the pixel font, 2 makes labels 6x10 pixels.
+const pngScale = 2
+
+// PNG writes the chart as an image. Only the axis labels are drawn as text, the built-in font has
+// no letters, so titles and legends are left to the caller (e.g. a photo caption).
+func (c *Chart) PNG(w io.Writer) error {
+	l := c.layout()
+	img := image.NewRGBA(image.Rect(0, 0, l.width, l.height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	for _, p := range l.panels {
+		for _, v := range p.yTicks {
+			y := p.y(v)
+			line(img, p.left, y, p.right, y, colorGrid, 1, false)
+			label := formatValue(v)
+			text(img, int(p.left)-6-textWidth(label), int(y)-glyphHeight*pngScale/2, label, colorText)
+		}
+		for _, t := range l.xTicks {
+			x := l.x(t)
+			line(img, x, p.top, x, p.bottom, colorGrid, 1, false)
+			label := l.label(t)
+			text(img, int(x)-textWidth(label)/2, int(p.bottom)+6, label, colorText)
+		}
+		line(img, p.left, p.top, p.right, p.top, colorAxis, 1, false)
+		line(img, p.left, p.bottom, p.right, p.bottom, colorAxis, 1, false)
+		line(img, p.left, p.top, p.left, p.bottom, colorAxis, 1, false)
+		line(img, p.right, p.top, p.right, p.bottom, colorAxis, 1, false)
+
+		for _, s := range p.Series {
+			for i := 1; i < len(s.Points); i++ {
+				a, b := s.Points[i-1], s.Points[i]
+				line(img, l.x(a.X), p.y(a.Y), l.x(b.X), p.y(b.Y), s.Color, 2, s.Dashed)
+			}
+			if !s.Dashed {
+				for _, point := range s.Points {
+					dot(img, l.x(point.X), p.y(point.Y), 3, s.Color)
+				}
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// line draws a segment by stepping along its longer side, dashed lines skip every other 6 pixels.
+func line(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA, width int, dashed bool) {
+	steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0)))
+	for i := 0; i <= steps; i++ {
+		if dashed && (i/6)%2 == 1 {
+			continue
+		}
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		x := int(math.Round(x0 + t*(x1-x0)))
+		y := int(math.Round(y0 + t*(y1-y0)))
+		for dx := 0; dx < width; dx++ {
+			for dy := 0; dy < width; dy++ {
+				img.SetRGBA(x+dx-width/2, y+dy-width/2, c)
+			}
+		}
+	}
+}
+
+func dot(img *image.RGBA, cx, cy float64, r int, c color.RGBA) {
+	for dx := -r; dx <= r; dx++ {
+		for dy := -r; dy <= r; dy++ {
+			if dx*dx+dy*dy <= r*r {
+				img.SetRGBA(int(math.Round(cx))+dx, int(math.Round(cy))+dy, c)
+			}
+		}
+	}
+}
+
+func textWidth(s string) int {
+	return len([]rune(s)) * (glyphWidth + 1) * pngScale
+}
+
+func text(img *image.RGBA, x, y int, s string, c color.RGBA) {
+	for _, r := range s {
+		glyph, ok := glyphs[r]
+		if ok {
+			for row, bits := range glyph {
+				for col, bit := range bits {
+					if bit != '#' {
+						continue
+					}
+					for dx := 0; dx < pngScale; dx++ {
+						for dy := 0; dy < pngScale; dy++ {
+							img.SetRGBA(x+col*pngScale+dx, y+row*pngScale+dy, c)
+						}
+					}
+				}
+			}
+		}
+		x += (glyphWidth + 1) * pngScale
+	}
+}
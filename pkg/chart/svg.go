@@ -0,0 +1,86 @@
+package chart
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SVG writes the chart with titles and a legend per panel.
+func (c *Chart) SVG(out io.Writer) error {
+	l := c.layout()
+	w := bufio.NewWriter(out)
+
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n",
+		l.width, l.height, l.width, l.height)
+	fmt.Fprintf(w, `<rect width="100%%" height="100%%" fill="#fff"/>`+"\n")
+	fmt.Fprintf(w, `<text x="%d" y="22" font-size="16" font-weight="bold" fill="%s">%s</text>`+"\n",
+		marginLeft, hex(colorText), html.EscapeString(c.Title))
+
+	for _, p := range l.panels {
+		fmt.Fprintf(w, `<text x="%g" y="%g" font-weight="bold" fill="%s">%s</text>`+"\n",
+			p.left, p.top-8, hex(colorText), html.EscapeString(p.Title))
+
+		legendX := p.right
+		for i := len(p.Series) - 1; i >= 0; i-- {
+			s := p.Series[i]
+			legendX -= float64(len([]rune(s.Name)))*7 + 32
+			fmt.Fprintf(w, `<line x1="%g" y1="%g" x2="%g" y2="%g" stroke="%s" stroke-width="2"%s/>`+"\n",
+				legendX, p.top-12, legendX+20, p.top-12, hex(s.Color), dash(s.Dashed))
+			fmt.Fprintf(w, `<text x="%g" y="%g" fill="%s">%s</text>`+"\n",
+				legendX+24, p.top-8, hex(colorText), html.EscapeString(s.Name))
+		}
+
+		for _, v := range p.yTicks {
+			y := p.y(v)
+			fmt.Fprintf(w, `<line x1="%g" y1="%g" x2="%g" y2="%g" stroke="%s"/>`+"\n", p.left, y, p.right, y, hex(colorGrid))
+			fmt.Fprintf(w, `<text x="%g" y="%g" text-anchor="end" fill="%s">%s</text>`+"\n",
+				p.left-6, y+4, hex(colorText), formatValue(v))
+		}
+		for _, t := range l.xTicks {
+			x := l.x(t)
+			fmt.Fprintf(w, `<line x1="%g" y1="%g" x2="%g" y2="%g" stroke="%s"/>`+"\n", x, p.top, x, p.bottom, hex(colorGrid))
+			fmt.Fprintf(w, `<text x="%g" y="%g" text-anchor="middle" fill="%s">%s</text>`+"\n",
+				x, p.bottom+16, hex(colorText), l.label(t))
+		}
+		fmt.Fprintf(w, `<rect x="%g" y="%g" width="%g" height="%g" fill="none" stroke="%s"/>`+"\n",
+			p.left, p.top, p.right-p.left, p.bottom-p.top, hex(colorAxis))
+
+		for _, s := range p.Series {
+			points := make([]string, 0, len(s.Points))
+			for _, point := range s.Points {
+				points = append(points, fmt.Sprintf("%.1f,%.1f", l.x(point.X), p.y(point.Y)))
+			}
+			fmt.Fprintf(w, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"%s/>`+"\n",
+				strings.Join(points, " "), hex(s.Color), dash(s.Dashed))
+			if !s.Dashed {
+				for _, point := range s.Points {
+					fmt.Fprintf(w, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"><title>%s: %s</title></circle>`+"\n",
+						l.x(point.X), p.y(point.Y), hex(s.Color), point.X.Format("02.01 15:04"), formatValue(point.Y))
+				}
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "</svg>")
+	return w.Flush()
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func dash(dashed bool) string {
+	if dashed {
+		return ` stroke-dasharray="6 4"`
+	}
+	return ""
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -2,15 +2,18 @@ package middleware
 
 import (
 	"net/http"
+	"sync"
 	"time"
 )
 
 type RateLimiter struct {
 	next   http.Handler
 	tokens chan struct{}
+	done   chan struct{}
+	once   sync.Once
 }
 
-func NewRateLimiter(next http.Handler, rps, burst int) http.Handler {
+func NewRateLimiter(next http.Handler, rps, burst int) *RateLimiter {
 	if rps <= 0 {
 		rps = 1
 	}
@@ -20,6 +23,7 @@ func NewRateLimiter(next http.Handler, rps, burst int) http.Handler {
 	rl := &RateLimiter{
 		next:   next,
 		tokens: make(chan struct{}, burst),
+		done:   make(chan struct{}),
 	}
 
 	for i := 0; i < burst; i++ {
@@ -30,11 +34,16 @@ func NewRateLimiter(next http.Handler, rps, burst int) http.Handler {
 	ticker := time.NewTicker(interval)
 	go func() {
 		defer ticker.Stop()
-		for range ticker.C {
+		for {
 			select {
-			case rl.tokens <- struct{}{}:
-			default:
-				// bucket full; drop token
+			case <-rl.done:
+				return
+			case <-ticker.C:
+				select {
+				case rl.tokens <- struct{}{}:
+				default:
+					// bucket full; drop token
+				}
 			}
 		}
 	}()
@@ -42,6 +51,13 @@ func NewRateLimiter(next http.Handler, rps, burst int) http.Handler {
 	return rl
 }
 
+// Stop terminates the token refill goroutine. It is safe to call Stop more than once.
+func (rl *RateLimiter) Stop() {
+	rl.once.Do(func() {
+		close(rl.done)
+	})
+}
+
 func (rl *RateLimiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	select {
 	case <-rl.tokens:
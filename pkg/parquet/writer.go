@@ -0,0 +1,412 @@
+// Package parquet writes flat Apache Parquet files.
+//
+// It supports the subset the rating dumps need: required and optional columns of booleans,
+// integers, doubles, UTF-8 strings and millisecond timestamps, PLAIN encoded and uncompressed,
+// one data page per column chunk. Nested schemas, compression and dictionaries are not supported.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+type Type int
+
+const (
+	Boolean Type = iota
+	Int32
+	Int64
+	Double
+	String
+	// Timestamp is stored as INT64 milliseconds since the Unix epoch, UTC.
+	Timestamp
+)
+
+func (t Type) String() string {
+	switch t {
+	case Boolean:
+		return "boolean"
+	case Int32:
+		return "int32"
+	case Int64:
+		return "int64"
+	case Double:
+		return "double"
+	case String:
+		return "string"
+	case Timestamp:
+		return "timestamp[ms, UTC]"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// physical types, repetition types, converted types, encodings and codecs of parquet.thrift
+const (
+	physicalBoolean   = 0
+	physicalInt32     = 1
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+	pageTypeData      = 0
+)
+
+var magic = []byte("PAR1")
+
+// DefaultRowGroupSize is the number of rows buffered before a row group is written.
+const DefaultRowGroupSize = 100_000
+
+type Column struct {
+	Name     string
+	Type     Type
+	Optional bool
+}
+
+func (c Column) physical() int32 {
+	switch c.Type {
+	case Boolean:
+		return physicalBoolean
+	case Int32:
+		return physicalInt32
+	case Int64, Timestamp:
+		return physicalInt64
+	case Double:
+		return physicalDouble
+	default:
+		return physicalByteArray
+	}
+}
+
+type Option func(*Writer)
+
+// WithMetadata adds a key-value pair to the file footer.
+func WithMetadata(key, value string) Option {
+	return func(w *Writer) {
+		w.metadata = append(w.metadata, [2]string{key, value})
+	}
+}
+
+func WithRowGroupSize(rows int) Option {
+	return func(w *Writer) {
+		if rows > 0 {
+			w.rowGroupSize = rows
+		}
+	}
+}
+
+// Writer buffers rows column by column and writes them as row groups.
+// Close must be called to write the footer, it doesn't close the underlying writer.
+type Writer struct {
+	out          io.Writer
+	offset       int64
+	columns      []Column
+	chunks       []chunk
+	rows         int
+	rowGroups    []rowGroup
+	rowGroupSize int
+	metadata     [][2]string
+	err          error
+}
+
+// chunk is the buffered part of a column in the current row group.
+type chunk struct {
+	present []bool // one per row, only for optional columns
+	bits    []bool // values of boolean columns, bit packed on flush
+	values  bytes.Buffer
+}
+
+type rowGroup struct {
+	columns []columnChunk
+	rows    int
+	size    int64
+}
+
+type columnChunk struct {
+	offset int64
+	size   int64
+	values int
+}
+
+func NewWriter(out io.Writer, columns []Column, options ...Option) *Writer {
+	w := &Writer{
+		out:          out,
+		columns:      columns,
+		chunks:       make([]chunk, len(columns)),
+		rowGroupSize: DefaultRowGroupSize,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	w.write(magic)
+	return w
+}
+
+// Write appends a row. Values follow the columns: bool, int32, int or int64, float64, string
+// and time.Time, nil for a missing value of an optional column.
+func (w *Writer) Write(row []any) error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("row has %d values, schema has %d columns", len(row), len(w.columns))
+	}
+	for i, v := range row {
+		// a half written row would misalign the columns, so the writer can't be used any more
+		if err := w.chunks[i].append(w.columns[i], v); err != nil {
+			w.err = fmt.Errorf("column %s: %w", w.columns[i].Name, err)
+			return w.err
+		}
+	}
+	w.rows++
+	if w.rows >= w.rowGroupSize {
+		w.flush()
+	}
+	return w.err
+}
+
+func (c *chunk) append(column Column, v any) error {
+	if v == nil {
+		if !column.Optional {
+			return errors.New("missing value of a required column")
+		}
+		c.present = append(c.present, false)
+		return nil
+	}
+	if column.Optional {
+		c.present = append(c.present, true)
+	}
+
+	var ok bool
+	switch column.Type {
+	case Boolean:
+		var b bool
+		if b, ok = v.(bool); ok {
+			c.bits = append(c.bits, b)
+		}
+	case Int32:
+		var n int32
+		if n, ok = v.(int32); !ok {
+			var i int
+			if i, ok = v.(int); ok {
+				if i < math.MinInt32 || i > math.MaxInt32 {
+					return fmt.Errorf("value %d overflows int32", i)
+				}
+				n = int32(i)
+			}
+		}
+		if ok {
+			c.values.Write(binary.LittleEndian.AppendUint32(nil, uint32(n)))
+		}
+	case Int64:
+		var n int64
+		if n, ok = v.(int64); !ok {
+			var i int
+			i, ok = v.(int)
+			n = int64(i)
+		}
+		if ok {
+			c.values.Write(binary.LittleEndian.AppendUint64(nil, uint64(n)))
+		}
+	case Double:
+		var f float64
+		if f, ok = v.(float64); ok {
+			c.values.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(f)))
+		}
+	case String:
+		var s string
+		if s, ok = v.(string); ok {
+			c.values.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(s))))
+			c.values.WriteString(s)
+		}
+	case Timestamp:
+		var t time.Time
+		if t, ok = v.(time.Time); ok {
+			c.values.Write(binary.LittleEndian.AppendUint64(nil, uint64(t.UnixMilli())))
+		}
+	}
+	if !ok {
+		return fmt.Errorf("unexpected %T value for %s column", v, column.Type)
+	}
+	return nil
+}
+
+func (w *Writer) write(p []byte) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.out.Write(p)
+	w.offset += int64(n)
+	if err != nil {
+		w.err = fmt.Errorf("failed to write parquet: %w", err)
+	}
+}
+
+// flush writes the buffered rows as a row group.
+func (w *Writer) flush() {
+	if w.rows == 0 || w.err != nil {
+		return
+	}
+
+	group := rowGroup{rows: w.rows}
+	for i, column := range w.columns {
+		c := &w.chunks[i]
+
+		var page bytes.Buffer
+		if column.Optional {
+			levels := bitPacked(c.present)
+			page.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(levels))))
+			page.Write(levels)
+		}
+		if column.Type == Boolean {
+			page.Write(packBits(c.bits))
+		} else {
+			page.Write(c.values.Bytes())
+		}
+
+		var header thriftWriter
+		header.structBegin()
+		header.i32(1, pageTypeData)
+		header.i32(2, int32(page.Len()))
+		header.i32(3, int32(page.Len()))
+		header.structField(5)
+		header.i32(1, int32(w.rows))
+		header.i32(2, encodingPlain)
+		header.i32(3, encodingRLE)
+		header.i32(4, encodingRLE)
+		header.structEnd()
+		header.structEnd()
+
+		offset := w.offset
+		w.write(header.buf)
+		w.write(page.Bytes())
+
+		size := w.offset - offset
+		group.columns = append(group.columns, columnChunk{offset: offset, size: size, values: w.rows})
+		group.size += size
+		*c = chunk{}
+	}
+
+	w.rowGroups = append(w.rowGroups, group)
+	w.rows = 0
+}
+
+// Close writes the remaining rows and the footer.
+func (w *Writer) Close() error {
+	w.flush()
+	if w.err != nil {
+		return w.err
+	}
+
+	var t thriftWriter
+	t.structBegin()
+	t.i32(1, 1)
+
+	t.list(2, thriftStruct, len(w.columns)+1)
+	t.structBegin()
+	t.string(4, "schema")
+	t.i32(5, int32(len(w.columns)))
+	t.structEnd()
+	for _, column := range w.columns {
+		t.structBegin()
+		t.i32(1, column.physical())
+		repetition := int32(repetitionRequired)
+		if column.Optional {
+			repetition = repetitionOptional
+		}
+		t.i32(3, repetition)
+		t.string(4, column.Name)
+		switch column.Type {
+		case String:
+			t.i32(6, convertedUTF8)
+		case Timestamp:
+			t.i32(6, convertedTimestampMillis)
+		}
+		t.structEnd()
+	}
+
+	rows := 0
+	for _, group := range w.rowGroups {
+		rows += group.rows
+	}
+	t.i64(3, int64(rows))
+
+	t.list(4, thriftStruct, len(w.rowGroups))
+	for _, group := range w.rowGroups {
+		t.structBegin()
+		t.list(1, thriftStruct, len(group.columns))
+		for i, c := range group.columns {
+			column := w.columns[i]
+			t.structBegin()
+			t.i64(2, c.offset)
+			t.structField(3)
+			t.i32(1, column.physical())
+			t.list(2, thriftI32, 2)
+			t.zigzag(encodingPlain)
+			t.zigzag(encodingRLE)
+			t.list(3, thriftBinary, 1)
+			t.rawString(column.Name)
+			t.i32(4, codecUncompressed)
+			t.i64(5, int64(c.values))
+			t.i64(6, c.size)
+			t.i64(7, c.size)
+			t.i64(9, c.offset)
+			t.structEnd()
+			t.structEnd()
+		}
+		t.i64(2, group.size)
+		t.i64(3, int64(group.rows))
+		t.structEnd()
+	}
+
+	if len(w.metadata) > 0 {
+		t.list(5, thriftStruct, len(w.metadata))
+		for _, kv := range w.metadata {
+			t.structBegin()
+			t.string(1, kv[0])
+			t.string(2, kv[1])
+			t.structEnd()
+		}
+	}
+	t.string(6, "itmo-ratings")
+	t.structEnd()
+
+	w.write(t.buf)
+	w.write(binary.LittleEndian.AppendUint32(nil, uint32(len(t.buf))))
+	w.write(magic)
+	return w.err
+}
+
+// bitPacked encodes definition levels of bit width 1 as a single bit-packed run
+// of the RLE/bit-packing hybrid encoding.
+func bitPacked(levels []bool) []byte {
+	groups := (len(levels) + 7) / 8
+	out := binary.AppendUvarint(nil, uint64(groups)<<1|1)
+	return append(out, packBits(levels)...)
+}
+
+// packBits packs values LSB first, as PLAIN booleans and bit-packed runs are stored.
+func packBits(values []bool) []byte {
+	out := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			out[i/8] |= 1 << (i % 8)
+		}
+	}
+	return out
+}
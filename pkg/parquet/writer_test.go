@@ -0,0 +1,425 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// The test reads files back with its own decoder of the format: Thrift compact structs as maps of
+// field IDs, data page v1 with RLE/bit-packed definition levels and PLAIN values.
+
+var testColumns = []Column{
+	{Name: "programId", Type: Int32},
+	{Name: "studentId", Type: String},
+	{Name: "agreement", Type: Boolean, Optional: true},
+	{Name: "total", Type: Double},
+	{Name: "priority", Type: Int64, Optional: true},
+	{Name: "contest", Type: String, Optional: true},
+	{Name: "updated", Type: Timestamp, Optional: true},
+	{Name: "top", Type: Boolean},
+}
+
+func testRows() [][]any {
+	updated := time.Date(2025, time.July, 26, 17, 43, 0, 0, time.FixedZone("MSK", 3*60*60))
+	var rows [][]any
+	for i := range 11 {
+		row := []any{
+			int32(1000 + i%3),
+			fmt.Sprintf("40000%02d", i),
+			i%3 == 0,
+			float64(300 - i*7),
+			int64(i%4 + 1),
+			"общий конкурс",
+			updated.Add(time.Duration(i) * time.Minute),
+			i%2 == 1,
+		}
+		// nulls at different positions of the bit-packed groups, one right after the first eight
+		if i%4 == 2 {
+			row[2] = nil
+		}
+		if i == 8 || i == 0 {
+			row[4] = nil
+		}
+		if i >= 5 {
+			row[5] = nil
+		}
+		if i == 9 {
+			row[6] = nil
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	for _, groupSize := range []int{DefaultRowGroupSize, 4} {
+		t.Run(fmt.Sprintf("row groups of %d", groupSize), func(t *testing.T) {
+			rows := testRows()
+			var buf bytes.Buffer
+			w := NewWriter(&buf, testColumns, WithRowGroupSize(groupSize), WithMetadata("degree", "master"))
+			for _, row := range rows {
+				if err := w.Write(row); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			file := buf.Bytes()
+			meta := readFooter(t, file)
+			if meta.i(1) != 1 || meta.i(3) != int64(len(rows)) || meta.str(6) != "itmo-ratings" {
+				t.Errorf("got version %d, %d rows, created by %q", meta.i(1), meta.i(3), meta.str(6))
+			}
+			kv := meta.list(5)
+			if len(kv) != 1 || kv[0].(thriftStruct_).str(1) != "degree" || kv[0].(thriftStruct_).str(2) != "master" {
+				t.Errorf("got key-value metadata %v", kv)
+			}
+			checkSchema(t, meta.list(2))
+
+			got := make([][]any, len(rows))
+			for i := range got {
+				got[i] = make([]any, len(testColumns))
+			}
+			groups := meta.list(4)
+			if want := (len(rows) + groupSize - 1) / groupSize; len(groups) != want {
+				t.Fatalf("got %d row groups, want %d", len(groups), want)
+			}
+			first := 0
+			for _, g := range groups {
+				group := g.(thriftStruct_)
+				n := int(group.i(3))
+				var size int64
+				for c, cc := range group.list(1) {
+					chunk := cc.(thriftStruct_)
+					md := chunk.strct(3)
+					if chunk.i(2) != md.i(9) {
+						t.Errorf("column %d: file offset %d, data page offset %d", c, chunk.i(2), md.i(9))
+					}
+					if md.i(1) != int64(testColumns[c].physical()) || md.i(4) != codecUncompressed || md.i(5) != int64(n) {
+						t.Errorf("column %d: got type %d, codec %d, %d values", c, md.i(1), md.i(4), md.i(5))
+					}
+					if path := md.list(3); len(path) != 1 || string(path[0].([]byte)) != testColumns[c].Name {
+						t.Errorf("column %d: got path %q", c, path)
+					}
+					values, chunkSize := readPage(t, file, md.i(9), testColumns[c], n)
+					if chunkSize != md.i(7) || chunkSize != md.i(6) {
+						t.Errorf("column %d: chunk is %d bytes, metadata says %d/%d", c, chunkSize, md.i(6), md.i(7))
+					}
+					size += chunkSize
+					for r, v := range values {
+						got[first+r][c] = v
+					}
+				}
+				if group.i(2) != size {
+					t.Errorf("row group is %d bytes, metadata says %d", size, group.i(2))
+				}
+				first += n
+			}
+
+			for i := range rows {
+				if ts, ok := rows[i][6].(time.Time); ok {
+					rows[i][6] = ts.UnixMilli()
+				}
+				if !reflect.DeepEqual(got[i], rows[i]) {
+					t.Errorf("row %d: got %v, want %v", i, got[i], rows[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, testColumns)
+	if err := w.Write([]any{int32(1)}); err == nil {
+		t.Error("short row accepted")
+	}
+	row := testRows()[0]
+	row[1] = nil
+	if err := w.Write(row); err == nil {
+		t.Error("missing value of a required column accepted")
+	}
+	// the writer is unusable after a half written row
+	if err := w.Write(testRows()[1]); err == nil {
+		t.Error("row accepted after a failed one")
+	}
+
+	w = NewWriter(&buf, []Column{{Name: "n", Type: Int32}})
+	if err := w.Write([]any{math.MaxInt32 + 1}); err == nil {
+		t.Error("int32 overflow accepted")
+	}
+}
+
+func checkSchema(t *testing.T, schema []any) {
+	t.Helper()
+	if len(schema) != len(testColumns)+1 {
+		t.Fatalf("got %d schema elements, want %d", len(schema), len(testColumns)+1)
+	}
+	root := schema[0].(thriftStruct_)
+	if root.i(5) != int64(len(testColumns)) {
+		t.Errorf("root has %d children", root.i(5))
+	}
+	for i, column := range testColumns {
+		element := schema[i+1].(thriftStruct_)
+		repetition := int64(repetitionRequired)
+		if column.Optional {
+			repetition = repetitionOptional
+		}
+		converted, hasConverted := element[6]
+		if element.str(4) != column.Name || element.i(1) != int64(column.physical()) || element.i(3) != repetition {
+			t.Errorf("column %s: got %v", column.Name, element)
+		}
+		switch column.Type {
+		case String:
+			if converted != int64(convertedUTF8) {
+				t.Errorf("column %s: got converted type %v, want UTF8", column.Name, converted)
+			}
+		case Timestamp:
+			if converted != int64(convertedTimestampMillis) {
+				t.Errorf("column %s: got converted type %v, want TIMESTAMP_MILLIS", column.Name, converted)
+			}
+		default:
+			if hasConverted {
+				t.Errorf("column %s: unexpected converted type %v", column.Name, converted)
+			}
+		}
+	}
+}
+
+func readFooter(t *testing.T, file []byte) thriftStruct_ {
+	t.Helper()
+	if !bytes.HasPrefix(file, magic) || !bytes.HasSuffix(file, magic) {
+		t.Fatal("file doesn't start and end with PAR1")
+	}
+	size := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := file[len(file)-8-size : len(file)-8]
+	r := &thriftReader{buf: footer}
+	meta, err := r.readStruct()
+	if err != nil {
+		t.Fatalf("failed to decode footer: %v", err)
+	}
+	if r.pos != len(footer) {
+		t.Fatalf("footer has %d bytes after the metadata", len(footer)-r.pos)
+	}
+	return meta
+}
+
+// readPage decodes the data page at offset, it returns the values with nil for nulls and the size
+// of the page with its header.
+func readPage(t *testing.T, file []byte, offset int64, column Column, rows int) ([]any, int64) {
+	t.Helper()
+	r := &thriftReader{buf: file[offset:]}
+	header, err := r.readStruct()
+	if err != nil {
+		t.Fatalf("column %s: failed to decode page header: %v", column.Name, err)
+	}
+	data := header.strct(5)
+	if header.i(1) != pageTypeData || data.i(1) != int64(rows) || data.i(2) != encodingPlain || data.i(3) != encodingRLE {
+		t.Fatalf("column %s: got page header %v", column.Name, header)
+	}
+	if header.i(2) != header.i(3) {
+		t.Errorf("column %s: uncompressed page of %d bytes compressed to %d", column.Name, header.i(2), header.i(3))
+	}
+	page := file[offset+int64(r.pos) : offset+int64(r.pos)+header.i(3)]
+
+	present := make([]bool, rows)
+	for i := range present {
+		present[i] = true
+	}
+	if column.Optional {
+		length := binary.LittleEndian.Uint32(page)
+		present = decodeLevels(t, page[4:4+length], rows)
+		page = page[4+length:]
+	}
+
+	var values []any
+	bit := 0
+	for _, ok := range present {
+		if !ok {
+			values = append(values, nil)
+			continue
+		}
+		switch column.Type {
+		case Boolean:
+			values = append(values, page[bit/8]&(1<<(bit%8)) != 0)
+			bit++
+		case Int32:
+			values = append(values, int32(binary.LittleEndian.Uint32(page)))
+			page = page[4:]
+		case Int64, Timestamp:
+			values = append(values, int64(binary.LittleEndian.Uint64(page)))
+			page = page[8:]
+		case Double:
+			values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(page)))
+			page = page[8:]
+		case String:
+			n := binary.LittleEndian.Uint32(page)
+			values = append(values, string(page[4:4+n]))
+			page = page[4+n:]
+		}
+	}
+	if column.Type == Boolean {
+		page = page[(bit+7)/8:]
+	}
+	if len(page) != 0 {
+		t.Errorf("column %s: %d bytes left in the page", column.Name, len(page))
+	}
+	return values, int64(r.pos) + header.i(3)
+}
+
+// decodeLevels reads definition levels of bit width 1 in the RLE/bit-packing hybrid encoding.
+func decodeLevels(t *testing.T, buf []byte, rows int) []bool {
+	t.Helper()
+	var levels []bool
+	for len(levels) < rows {
+		header, n := binary.Uvarint(buf)
+		if n <= 0 {
+			t.Fatalf("bad run header in %x", buf)
+		}
+		buf = buf[n:]
+		if header&1 == 1 {
+			groups := int(header >> 1)
+			for i := range groups * 8 {
+				levels = append(levels, buf[i/8]&(1<<(i%8)) != 0)
+			}
+			buf = buf[groups:]
+		} else {
+			for range header >> 1 {
+				levels = append(levels, buf[0] == 1)
+			}
+			buf = buf[1:]
+		}
+	}
+	if len(buf) != 0 {
+		t.Fatalf("%d bytes left after the levels", len(buf))
+	}
+	// bit-packed runs are padded to a multiple of eight
+	return levels[:rows]
+}
+
+// thriftStruct_ is a decoded struct by field ID: int64 for integers, []byte for binary,
+// []any for lists and thriftStruct_ for nested structs.
+type thriftStruct_ map[int16]any
+
+func (s thriftStruct_) i(id int16) int64 {
+	v, _ := s[id].(int64)
+	return v
+}
+
+func (s thriftStruct_) str(id int16) string {
+	v, _ := s[id].([]byte)
+	return string(v)
+}
+
+func (s thriftStruct_) list(id int16) []any {
+	v, _ := s[id].([]any)
+	return v
+}
+
+func (s thriftStruct_) strct(id int16) thriftStruct_ {
+	v, _ := s[id].(thriftStruct_)
+	return v
+}
+
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errors.New("bad varint")
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftReader) zigzag() (int64, error) {
+	v, err := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1), err
+}
+
+func (r *thriftReader) byte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errors.New("unexpected end")
+	}
+	r.pos++
+	return r.buf[r.pos-1], nil
+}
+
+func (r *thriftReader) readStruct() (thriftStruct_, error) {
+	s := thriftStruct_{}
+	var last int16
+	for {
+		b, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if b == 0 {
+			return s, nil
+		}
+		id := last + int16(b>>4)
+		if b>>4 == 0 {
+			v, err := r.zigzag()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		if s[id], err = r.value(b & 0x0f); err != nil {
+			return nil, fmt.Errorf("field %d: %w", id, err)
+		}
+		last = id
+	}
+}
+
+func (r *thriftReader) value(typ byte) (any, error) {
+	switch typ {
+	case 1, 2: // booleans are encoded in the type
+		return typ == 1, nil
+	case 3:
+		b, err := r.byte()
+		return int64(int8(b)), err
+	case 4, thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n, err := r.uvarint()
+		if err != nil || r.pos+int(n) > len(r.buf) {
+			return nil, errors.New("bad binary")
+		}
+		r.pos += int(n)
+		return r.buf[r.pos-int(n) : r.pos], nil
+	case thriftList:
+		header, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		size := int(header >> 4)
+		if size == 15 {
+			n, err := r.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			size = int(n)
+		}
+		list := make([]any, size)
+		for i := range list {
+			if list[i], err = r.value(header & 0x0f); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		return nil, fmt.Errorf("unsupported type %d", typ)
+	}
+}
@@ -0,0 +1,102 @@
+// Package term puts a terminal into raw mode and decodes the keys read from it.
+package term
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+var ErrUnsupported = errors.New("terminal is not supported on this platform")
+
+type KeyCode int
+
+const (
+	KeyRune KeyCode = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyEscape
+	KeyTab
+	KeyBackspace
+)
+
+// Key is a pressed key, Rune is set for KeyRune only.
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+var sequences = map[string]KeyCode{
+	"\x1b[A":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1b[C":  KeyRight,
+	"\x1b[D":  KeyLeft,
+	"\x1bOA":  KeyUp,
+	"\x1bOB":  KeyDown,
+	"\x1bOC":  KeyRight,
+	"\x1bOD":  KeyLeft,
+	"\x1b[5~": KeyPageUp,
+	"\x1b[6~": KeyPageDown,
+	"\x1b[H":  KeyHome,
+	"\x1b[F":  KeyEnd,
+	"\x1bOH":  KeyHome,
+	"\x1bOF":  KeyEnd,
+	"\x1b[1~": KeyHome,
+	"\x1b[4~": KeyEnd,
+}
+
+// Decode splits a chunk read from a raw terminal into keys. Unknown escape sequences are dropped.
+func Decode(p []byte) []Key {
+	var keys []Key
+	for len(p) > 0 {
+		switch p[0] {
+		case '\x1b':
+			n := escapeLength(p)
+			if n == 1 {
+				keys = append(keys, Key{Code: KeyEscape})
+			} else if code, ok := sequences[string(p[:n])]; ok {
+				keys = append(keys, Key{Code: code})
+			}
+			p = p[n:]
+			continue
+		case '\r', '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+		case '\t':
+			keys = append(keys, Key{Code: KeyTab})
+		case 0x7f, 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+		default:
+			r, size := utf8.DecodeRune(p)
+			if r != utf8.RuneError && r >= ' ' {
+				keys = append(keys, Key{Code: KeyRune, Rune: r})
+			}
+			p = p[size:]
+			continue
+		}
+		p = p[1:]
+	}
+	return keys
+}
+
+// escapeLength returns the length of the escape sequence at the start of p, 1 for a lone escape.
+func escapeLength(p []byte) int {
+	if len(p) < 2 || (p[1] != '[' && p[1] != 'O') {
+		return 1
+	}
+	if p[1] == 'O' {
+		return min(3, len(p))
+	}
+	// CSI: parameters and intermediates up to the final byte
+	for i := 2; i < len(p); i++ {
+		if p[i] >= 0x40 && p[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return len(p)
+}
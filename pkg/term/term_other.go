@@ -0,0 +1,25 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package term
+
+import "os"
+
+type State struct{}
+
+func IsTerminal(int) bool {
+	return false
+}
+
+func MakeRaw(int) (*State, error) {
+	return nil, ErrUnsupported
+}
+
+func Restore(int, *State) error {
+	return ErrUnsupported
+}
+
+func Size(int) (int, int, error) {
+	return 0, 0, ErrUnsupported
+}
+
+func NotifyResize(chan<- os.Signal) {}
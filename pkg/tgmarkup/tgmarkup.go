@@ -0,0 +1,131 @@
+// Package tgmarkup builds Telegram messages in the HTML parse mode and prepares them for sending:
+// escaping of dynamic text, splitting at the message length limit and a plain text fallback.
+package tgmarkup
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// ParseMode is the Telegram parse mode of the markup built by this package.
+const ParseMode = "HTML"
+
+// MaxLength is the Telegram limit of a text message, in UTF-16 code units after entities parsing.
+const MaxLength = 4096
+
+var (
+	textEscaper      = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attributeEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+// Escape makes s safe to put in a message as text.
+func Escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// Link is an inline link with the text escaped.
+func Link(text, url string) string {
+	return `<a href="` + attributeEscaper.Replace(url) + `">` + Escape(text) + `</a>`
+}
+
+// Bold is bold escaped text.
+func Bold(text string) string {
+	return "<b>" + Escape(text) + "</b>"
+}
+
+var (
+	linkPattern = regexp.MustCompile(`<a href="([^"]*)">(.*?)</a>`)
+	tagPattern  = regexp.MustCompile(`</?[a-z][^>]*>`)
+)
+
+// Plain converts a message to plain text, links keep their URL after the text.
+func Plain(message string) string {
+	message = linkPattern.ReplaceAllString(message, "$2 ($1)")
+	return html.UnescapeString(tagPattern.ReplaceAllString(message, ""))
+}
+
+// Length is the length of the message as Telegram counts it against MaxLength.
+func Length(message string) int {
+	text := html.UnescapeString(tagPattern.ReplaceAllString(message, ""))
+	return len(utf16.Encode([]rune(text)))
+}
+
+// Split cuts a message into parts of at most limit. It breaks at blank lines first, which separate
+// the programs of a summary, then at line ends. A single line longer than the limit is cut at
+// any character and may lose its markup, senders fall back to Plain when Telegram rejects it.
+// Parts are measured in their Plain form, which is never shorter, so the fallback fits the limit too.
+func Split(message string, limit int) []string {
+	message = strings.Trim(message, "\n")
+	if plainLength(message) <= limit {
+		return []string{message}
+	}
+
+	var parts []string
+	for _, block := range pack(strings.Split(message, "\n\n"), "\n\n", limit) {
+		if plainLength(block) <= limit {
+			parts = append(parts, block)
+			continue
+		}
+		for _, lines := range pack(strings.Split(block, "\n"), "\n", limit) {
+			if plainLength(lines) <= limit {
+				parts = append(parts, lines)
+				continue
+			}
+			parts = append(parts, cut(lines, limit)...)
+		}
+	}
+	return parts
+}
+
+// plainLength is the length of the message once converted with Plain.
+func plainLength(message string) int {
+	return len(utf16.Encode([]rune(Plain(message))))
+}
+
+// pack joins consecutive pieces with sep while the result fits the limit.
+func pack(pieces []string, sep string, limit int) []string {
+	var out []string
+	current := ""
+	for _, piece := range pieces {
+		piece = strings.Trim(piece, "\n")
+		if piece == "" {
+			continue
+		}
+		if current != "" && plainLength(current+sep+piece) <= limit {
+			current += sep + piece
+			continue
+		}
+		if current != "" {
+			out = append(out, current)
+		}
+		current = piece
+	}
+	if current != "" {
+		out = append(out, current)
+	}
+	return out
+}
+
+// cut splits a line at rune boundaries. Every part is at most limit long before any conversion,
+// so both its markup and its Plain form fit.
+func cut(line string, limit int) []string {
+	var out []string
+	var current strings.Builder
+	length := 0
+	for _, r := range line {
+		size := utf16.RuneLen(r)
+		if length+size > limit {
+			out = append(out, current.String())
+			current.Reset()
+			length = 0
+		}
+		current.WriteRune(r)
+		length += size
+	}
+	if current.Len() > 0 {
+		out = append(out, current.String())
+	}
+	return out
+}
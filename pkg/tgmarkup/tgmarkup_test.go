@@ -0,0 +1,164 @@
+package tgmarkup
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// titles are program titles as they come from the ITMO lists, with the characters that break
+// Markdown and HTML markup.
+var titles = []struct {
+	title   string
+	escaped string
+}{
+	{title: "Компьютерные системы и технологии", escaped: "Компьютерные системы и технологии"},
+	{title: "Нейротехнологии и программная инженерия", escaped: "Нейротехнологии и программная инженерия"},
+	{title: "Тестовая программа_1", escaped: "Тестовая программа_1"},
+	{title: "Robotics & Artificial Intelligence", escaped: "Robotics &amp; Artificial Intelligence"},
+	{title: "Программная инженерия <Software Engineering>", escaped: "Программная инженерия &lt;Software Engineering&gt;"},
+	{title: "Финтех [*Financial Technologies*]", escaped: "Финтех [*Financial Technologies*]"},
+	{title: "R&D в IT_продуктах <2025>", escaped: "R&amp;D в IT_продуктах &lt;2025&gt;"},
+}
+
+func TestEscape(t *testing.T) {
+	for _, tt := range titles {
+		if got := Escape(tt.title); got != tt.escaped {
+			t.Errorf("Escape(%q) = %q, want %q", tt.title, got, tt.escaped)
+		}
+	}
+}
+
+func TestLinkAndPlain(t *testing.T) {
+	const url = `https://abit.itmo.ru/rating/master/budget/1000?a=1&b="2"`
+	for _, tt := range titles {
+		link := Link(tt.title, url)
+		want := `<a href="https://abit.itmo.ru/rating/master/budget/1000?a=1&amp;b=&quot;2&quot;">` + tt.escaped + `</a>`
+		if link != want {
+			t.Errorf("Link(%q) = %q, want %q", tt.title, link, want)
+		}
+
+		message := "Программа: " + link + "\n" + Bold(tt.title)
+		wantPlain := "Программа: " + tt.title + " (" + url + ")\n" + tt.title
+		if got := Plain(message); got != wantPlain {
+			t.Errorf("Plain(%q) = %q, want %q", message, got, wantPlain)
+		}
+	}
+}
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    int
+	}{
+		{name: "ascii", message: "Priority: 1", want: 11},
+		// Cyrillic letters are a single UTF-16 unit each, not two bytes
+		{name: "cyrillic", message: "Приоритет", want: 9},
+		// emoji outside the BMP take a surrogate pair
+		{name: "emoji", message: "🎓 проходит ✅", want: 13},
+		// tags are entities, they don't count
+		{name: "tags", message: "<b>бюджет</b>", want: 6},
+		// the URL of a link is an entity, only the text counts
+		{name: "link", message: Link("Тестовая программа_1", "https://abit.itmo.ru/rating/master/budget/1000"), want: 20},
+		// escaped characters count once
+		{name: "escaped", message: Escape("R&D <2025>"), want: 10},
+	}
+	for _, tt := range tests {
+		if got := Length(tt.message); got != tt.want {
+			t.Errorf("%s: Length(%q) = %d, want %d", tt.name, tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	var programs []string
+	for i := range 40 {
+		title := titles[i%len(titles)].title
+		programs = append(programs, fmt.Sprintf(
+			"Приоритет: %d 🎓\nПрограмма: %s\nОснова: бюджет\nПозиция: %d / 26 (всего подано заявлений: 112)\nПоследнее обновление: 26.07.2025 17:43 MSK",
+			i+1, Link(title, fmt.Sprintf("https://abit.itmo.ru/rating/master/budget/%d", 1000+i)), i+12))
+	}
+	message := strings.Join(programs, "\n\n")
+	if Length(message) <= MaxLength {
+		t.Fatalf("summary of %d units fits a single message", Length(message))
+	}
+
+	parts := Split(message, MaxLength)
+	if len(parts) < 2 {
+		t.Fatalf("got %d parts, want several", len(parts))
+	}
+	for i, part := range parts {
+		if Length(part) > MaxLength {
+			t.Errorf("part %d is %d units long", i, Length(part))
+		}
+		if !strings.HasPrefix(part, "Приоритет: ") || !strings.HasSuffix(part, "MSK") {
+			t.Errorf("part %d doesn't break at program boundaries: %q…%q", i, part[:40], part[len(part)-40:])
+		}
+	}
+	if joined := strings.Join(parts, "\n\n"); joined != message {
+		t.Errorf("parts don't add up to the message")
+	}
+}
+
+func TestSplitPlainFits(t *testing.T) {
+	// long links take no room in the markup, but Plain writes their URLs out
+	var lines []string
+	for i := range 30 {
+		url := fmt.Sprintf("https://abit.itmo.ru/rating/master/budget/%d?%s", 1000+i, strings.Repeat("x", 200))
+		lines = append(lines, "Программа: "+Link(titles[i%len(titles)].title, url))
+	}
+	message := strings.Join(lines, "\n\n")
+	if Length(message) > MaxLength {
+		t.Fatalf("markup of %d units doesn't fit a single message", Length(message))
+	}
+
+	parts := Split(message, MaxLength)
+	if len(parts) < 2 {
+		t.Fatalf("got %d parts, want the plain form split too", len(parts))
+	}
+	for i, part := range parts {
+		if n := Length(Plain(part)); n > MaxLength {
+			t.Errorf("plain part %d is %d units long", i, n)
+		}
+	}
+}
+
+func TestSplitShort(t *testing.T) {
+	message := "\nПриоритет: 1\n\nПриоритет: 2\n"
+	parts := Split(message, MaxLength)
+	if len(parts) != 1 || parts[0] != strings.Trim(message, "\n") {
+		t.Errorf("Split(%q) = %q, want the trimmed message", message, parts)
+	}
+}
+
+func TestCut(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		limit int
+	}{
+		{name: "cyrillic", line: strings.Repeat("Нейротехнологии ", 300), limit: MaxLength},
+		// an odd limit must not split a surrogate pair
+		{name: "emoji", line: strings.Repeat("🎓я", 3000), limit: 101},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := cut(tt.line, tt.limit)
+			if len(parts) < 2 {
+				t.Fatalf("got %d parts, want several", len(parts))
+			}
+			for i, part := range parts {
+				if Length(part) > tt.limit {
+					t.Errorf("part %d is %d units long", i, Length(part))
+				}
+				if Length(part) < tt.limit-1 && i < len(parts)-1 {
+					t.Errorf("part %d is %d units long, only the last may be short", i, Length(part))
+				}
+			}
+			if joined := strings.Join(parts, ""); joined != tt.line {
+				t.Errorf("parts don't add up to the line")
+			}
+		})
+	}
+}